@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-kratos/kratos-layout/pkg/fieldmask"
+	"github.com/go-kratos/kratos-layout/pkg/nosql"
+)
+
+/*TableRequest 转为nosql.TableRequest
+参数:
+*	mask	*fieldmask.Mask		接口字段->数据库字段，sort和field_mask只能使用其中的字段，为nil时不转换也不限制
+返回值:
+*	nosql.TableRequest	nosql.TableRequest
+*	error             	error
+*/
+func (x *ListRequest) TableRequest(mask *fieldmask.Mask) (nosql.TableRequest, error) {
+	req := nosql.TableRequest{
+		Query: make(map[string]interface{}, len(x.GetFilter())),
+		Start: int(x.GetStart()),
+		Limit: int(x.GetLimit()),
+	}
+	for k, v := range x.GetFilter() {
+		req.Query[k] = v
+	}
+	if token := x.GetPageToken(); token != "" {
+		start, err := DecodePageToken(token)
+		if err != nil {
+			return req, err
+		}
+		req.Start = start
+	}
+	for _, sort := range x.GetSort() {
+		if len(sort) < 2 || (sort[0] != '-' && sort[0] != '+') {
+			return req, fmt.Errorf("排序[%s]必须以-或+开头", sort)
+		}
+		field, _, err := mask.Field(sort[1:])
+		if err != nil {
+			return req, err
+		}
+		req.Sort = append(req.Sort, sort[:1]+field)
+	}
+	if mask == nil {
+		req.Fields = x.GetFieldMask().GetPaths()
+	} else {
+		fields, err := mask.Read(x.GetFieldMask())
+		if err != nil {
+			return req, err
+		}
+		req.Fields = fields
+	}
+	return req, req.Validate()
+}
+
+/*NewListResponse 根据查询参数和总数量构建列表元数据
+参数:
+*	req  	nosql.TableRequest	实际使用的查询参数
+*	count	int64				总数量
+返回值:
+*	*ListResponse	*ListResponse
+*/
+func NewListResponse(req nosql.TableRequest, count int64) *ListResponse {
+	resp := &ListResponse{
+		Count: count,
+		Start: int32(req.Start),
+		Limit: int32(req.Limit),
+	}
+	if next := req.Start + req.Limit; req.Limit > 0 && int64(next) < count {
+		resp.NextPageToken = EncodePageToken(next)
+	}
+	return resp
+}
+
+const pageTokenPrefix = "start:"
+
+// EncodePageToken 下一页的开始位置编码为page token
+func EncodePageToken(start int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(pageTokenPrefix + strconv.Itoa(start)))
+}
+
+// DecodePageToken 解析EncodePageToken生成的page token
+func DecodePageToken(token string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(data), pageTokenPrefix) {
+		return 0, fmt.Errorf("错误的page token[%s]", token)
+	}
+	start, err := strconv.Atoi(strings.TrimPrefix(string(data), pageTokenPrefix))
+	if err != nil || start < 0 {
+		return 0, fmt.Errorf("错误的page token[%s]", token)
+	}
+	return start, nil
+}
@@ -0,0 +1,53 @@
+package v1_test
+
+import (
+	"testing"
+
+	. "github.com/go-kratos/kratos-layout/api/common/v1"
+	hellov1 "github.com/go-kratos/kratos-layout/api/helloworld/v1"
+	"github.com/go-kratos/kratos-layout/pkg/fieldmask"
+	"github.com/go-kratos/kratos-layout/pkg/nosql"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestListRequest(t *testing.T) {
+	fields := fieldmask.MustNew(&hellov1.GreeterReply{}, map[string]fieldmask.Field{
+		"hello":       {DB: "hello"},
+		"create_time": {DB: "createTime"},
+	})
+	in := &ListRequest{
+		Filter:    map[string]string{"hello": "k"},
+		Sort:      []string{"-create_time", "+hello"},
+		Limit:     10,
+		PageToken: EncodePageToken(20),
+		FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"hello"}},
+	}
+	req, err := in.TableRequest(fields)
+	require.NoError(t, err)
+	require.Equal(t, nosql.TableRequest{
+		Query:  map[string]interface{}{"hello": "k"},
+		Sort:   []string{"-createTime", "+hello"},
+		Start:  20,
+		Limit:  10,
+		Fields: []string{"hello"},
+	}, req)
+
+	_, err = (&ListRequest{Sort: []string{"+password"}}).TableRequest(fields)
+	require.Error(t, err)
+	// 消息中有但没有映射的字段
+	_, err = (&ListRequest{Sort: []string{"+id"}}).TableRequest(fields)
+	require.Error(t, err)
+	_, err = (&ListRequest{PageToken: "bad"}).TableRequest(nil)
+	require.Error(t, err)
+}
+
+func TestNewListResponse(t *testing.T) {
+	resp := NewListResponse(nosql.TableRequest{Start: 10, Limit: 10}, 25)
+	start, err := DecodePageToken(resp.NextPageToken)
+	require.NoError(t, err)
+	require.Equal(t, 20, start)
+
+	require.Empty(t, NewListResponse(nosql.TableRequest{Start: 20, Limit: 10}, 25).NextPageToken)
+	require.Empty(t, NewListResponse(nosql.TableRequest{}, 25).NextPageToken)
+}
@@ -0,0 +1,82 @@
+// Code generated by protoc-gen-go-errors. DO NOT EDIT.
+
+package v1
+
+import (
+	fmt "fmt"
+	errors "github.com/go-kratos/kratos/v2/errors"
+)
+
+// 引用模板中用到的包，保证生成的import不被去掉
+var _ = fmt.Sprintf
+var _ = errors.New
+
+// IsErrorReasonUnspecified 判断是否为ERROR_REASON_UNSPECIFIED错误，支持wrap过的错误
+func IsErrorReasonUnspecified(err error) bool {
+	if err == nil {
+		return false
+	}
+	e := errors.FromError(err)
+	return e.Reason == ErrorReason_ERROR_REASON_UNSPECIFIED.String() && e.Code == 500
+}
+
+// ErrorErrorReasonUnspecified ERROR_REASON_UNSPECIFIED错误，HTTP状态码500
+func ErrorErrorReasonUnspecified(format string, args ...interface{}) *errors.Error {
+	return errors.New(500, ErrorReason_ERROR_REASON_UNSPECIFIED.String(), fmt.Sprintf(format, args...))
+}
+
+// IsUserNotFound 判断是否为USER_NOT_FOUND错误，支持wrap过的错误
+func IsUserNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	e := errors.FromError(err)
+	return e.Reason == ErrorReason_USER_NOT_FOUND.String() && e.Code == 404
+}
+
+// ErrorUserNotFound USER_NOT_FOUND错误，HTTP状态码404
+func ErrorUserNotFound(format string, args ...interface{}) *errors.Error {
+	return errors.New(404, ErrorReason_USER_NOT_FOUND.String(), fmt.Sprintf(format, args...))
+}
+
+// IsGreeterNotFound 判断是否为GREETER_NOT_FOUND错误，支持wrap过的错误
+func IsGreeterNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	e := errors.FromError(err)
+	return e.Reason == ErrorReason_GREETER_NOT_FOUND.String() && e.Code == 404
+}
+
+// ErrorGreeterNotFound GREETER_NOT_FOUND错误，HTTP状态码404
+func ErrorGreeterNotFound(format string, args ...interface{}) *errors.Error {
+	return errors.New(404, ErrorReason_GREETER_NOT_FOUND.String(), fmt.Sprintf(format, args...))
+}
+
+// IsInvalidArgument 判断是否为INVALID_ARGUMENT错误，支持wrap过的错误
+func IsInvalidArgument(err error) bool {
+	if err == nil {
+		return false
+	}
+	e := errors.FromError(err)
+	return e.Reason == ErrorReason_INVALID_ARGUMENT.String() && e.Code == 400
+}
+
+// ErrorInvalidArgument INVALID_ARGUMENT错误，HTTP状态码400
+func ErrorInvalidArgument(format string, args ...interface{}) *errors.Error {
+	return errors.New(400, ErrorReason_INVALID_ARGUMENT.String(), fmt.Sprintf(format, args...))
+}
+
+// IsUnauthorized 判断是否为UNAUTHORIZED错误，支持wrap过的错误
+func IsUnauthorized(err error) bool {
+	if err == nil {
+		return false
+	}
+	e := errors.FromError(err)
+	return e.Reason == ErrorReason_UNAUTHORIZED.String() && e.Code == 401
+}
+
+// ErrorUnauthorized UNAUTHORIZED错误，HTTP状态码401
+func ErrorUnauthorized(format string, args ...interface{}) *errors.Error {
+	return errors.New(401, ErrorReason_UNAUTHORIZED.String(), fmt.Sprintf(format, args...))
+}
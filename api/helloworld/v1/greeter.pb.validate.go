@@ -0,0 +1,907 @@
+// Code generated by protoc-gen-validate. DO NOT EDIT.
+// source: api/helloworld/v1/greeter.proto
+
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/ptypes"
+)
+
+// ensure the imports are used
+var (
+	_ = bytes.MinRead
+	_ = errors.New("")
+	_ = fmt.Print
+	_ = utf8.UTFMax
+	_ = (*regexp.Regexp)(nil)
+	_ = (*strings.Reader)(nil)
+	_ = net.IPv4len
+	_ = time.Duration(0)
+	_ = (*url.URL)(nil)
+	_ = (*mail.Address)(nil)
+	_ = ptypes.DynamicAny{}
+)
+
+// define the regex for a UUID once up-front
+var _greeter_uuidPattern = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+// Validate checks the field values on HelloRequest with the rules defined in
+// the proto definition for this message. If any rules are violated, an error
+// is returned.
+func (m *HelloRequest) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	// no validation rules for Name
+
+	return nil
+}
+
+// HelloRequestValidationError is the validation error returned by
+// HelloRequest.Validate if the designated constraints aren't met.
+type HelloRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e HelloRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e HelloRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e HelloRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e HelloRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e HelloRequestValidationError) ErrorName() string { return "HelloRequestValidationError" }
+
+// Error satisfies the builtin error interface
+func (e HelloRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sHelloRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = HelloRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = HelloRequestValidationError{}
+
+// Validate checks the field values on HelloReply with the rules defined in the
+// proto definition for this message. If any rules are violated, an error is returned.
+func (m *HelloReply) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	// no validation rules for Message
+
+	return nil
+}
+
+// HelloReplyValidationError is the validation error returned by
+// HelloReply.Validate if the designated constraints aren't met.
+type HelloReplyValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e HelloReplyValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e HelloReplyValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e HelloReplyValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e HelloReplyValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e HelloReplyValidationError) ErrorName() string { return "HelloReplyValidationError" }
+
+// Error satisfies the builtin error interface
+func (e HelloReplyValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sHelloReply.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = HelloReplyValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = HelloReplyValidationError{}
+
+// Validate checks the field values on GreeterReply with the rules defined in
+// the proto definition for this message. If any rules are violated, an error
+// is returned.
+func (m *GreeterReply) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	// no validation rules for Id
+
+	// no validation rules for Hello
+
+	if v, ok := interface{}(m.GetCreateTime()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GreeterReplyValidationError{
+				field:  "CreateTime",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if v, ok := interface{}(m.GetUpdateTime()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GreeterReplyValidationError{
+				field:  "UpdateTime",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// GreeterReplyValidationError is the validation error returned by
+// GreeterReply.Validate if the designated constraints aren't met.
+type GreeterReplyValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GreeterReplyValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GreeterReplyValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GreeterReplyValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GreeterReplyValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GreeterReplyValidationError) ErrorName() string { return "GreeterReplyValidationError" }
+
+// Error satisfies the builtin error interface
+func (e GreeterReplyValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGreeterReply.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GreeterReplyValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GreeterReplyValidationError{}
+
+// Validate checks the field values on CreateGreeterRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, an error is returned.
+func (m *CreateGreeterRequest) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	if l := utf8.RuneCountInString(m.GetHello()); l < 1 || l > 64 {
+		return CreateGreeterRequestValidationError{
+			field:  "Hello",
+			reason: "value length must be between 1 and 64 runes, inclusive",
+		}
+	}
+
+	return nil
+}
+
+// CreateGreeterRequestValidationError is the validation error returned by
+// CreateGreeterRequest.Validate if the designated constraints aren't met.
+type CreateGreeterRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CreateGreeterRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CreateGreeterRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CreateGreeterRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CreateGreeterRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CreateGreeterRequestValidationError) ErrorName() string {
+	return "CreateGreeterRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e CreateGreeterRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCreateGreeterRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CreateGreeterRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CreateGreeterRequestValidationError{}
+
+// Validate checks the field values on GetGreeterRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, an
+// error is returned.
+func (m *GetGreeterRequest) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	if !_GetGreeterRequest_Id_Pattern.MatchString(m.GetId()) {
+		return GetGreeterRequestValidationError{
+			field:  "Id",
+			reason: "value does not match regex pattern \"^[0-9a-f]{24}$\"",
+		}
+	}
+
+	if v, ok := interface{}(m.GetReadMask()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GetGreeterRequestValidationError{
+				field:  "ReadMask",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetGreeterRequestValidationError is the validation error returned by
+// GetGreeterRequest.Validate if the designated constraints aren't met.
+type GetGreeterRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetGreeterRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetGreeterRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetGreeterRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetGreeterRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetGreeterRequestValidationError) ErrorName() string {
+	return "GetGreeterRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e GetGreeterRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetGreeterRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetGreeterRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetGreeterRequestValidationError{}
+
+var _GetGreeterRequest_Id_Pattern = regexp.MustCompile("^[0-9a-f]{24}$")
+
+// Validate checks the field values on ListGreetersReply with the rules defined
+// in the proto definition for this message. If any rules are violated, an
+// error is returned.
+func (m *ListGreetersReply) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	if v, ok := interface{}(m.GetMeta()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ListGreetersReplyValidationError{
+				field:  "Meta",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	for idx, item := range m.GetResult() {
+		_, _ = idx, item
+
+		if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return ListGreetersReplyValidationError{
+					field:  fmt.Sprintf("Result[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// ListGreetersReplyValidationError is the validation error returned by
+// ListGreetersReply.Validate if the designated constraints aren't met.
+type ListGreetersReplyValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ListGreetersReplyValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ListGreetersReplyValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ListGreetersReplyValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ListGreetersReplyValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ListGreetersReplyValidationError) ErrorName() string {
+	return "ListGreetersReplyValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ListGreetersReplyValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sListGreetersReply.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ListGreetersReplyValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ListGreetersReplyValidationError{}
+
+// Validate checks the field values on UpdateGreeterRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, an error is returned.
+func (m *UpdateGreeterRequest) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	if !_UpdateGreeterRequest_Id_Pattern.MatchString(m.GetId()) {
+		return UpdateGreeterRequestValidationError{
+			field:  "Id",
+			reason: "value does not match regex pattern \"^[0-9a-f]{24}$\"",
+		}
+	}
+
+	if utf8.RuneCountInString(m.GetHello()) > 64 {
+		return UpdateGreeterRequestValidationError{
+			field:  "Hello",
+			reason: "value length must be at most 64 runes",
+		}
+	}
+
+	if v, ok := interface{}(m.GetUpdateMask()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return UpdateGreeterRequestValidationError{
+				field:  "UpdateMask",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// UpdateGreeterRequestValidationError is the validation error returned by
+// UpdateGreeterRequest.Validate if the designated constraints aren't met.
+type UpdateGreeterRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e UpdateGreeterRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e UpdateGreeterRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e UpdateGreeterRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e UpdateGreeterRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e UpdateGreeterRequestValidationError) ErrorName() string {
+	return "UpdateGreeterRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e UpdateGreeterRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sUpdateGreeterRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = UpdateGreeterRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = UpdateGreeterRequestValidationError{}
+
+var _UpdateGreeterRequest_Id_Pattern = regexp.MustCompile("^[0-9a-f]{24}$")
+
+// Validate checks the field values on DeleteGreeterRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, an error is returned.
+func (m *DeleteGreeterRequest) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	if !_DeleteGreeterRequest_Id_Pattern.MatchString(m.GetId()) {
+		return DeleteGreeterRequestValidationError{
+			field:  "Id",
+			reason: "value does not match regex pattern \"^[0-9a-f]{24}$\"",
+		}
+	}
+
+	return nil
+}
+
+// DeleteGreeterRequestValidationError is the validation error returned by
+// DeleteGreeterRequest.Validate if the designated constraints aren't met.
+type DeleteGreeterRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DeleteGreeterRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DeleteGreeterRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DeleteGreeterRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DeleteGreeterRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DeleteGreeterRequestValidationError) ErrorName() string {
+	return "DeleteGreeterRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DeleteGreeterRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDeleteGreeterRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DeleteGreeterRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DeleteGreeterRequestValidationError{}
+
+var _DeleteGreeterRequest_Id_Pattern = regexp.MustCompile("^[0-9a-f]{24}$")
+
+// Validate checks the field values on DeleteGreeterReply with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, an error is returned.
+func (m *DeleteGreeterReply) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	return nil
+}
+
+// DeleteGreeterReplyValidationError is the validation error returned by
+// DeleteGreeterReply.Validate if the designated constraints aren't met.
+type DeleteGreeterReplyValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DeleteGreeterReplyValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DeleteGreeterReplyValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DeleteGreeterReplyValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DeleteGreeterReplyValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DeleteGreeterReplyValidationError) ErrorName() string {
+	return "DeleteGreeterReplyValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DeleteGreeterReplyValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDeleteGreeterReply.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DeleteGreeterReplyValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DeleteGreeterReplyValidationError{}
+
+// Validate checks the field values on WatchGreetersRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, an error is returned.
+func (m *WatchGreetersRequest) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	// no validation rules for ResumeToken
+
+	for idx, item := range m.GetTypes() {
+		_, _ = idx, item
+
+		if _, ok := GreeterEvent_Type_name[int32(item)]; !ok {
+			return WatchGreetersRequestValidationError{
+				field:  fmt.Sprintf("Types[%v]", idx),
+				reason: "value must be one of the defined enum values",
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// WatchGreetersRequestValidationError is the validation error returned by
+// WatchGreetersRequest.Validate if the designated constraints aren't met.
+type WatchGreetersRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e WatchGreetersRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e WatchGreetersRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e WatchGreetersRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e WatchGreetersRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e WatchGreetersRequestValidationError) ErrorName() string {
+	return "WatchGreetersRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e WatchGreetersRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sWatchGreetersRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = WatchGreetersRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = WatchGreetersRequestValidationError{}
+
+// Validate checks the field values on GreeterEvent with the rules defined in
+// the proto definition for this message. If any rules are violated, an error
+// is returned.
+func (m *GreeterEvent) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	// no validation rules for Type
+
+	if v, ok := interface{}(m.GetGreeter()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GreeterEventValidationError{
+				field:  "Greeter",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for ResumeToken
+
+	return nil
+}
+
+// GreeterEventValidationError is the validation error returned by
+// GreeterEvent.Validate if the designated constraints aren't met.
+type GreeterEventValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GreeterEventValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GreeterEventValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GreeterEventValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GreeterEventValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GreeterEventValidationError) ErrorName() string { return "GreeterEventValidationError" }
+
+// Error satisfies the builtin error interface
+func (e GreeterEventValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGreeterEvent.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GreeterEventValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GreeterEventValidationError{}
@@ -4,6 +4,7 @@ package v1
 
 import (
 	context "context"
+	v1 "github.com/go-kratos/kratos-layout/api/common/v1"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -20,6 +21,20 @@ const _ = grpc.SupportPackageIsVersion7
 type GreeterClient interface {
 	// Sends a greeting
 	SayHello(ctx context.Context, in *HelloRequest, opts ...grpc.CallOption) (*HelloReply, error)
+	// Creates a greeter
+	CreateGreeter(ctx context.Context, in *CreateGreeterRequest, opts ...grpc.CallOption) (*GreeterReply, error)
+	// Gets a greeter by id
+	GetGreeter(ctx context.Context, in *GetGreeterRequest, opts ...grpc.CallOption) (*GreeterReply, error)
+	// Lists greeters, filter supports hello, sort and field_mask support id/hello/create_time/update_time
+	ListGreeters(ctx context.Context, in *v1.ListRequest, opts ...grpc.CallOption) (*ListGreetersReply, error)
+	// Updates a greeter
+	UpdateGreeter(ctx context.Context, in *UpdateGreeterRequest, opts ...grpc.CallOption) (*GreeterReply, error)
+	// Deletes a greeter
+	DeleteGreeter(ctx context.Context, in *DeleteGreeterRequest, opts ...grpc.CallOption) (*DeleteGreeterReply, error)
+	// Watches greeter changes, HTTP clients use server-sent events on GET /v1/greeters:watch
+	WatchGreeters(ctx context.Context, in *WatchGreetersRequest, opts ...grpc.CallOption) (Greeter_WatchGreetersClient, error)
+	// Sends a greeting for every request on the stream
+	SayHelloStream(ctx context.Context, opts ...grpc.CallOption) (Greeter_SayHelloStreamClient, error)
 }
 
 type greeterClient struct {
@@ -39,12 +54,134 @@ func (c *greeterClient) SayHello(ctx context.Context, in *HelloRequest, opts ...
 	return out, nil
 }
 
+func (c *greeterClient) CreateGreeter(ctx context.Context, in *CreateGreeterRequest, opts ...grpc.CallOption) (*GreeterReply, error) {
+	out := new(GreeterReply)
+	err := c.cc.Invoke(ctx, "/helloworld.v1.Greeter/CreateGreeter", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterClient) GetGreeter(ctx context.Context, in *GetGreeterRequest, opts ...grpc.CallOption) (*GreeterReply, error) {
+	out := new(GreeterReply)
+	err := c.cc.Invoke(ctx, "/helloworld.v1.Greeter/GetGreeter", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterClient) ListGreeters(ctx context.Context, in *v1.ListRequest, opts ...grpc.CallOption) (*ListGreetersReply, error) {
+	out := new(ListGreetersReply)
+	err := c.cc.Invoke(ctx, "/helloworld.v1.Greeter/ListGreeters", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterClient) UpdateGreeter(ctx context.Context, in *UpdateGreeterRequest, opts ...grpc.CallOption) (*GreeterReply, error) {
+	out := new(GreeterReply)
+	err := c.cc.Invoke(ctx, "/helloworld.v1.Greeter/UpdateGreeter", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterClient) DeleteGreeter(ctx context.Context, in *DeleteGreeterRequest, opts ...grpc.CallOption) (*DeleteGreeterReply, error) {
+	out := new(DeleteGreeterReply)
+	err := c.cc.Invoke(ctx, "/helloworld.v1.Greeter/DeleteGreeter", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterClient) WatchGreeters(ctx context.Context, in *WatchGreetersRequest, opts ...grpc.CallOption) (Greeter_WatchGreetersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Greeter_ServiceDesc.Streams[0], "/helloworld.v1.Greeter/WatchGreeters", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &greeterWatchGreetersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Greeter_WatchGreetersClient interface {
+	Recv() (*GreeterEvent, error)
+	grpc.ClientStream
+}
+
+type greeterWatchGreetersClient struct {
+	grpc.ClientStream
+}
+
+func (x *greeterWatchGreetersClient) Recv() (*GreeterEvent, error) {
+	m := new(GreeterEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *greeterClient) SayHelloStream(ctx context.Context, opts ...grpc.CallOption) (Greeter_SayHelloStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Greeter_ServiceDesc.Streams[1], "/helloworld.v1.Greeter/SayHelloStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &greeterSayHelloStreamClient{stream}
+	return x, nil
+}
+
+type Greeter_SayHelloStreamClient interface {
+	Send(*HelloRequest) error
+	Recv() (*HelloReply, error)
+	grpc.ClientStream
+}
+
+type greeterSayHelloStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *greeterSayHelloStreamClient) Send(m *HelloRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *greeterSayHelloStreamClient) Recv() (*HelloReply, error) {
+	m := new(HelloReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // GreeterServer is the server API for Greeter service.
 // All implementations must embed UnimplementedGreeterServer
 // for forward compatibility
 type GreeterServer interface {
 	// Sends a greeting
 	SayHello(context.Context, *HelloRequest) (*HelloReply, error)
+	// Creates a greeter
+	CreateGreeter(context.Context, *CreateGreeterRequest) (*GreeterReply, error)
+	// Gets a greeter by id
+	GetGreeter(context.Context, *GetGreeterRequest) (*GreeterReply, error)
+	// Lists greeters, filter supports hello, sort and field_mask support id/hello/create_time/update_time
+	ListGreeters(context.Context, *v1.ListRequest) (*ListGreetersReply, error)
+	// Updates a greeter
+	UpdateGreeter(context.Context, *UpdateGreeterRequest) (*GreeterReply, error)
+	// Deletes a greeter
+	DeleteGreeter(context.Context, *DeleteGreeterRequest) (*DeleteGreeterReply, error)
+	// Watches greeter changes, HTTP clients use server-sent events on GET /v1/greeters:watch
+	WatchGreeters(*WatchGreetersRequest, Greeter_WatchGreetersServer) error
+	// Sends a greeting for every request on the stream
+	SayHelloStream(Greeter_SayHelloStreamServer) error
 	mustEmbedUnimplementedGreeterServer()
 }
 
@@ -55,6 +192,27 @@ type UnimplementedGreeterServer struct {
 func (UnimplementedGreeterServer) SayHello(context.Context, *HelloRequest) (*HelloReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SayHello not implemented")
 }
+func (UnimplementedGreeterServer) CreateGreeter(context.Context, *CreateGreeterRequest) (*GreeterReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateGreeter not implemented")
+}
+func (UnimplementedGreeterServer) GetGreeter(context.Context, *GetGreeterRequest) (*GreeterReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGreeter not implemented")
+}
+func (UnimplementedGreeterServer) ListGreeters(context.Context, *v1.ListRequest) (*ListGreetersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGreeters not implemented")
+}
+func (UnimplementedGreeterServer) UpdateGreeter(context.Context, *UpdateGreeterRequest) (*GreeterReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateGreeter not implemented")
+}
+func (UnimplementedGreeterServer) DeleteGreeter(context.Context, *DeleteGreeterRequest) (*DeleteGreeterReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteGreeter not implemented")
+}
+func (UnimplementedGreeterServer) WatchGreeters(*WatchGreetersRequest, Greeter_WatchGreetersServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchGreeters not implemented")
+}
+func (UnimplementedGreeterServer) SayHelloStream(Greeter_SayHelloStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method SayHelloStream not implemented")
+}
 func (UnimplementedGreeterServer) mustEmbedUnimplementedGreeterServer() {}
 
 // UnsafeGreeterServer may be embedded to opt out of forward compatibility for this service.
@@ -86,6 +244,143 @@ func _Greeter_SayHello_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Greeter_CreateGreeter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateGreeterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).CreateGreeter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/helloworld.v1.Greeter/CreateGreeter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).CreateGreeter(ctx, req.(*CreateGreeterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Greeter_GetGreeter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGreeterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).GetGreeter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/helloworld.v1.Greeter/GetGreeter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).GetGreeter(ctx, req.(*GetGreeterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Greeter_ListGreeters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).ListGreeters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/helloworld.v1.Greeter/ListGreeters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).ListGreeters(ctx, req.(*v1.ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Greeter_UpdateGreeter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateGreeterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).UpdateGreeter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/helloworld.v1.Greeter/UpdateGreeter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).UpdateGreeter(ctx, req.(*UpdateGreeterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Greeter_DeleteGreeter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteGreeterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).DeleteGreeter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/helloworld.v1.Greeter/DeleteGreeter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).DeleteGreeter(ctx, req.(*DeleteGreeterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Greeter_WatchGreeters_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchGreetersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GreeterServer).WatchGreeters(m, &greeterWatchGreetersServer{stream})
+}
+
+type Greeter_WatchGreetersServer interface {
+	Send(*GreeterEvent) error
+	grpc.ServerStream
+}
+
+type greeterWatchGreetersServer struct {
+	grpc.ServerStream
+}
+
+func (x *greeterWatchGreetersServer) Send(m *GreeterEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Greeter_SayHelloStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GreeterServer).SayHelloStream(&greeterSayHelloStreamServer{stream})
+}
+
+type Greeter_SayHelloStreamServer interface {
+	Send(*HelloReply) error
+	Recv() (*HelloRequest, error)
+	grpc.ServerStream
+}
+
+type greeterSayHelloStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *greeterSayHelloStreamServer) Send(m *HelloReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *greeterSayHelloStreamServer) Recv() (*HelloRequest, error) {
+	m := new(HelloRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Greeter_ServiceDesc is the grpc.ServiceDesc for Greeter service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -97,7 +392,39 @@ var Greeter_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SayHello",
 			Handler:    _Greeter_SayHello_Handler,
 		},
+		{
+			MethodName: "CreateGreeter",
+			Handler:    _Greeter_CreateGreeter_Handler,
+		},
+		{
+			MethodName: "GetGreeter",
+			Handler:    _Greeter_GetGreeter_Handler,
+		},
+		{
+			MethodName: "ListGreeters",
+			Handler:    _Greeter_ListGreeters_Handler,
+		},
+		{
+			MethodName: "UpdateGreeter",
+			Handler:    _Greeter_UpdateGreeter_Handler,
+		},
+		{
+			MethodName: "DeleteGreeter",
+			Handler:    _Greeter_DeleteGreeter_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchGreeters",
+			Handler:       _Greeter_WatchGreeters_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SayHelloStream",
+			Handler:       _Greeter_SayHelloStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/helloworld/v1/greeter.proto",
 }
@@ -2,6 +2,10 @@
 
 package v1
 
+import (
+	v1 "github.com/go-kratos/kratos-layout/api/common/v1"
+)
+
 import (
 	context "context"
 	http1 "github.com/go-kratos/kratos/v2/transport/http"
@@ -21,6 +25,11 @@ const _ = http1.SupportPackageIsVersion1
 
 type GreeterHandler interface {
 	SayHello(context.Context, *HelloRequest) (*HelloReply, error)
+	CreateGreeter(context.Context, *CreateGreeterRequest) (*GreeterReply, error)
+	GetGreeter(context.Context, *GetGreeterRequest) (*GreeterReply, error)
+	ListGreeters(context.Context, *v1.ListRequest) (*ListGreetersReply, error)
+	UpdateGreeter(context.Context, *UpdateGreeterRequest) (*GreeterReply, error)
+	DeleteGreeter(context.Context, *DeleteGreeterRequest) (*DeleteGreeterReply, error)
 }
 
 func NewGreeterHandler(srv GreeterHandler, opts ...http1.HandleOption) http.Handler {
@@ -59,11 +68,151 @@ func NewGreeterHandler(srv GreeterHandler, opts ...http1.HandleOption) http.Hand
 		}
 	}).Methods("GET")
 
+	r.HandleFunc("/v1/greeters", func(w http.ResponseWriter, r *http.Request) {
+		var in CreateGreeterRequest
+		if err := h.Decode(r, &in); err != nil {
+			h.Error(w, r, err)
+			return
+		}
+
+		next := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.CreateGreeter(ctx, req.(*CreateGreeterRequest))
+		}
+		if h.Middleware != nil {
+			next = h.Middleware(next)
+		}
+		out, err := next(r.Context(), &in)
+		if err != nil {
+			h.Error(w, r, err)
+			return
+		}
+		reply := out.(*GreeterReply)
+		if err := h.Encode(w, r, reply); err != nil {
+			h.Error(w, r, err)
+		}
+	}).Methods("POST")
+
+	r.HandleFunc("/v1/greeters/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var in GetGreeterRequest
+		if err := h.Decode(r, &in); err != nil {
+			h.Error(w, r, err)
+			return
+		}
+
+		if err := binding.BindVars(mux.Vars(r), &in); err != nil {
+			h.Error(w, r, err)
+			return
+		}
+
+		next := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.GetGreeter(ctx, req.(*GetGreeterRequest))
+		}
+		if h.Middleware != nil {
+			next = h.Middleware(next)
+		}
+		out, err := next(r.Context(), &in)
+		if err != nil {
+			h.Error(w, r, err)
+			return
+		}
+		reply := out.(*GreeterReply)
+		if err := h.Encode(w, r, reply); err != nil {
+			h.Error(w, r, err)
+		}
+	}).Methods("GET")
+
+	r.HandleFunc("/v1/greeters", func(w http.ResponseWriter, r *http.Request) {
+		var in v1.ListRequest
+		if err := h.Decode(r, &in); err != nil {
+			h.Error(w, r, err)
+			return
+		}
+
+		next := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.ListGreeters(ctx, req.(*v1.ListRequest))
+		}
+		if h.Middleware != nil {
+			next = h.Middleware(next)
+		}
+		out, err := next(r.Context(), &in)
+		if err != nil {
+			h.Error(w, r, err)
+			return
+		}
+		reply := out.(*ListGreetersReply)
+		if err := h.Encode(w, r, reply); err != nil {
+			h.Error(w, r, err)
+		}
+	}).Methods("GET")
+
+	r.HandleFunc("/v1/greeters/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var in UpdateGreeterRequest
+		if err := h.Decode(r, &in); err != nil {
+			h.Error(w, r, err)
+			return
+		}
+
+		if err := binding.BindVars(mux.Vars(r), &in); err != nil {
+			h.Error(w, r, err)
+			return
+		}
+
+		next := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.UpdateGreeter(ctx, req.(*UpdateGreeterRequest))
+		}
+		if h.Middleware != nil {
+			next = h.Middleware(next)
+		}
+		out, err := next(r.Context(), &in)
+		if err != nil {
+			h.Error(w, r, err)
+			return
+		}
+		reply := out.(*GreeterReply)
+		if err := h.Encode(w, r, reply); err != nil {
+			h.Error(w, r, err)
+		}
+	}).Methods("PUT")
+
+	r.HandleFunc("/v1/greeters/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var in DeleteGreeterRequest
+		if err := h.Decode(r, &in); err != nil {
+			h.Error(w, r, err)
+			return
+		}
+
+		if err := binding.BindVars(mux.Vars(r), &in); err != nil {
+			h.Error(w, r, err)
+			return
+		}
+
+		next := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.DeleteGreeter(ctx, req.(*DeleteGreeterRequest))
+		}
+		if h.Middleware != nil {
+			next = h.Middleware(next)
+		}
+		out, err := next(r.Context(), &in)
+		if err != nil {
+			h.Error(w, r, err)
+			return
+		}
+		reply := out.(*DeleteGreeterReply)
+		if err := h.Encode(w, r, reply); err != nil {
+			h.Error(w, r, err)
+		}
+	}).Methods("DELETE")
+
 	return r
 }
 
 type GreeterHttpClient interface {
 	SayHello(ctx context.Context, req *HelloRequest, opts ...http1.CallOption) (rsp *HelloReply, err error)
+	CreateGreeter(ctx context.Context, req *CreateGreeterRequest, opts ...http1.CallOption) (rsp *GreeterReply, err error)
+	GetGreeter(ctx context.Context, req *GetGreeterRequest, opts ...http1.CallOption) (rsp *GreeterReply, err error)
+	ListGreeters(ctx context.Context, req *v1.ListRequest, opts ...http1.CallOption) (rsp *ListGreetersReply, err error)
+	UpdateGreeter(ctx context.Context, req *UpdateGreeterRequest, opts ...http1.CallOption) (rsp *GreeterReply, err error)
+	DeleteGreeter(ctx context.Context, req *DeleteGreeterRequest, opts ...http1.CallOption) (rsp *DeleteGreeterReply, err error)
 }
 
 type GreeterHttpClientImpl struct {
@@ -85,3 +234,63 @@ func (c *GreeterHttpClientImpl) SayHello(ctx context.Context, in *HelloRequest,
 	}
 	return
 }
+
+func (c *GreeterHttpClientImpl) CreateGreeter(ctx context.Context, in *CreateGreeterRequest, opts ...http1.CallOption) (out *GreeterReply, err error) {
+	path := binding.EncodePath("POST", "/v1/greeters", in)
+	out = &GreeterReply{}
+
+	err = c.cc.Invoke(ctx, path, in, &out, http1.Method("POST"), http1.PathPattern("/v1/greeters"))
+
+	if err != nil {
+		return
+	}
+	return
+}
+
+func (c *GreeterHttpClientImpl) GetGreeter(ctx context.Context, in *GetGreeterRequest, opts ...http1.CallOption) (out *GreeterReply, err error) {
+	path := binding.EncodePath("GET", "/v1/greeters/{id}", in)
+	out = &GreeterReply{}
+
+	err = c.cc.Invoke(ctx, path, nil, &out, http1.Method("GET"), http1.PathPattern("/v1/greeters/{id}"))
+
+	if err != nil {
+		return
+	}
+	return
+}
+
+func (c *GreeterHttpClientImpl) ListGreeters(ctx context.Context, in *v1.ListRequest, opts ...http1.CallOption) (out *ListGreetersReply, err error) {
+	path := binding.EncodePath("GET", "/v1/greeters", in)
+	out = &ListGreetersReply{}
+
+	err = c.cc.Invoke(ctx, path, nil, &out, http1.Method("GET"), http1.PathPattern("/v1/greeters"))
+
+	if err != nil {
+		return
+	}
+	return
+}
+
+func (c *GreeterHttpClientImpl) UpdateGreeter(ctx context.Context, in *UpdateGreeterRequest, opts ...http1.CallOption) (out *GreeterReply, err error) {
+	path := binding.EncodePath("PUT", "/v1/greeters/{id}", in)
+	out = &GreeterReply{}
+
+	err = c.cc.Invoke(ctx, path, in, &out, http1.Method("PUT"), http1.PathPattern("/v1/greeters/{id}"))
+
+	if err != nil {
+		return
+	}
+	return
+}
+
+func (c *GreeterHttpClientImpl) DeleteGreeter(ctx context.Context, in *DeleteGreeterRequest, opts ...http1.CallOption) (out *DeleteGreeterReply, err error) {
+	path := binding.EncodePath("DELETE", "/v1/greeters/{id}", in)
+	out = &DeleteGreeterReply{}
+
+	err = c.cc.Invoke(ctx, path, nil, &out, http1.Method("DELETE"), http1.PathPattern("/v1/greeters/{id}"))
+
+	if err != nil {
+		return
+	}
+	return
+}
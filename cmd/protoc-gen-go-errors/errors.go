@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/go-kratos/kratos-layout/pkg/errors"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	fmtPackage    = protogen.GoImportPath("fmt")
+	errorsPackage = protogen.GoImportPath("github.com/go-kratos/kratos/v2/errors")
+	// defaultCode 枚举没有default_code时使用
+	defaultCode = 500
+)
+
+var errorsTemplate = template.Must(template.New("errors").Parse(`
+{{- range .Errors}}
+// Is{{.CamelValue}} 判断是否为{{.Value}}错误，支持wrap过的错误
+func Is{{.CamelValue}}(err error) bool {
+	if err == nil {
+		return false
+	}
+	e := errors.FromError(err)
+	return e.Reason == {{.Name}}_{{.Value}}.String() && e.Code == {{.HTTPCode}}
+}
+
+// Error{{.CamelValue}} {{.Value}}错误，HTTP状态码{{.HTTPCode}}
+func Error{{.CamelValue}}(format string, args ...interface{}) *errors.Error {
+	return errors.New({{.HTTPCode}}, {{.Name}}_{{.Value}}.String(), fmt.Sprintf(format, args...))
+}
+{{- end}}
+`))
+
+type errorInfo struct {
+	Name       string
+	Value      string
+	CamelValue string
+	HTTPCode   int
+}
+
+func generateFile(gen *protogen.Plugin, file *protogen.File) {
+	if len(file.Enums) == 0 {
+		return
+	}
+	var infos []errorInfo
+	for _, enum := range file.Enums {
+		code := int(proto.GetExtension(enum.Desc.Options(), errors.E_DefaultCode).(int32))
+		if code == 0 {
+			continue
+		}
+		for _, v := range enum.Values {
+			valueCode := code
+			if c := int(proto.GetExtension(v.Desc.Options(), errors.E_Code).(int32)); c != 0 {
+				valueCode = c
+			}
+			infos = append(infos, errorInfo{
+				Name:       string(enum.Desc.Name()),
+				Value:      string(v.Desc.Name()),
+				CamelValue: camel(string(v.Desc.Name())),
+				HTTPCode:   valueCode,
+			})
+		}
+	}
+	if len(infos) == 0 {
+		return
+	}
+	g := gen.NewGeneratedFile(file.GeneratedFilenamePrefix+"_errors.pb.go", file.GoImportPath)
+	g.P("// Code generated by protoc-gen-go-errors. DO NOT EDIT.")
+	g.P()
+	g.P("package ", file.GoPackageName)
+	g.P()
+	// 模板输出的是纯文本，通过Ident引用两个包让protogen生成对应的import
+	g.P("// 引用模板中用到的包，保证生成的import不被去掉")
+	g.P("var _ = ", fmtPackage.Ident("Sprintf"))
+	g.P("var _ = ", errorsPackage.Ident("New"))
+	g.P()
+	buf := &bytes.Buffer{}
+	if err := errorsTemplate.Execute(buf, struct{ Errors []errorInfo }{infos}); err != nil {
+		gen.Error(err)
+		return
+	}
+	g.P(strings.TrimSpace(buf.String()))
+}
+
+// camel USER_NOT_FOUND -> UserNotFound
+func camel(s string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(strings.ToLower(s), "_") {
+		if word == "" {
+			continue
+		}
+		r := []rune(word)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,36 @@
+// protoc-gen-go-errors 根据错误原因枚举生成构造函数和IsXxx判断函数
+//
+//	protoc --proto_path=. --proto_path=./third_party --go-errors_out=paths=source_relative:. xxx.proto
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var showVersion = flag.Bool("version", false, "print the version and exit")
+
+const version = "v0.1.0"
+
+func main() {
+	flag.Parse()
+	if *showVersion {
+		fmt.Printf("protoc-gen-go-errors %s\n", version)
+		return
+	}
+	protogen.Options{
+		ParamFunc: flag.CommandLine.Set,
+	}.Run(func(gen *protogen.Plugin) error {
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		for _, f := range gen.Files {
+			if !f.Generate {
+				continue
+			}
+			generateFile(gen, f)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-kratos/kratos-layout/pkg/config"
+)
+
+// 生成密钥、加密配置中的敏感值
+//
+//	secret genkey
+//	CONFIG_SECRET_KEY=xxx secret encrypt password123
+//	echo password123 | CONFIG_SECRET_KEY=xxx secret encrypt
+//	CONFIG_SECRET_KEY=xxx secret decrypt '${enc:...}'
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s genkey|encrypt|decrypt [value]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "密钥从环境变量 %s 读取\n", config.SecretKeyEnv)
+	}
+	flag.Parse()
+	if err := run(flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "genkey":
+		key, err := config.GenerateSecretKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+		return nil
+	case "encrypt", "decrypt":
+		key, err := config.SecretKeyFromEnv()
+		if err != nil {
+			return err
+		}
+		value, err := readValue(args[1:])
+		if err != nil {
+			return err
+		}
+		if args[0] == "encrypt" {
+			value, err = config.Encrypt(key, value)
+		} else {
+			value, err = config.Decrypt(key, value)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	default:
+		return fmt.Errorf("未知命令: %s", args[0])
+	}
+}
+
+// readValue 优先使用参数，没有参数时从标准输入读取一行，避免明文出现在shell历史中
+func readValue(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("读取标准输入: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
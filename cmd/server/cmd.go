@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/internal/conf"
+	"github.com/go-kratos/kratos-layout/internal/data"
+	"github.com/go-kratos/kratos-layout/internal/server"
+	"github.com/go-kratos/kratos-layout/pkg/config"
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// command 子命令
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"serve":       {"启动http和grpc服务(默认)", serve},
+		"migrate":     {"升级mysql表结构和mongo数据", migrateCmd},
+		"index":       {"plan|apply 查看或升级mongo索引", index},
+		"config":      {"validate|print 校验或打印配置", configCmd},
+		"version":     {"打印版本信息", version},
+		"healthcheck": {"检查运行中的服务是否健康，可用作docker HEALTHCHECK", healthcheck},
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	w := tabwriter.NewWriter(os.Stderr, 0, 4, 2, ' ', 0)
+	for _, name := range []string{"serve", "migrate", "index", "config", "version", "healthcheck"} {
+		fmt.Fprintf(w, "  %s\t%s\n", name, commands[name].usage)
+	}
+	_ = w.Flush()
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for command flags.\n", os.Args[0])
+}
+
+// newFlagSet 所有子命令都支持-conf
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&flagconf, "conf", "./conf", "config path, eg: -conf application.yaml")
+	return fs
+}
+
+func serve(args []string) error {
+	fs := newFlagSet("serve")
+	doMigrate := fs.Bool("migrate", true, "启动时升级mysql表结构、mongo数据和索引")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	bc, logger, closeLog, err := setup()
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	tp, err := newTracerProvider(bc.Otel)
+	if err != nil {
+		return err
+	}
+	app, cleanup, err := initApp(bc.Server, bc.Data, tp, logger)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if *doMigrate {
+		if err = migrate(app.migrator); err != nil {
+			return err
+		}
+	}
+
+	// start and wait for stop signal
+	return app.Run()
+}
+
+// migrate 先升级表结构和数据，再升级索引，多个实例同时启动时通过分布式锁依次执行
+func migrate(m *data.Migrator) error {
+	return m.Exclusive(context.Background(), func(ctx context.Context) error {
+		if err := m.Migrate(ctx); err != nil {
+			return err
+		}
+		return m.ApplyIndex(ctx)
+	})
+}
+
+// withMigrator 只创建数据层依赖，不启动服务
+func withMigrator(args []string, name string, fn func(m *data.Migrator, args []string) error) error {
+	fs := newFlagSet(name)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	bc, logger, closeLog, err := setup()
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	m, cleanup, err := initMigrator(bc.Data, logger)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	return fn(m, fs.Args())
+}
+
+func migrateCmd(args []string) error {
+	return withMigrator(args, "migrate", func(m *data.Migrator, _ []string) error {
+		return m.Migrate(context.Background())
+	})
+}
+
+func index(args []string) error {
+	if len(args) == 0 || (args[0] != "plan" && args[0] != "apply") {
+		return errors.New("usage: index plan|apply [-conf path]")
+	}
+	action := args[0]
+	return withMigrator(args[1:], "index "+action, func(m *data.Migrator, _ []string) error {
+		ctx := context.Background()
+		if action == "apply" {
+			return m.ApplyIndex(ctx)
+		}
+		plan, err := m.PlanIndex(ctx)
+		if err != nil {
+			return err
+		}
+		for _, change := range plan {
+			fmt.Println(change)
+		}
+		return nil
+	})
+}
+
+func configCmd(args []string) error {
+	if len(args) == 0 || (args[0] != "validate" && args[0] != "print") {
+		return errors.New("usage: config validate|print [-conf path] [-show-secrets]")
+	}
+	action := args[0]
+	fs := newFlagSet("config " + action)
+	showSecrets := fs.Bool("show-secrets", false, "print时不隐藏密码和token")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	// 输出到标准输出的只有配置本身
+	config.SetLogger(log.NewStdLogger(os.Stderr))
+	bc, err := loadBootstrap()
+	if err != nil {
+		return err
+	}
+	if err = bc.Validate(); err != nil {
+		return err
+	}
+	if action == "validate" {
+		fmt.Println("ok")
+		return nil
+	}
+	if !*showSecrets {
+		maskSecrets(bc.ProtoReflect())
+	}
+	b, err := protojson.MarshalOptions{Multiline: true, UseProtoNames: true}.Marshal(bc)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+// maskSecrets 隐藏名字包含password或token的字段
+func maskSecrets(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := strings.ToLower(string(fd.Name()))
+		secret := strings.Contains(name, "password") || strings.Contains(name, "token")
+		switch {
+		case fd.Kind() == protoreflect.StringKind && fd.IsList() && secret:
+			for i := 0; i < v.List().Len(); i++ {
+				v.List().Set(i, protoreflect.ValueOfString("******"))
+			}
+		case fd.Kind() == protoreflect.StringKind && !fd.IsMap() && secret:
+			m.Set(fd, protoreflect.ValueOfString("******"))
+		case fd.Kind() == protoreflect.MessageKind && !fd.IsList() && !fd.IsMap():
+			maskSecrets(v.Message())
+		}
+		return true
+	})
+}
+
+func version(args []string) error {
+	if err := flag.NewFlagSet("version", flag.ContinueOnError).Parse(args); err != nil {
+		return err
+	}
+	fmt.Printf("name: %s\nversion: %s\nbuild time: %s\ngo: %s %s/%s\n", Name, Version, BuildTime, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	return nil
+}
+
+func healthcheck(args []string) error {
+	fs := newFlagSet("healthcheck")
+	addr := fs.String("addr", "", "服务地址，为空时使用配置中的地址")
+	useGRPC := fs.Bool("grpc", false, "使用grpc health检查，默认检查http "+server.HealthPath)
+	timeout := fs.Duration("timeout", 3*time.Second, "超时时间")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *addr == "" {
+		config.SetLogger(log.NewStdLogger(os.Stderr))
+		bc, err := loadBootstrap()
+		if err != nil {
+			return err
+		}
+		*addr = probeAddr(bc.Server, *useGRPC)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if *useGRPC {
+		return grpcHealthcheck(ctx, *addr)
+	}
+	return httpHealthcheck(ctx, *addr)
+}
+
+// probeAddr 监听在0.0.0.0等地址时改为访问本机
+func probeAddr(c *conf.Server, useGRPC bool) string {
+	addr := ""
+	if useGRPC && c.Grpc != nil {
+		addr = c.Grpc.Addr
+	} else if !useGRPC && c.Http != nil {
+		addr = c.Http.Addr
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+func httpHealthcheck(ctx context.Context, addr string) error {
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, "http://"+addr+server.HealthPath, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusOK {
+		return fmt.Errorf("unhealthy: %s", resp.Status)
+	}
+	return nil
+}
+
+func grpcHealthcheck(ctx context.Context, addr string) error {
+	cc, err := grpc.DialContext(ctx, addr, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+	resp, err := grpc_health_v1.NewHealthClient(cc).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("unhealthy: %s", resp.Status)
+	}
+	return nil
+}
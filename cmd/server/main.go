@@ -2,24 +2,29 @@ package main
 
 import (
 	"flag"
-	"github.com/go-kratos/kratos-layout/internal/data"
-	"github.com/go-kratos/kratos-layout/pkg/config"
-	"github.com/go-kratos/kratos-layout/pkg/decodehook"
-	"github.com/spf13/viper"
-	"go.opentelemetry.io/otel/exporters/trace/jaeger"
+	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/go-kratos/kratos-layout/internal/conf"
+	"github.com/go-kratos/kratos-layout/internal/data"
+	"github.com/go-kratos/kratos-layout/pkg/config"
+	"github.com/go-kratos/kratos-layout/pkg/logger"
+	"github.com/go-kratos/kratos-layout/pkg/nosql"
+	"github.com/go-kratos/kratos-layout/pkg/queue"
+	"github.com/go-kratos/kratos-layout/pkg/scheduler"
+	"github.com/go-kratos/kratos-layout/pkg/tools"
 	"github.com/go-kratos/kratos/v2"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/transport/grpc"
 	"github.com/go-kratos/kratos/v2/transport/http"
+	"go.opentelemetry.io/otel/exporters/trace/jaeger"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
 	_ "github.com/go-sql-driver/mysql"
 )
 
-// go build -ldflags "-X main.Version=x.y.z"
+// go build -ldflags "-X main.Version=x.y.z -X main.BuildTime=..."
 var (
 	// Name is the name of the compiled software.
 	Name string
@@ -31,12 +36,14 @@ var (
 	flagconf string
 )
 
-func init() {
-	flag.StringVar(&flagconf, "conf", "./conf", "config path, eg: -conf application.yaml")
+// application wire组装的应用，组装时不访问数据库，升级由serve在启动前显式执行
+type application struct {
+	*kratos.App
+	migrator *data.Migrator
 }
 
-func newApp(logger log.Logger, hs *http.Server, gs *grpc.Server, data *data.Data) *kratos.App {
-	return kratos.New(
+func newApp(logger log.Logger, hs *http.Server, gs *grpc.Server, data *data.Data, migrator *data.Migrator, flusher *data.CounterFlusher, jobs *queue.Queue, cron *scheduler.Scheduler, relay *data.EventRelay, watcher *data.GreeterCacheWatcher) *application {
+	app := kratos.New(
 		kratos.Name(Name),
 		kratos.Version(Version),
 		kratos.Metadata(map[string]string{}),
@@ -45,56 +52,128 @@ func newApp(logger log.Logger, hs *http.Server, gs *grpc.Server, data *data.Data
 			hs,
 			gs,
 			data,
+			flusher,
+			jobs,
+			cron,
+			relay,
+			watcher,
 		),
 	)
+	return &application{App: app, migrator: migrator}
 }
 
-func main() {
-	flag.Parse()
-	env, exist := os.LookupEnv("DEPLOY_ENV")
-	logger := log.With(log.NewStdLogger(os.Stdout),
-		"service.name", Name,
-		"service.version", Version,
-		"service.buildTime", BuildTime,
-		"service.deploy", env,
-		"ts", log.DefaultTimestamp,
-		"caller", log.DefaultCaller,
-	)
-	if err := config.Init("", Name); err != nil {
-		panic(err)
-	}
-
-	s := new(conf.Server)
-	d := new(conf.Data)
-	t := new(conf.OTEL)
-	if err := viper.UnmarshalKey("server", s, viper.DecodeHook(decodehook.StringToTimeDurationHookFunc())); err != nil {
-		panic(err)
+// newLogger 根据配置创建日志，没有配置时输出到标准输出
+func newLogger(c *conf.Log) (log.Logger, func()) {
+	o := logger.Options{
+		Level:   c.Level,
+		Format:  c.Format,
+		Modules: c.Modules,
+		Trace:   c.Trace,
 	}
-	if err := viper.UnmarshalKey("data", d, viper.DecodeHook(decodehook.StringToTimeDurationHookFunc())); err != nil {
-		panic(err)
+	if r := c.Rotate; r != nil {
+		o.Rotate = &logger.Rotate{
+			Filename:   r.Filename,
+			MaxSize:    int(r.MaxSize),
+			MaxBackups: int(r.MaxBackups),
+			MaxAge:     int(r.MaxAge),
+			Compress:   r.Compress,
+		}
 	}
-	if err := viper.UnmarshalKey("otel", t, viper.DecodeHook(decodehook.StringToTimeDurationHookFunc())); err != nil {
-		panic(err)
+	if sp := c.Sampling; sp != nil {
+		o.Sample = &logger.Sample{
+			Tick:       sp.Tick.AsDuration(),
+			Initial:    int(sp.Initial),
+			Thereafter: int(sp.Thereafter),
+		}
 	}
+	return logger.New(o)
+}
 
-	var tp *sdktrace.TracerProvider
+// newTracerProvider 非生产环境全部采样
+func newTracerProvider(c *conf.OTEL) (*sdktrace.TracerProvider, error) {
+	exporter, err := jaeger.NewRawExporter(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(c.CollectorEndpoint)))
+	if err != nil {
+		return nil, err
+	}
+	if env, exist := os.LookupEnv("DEPLOY_ENV"); exist && env != "production" {
+		return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithSampler(sdktrace.AlwaysSample())), nil
+	}
+	return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithSampler(sdktrace.NeverSample())), nil
+}
 
-	if exporter, err := jaeger.NewRawExporter(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(t.CollectorEndpoint))); err != nil {
-		panic(err)
-	} else if exist && env != "production" {
-		tp = sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithSampler(sdktrace.AlwaysSample()))
-	} else {
-		tp = sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithSampler(sdktrace.NeverSample()))
+// loadBootstrap 读取配置文件并解码到conf.Bootstrap，-conf可以是目录或文件
+func loadBootstrap() (*conf.Bootstrap, error) {
+	path := flagconf
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "application.yaml")
+	}
+	if err := config.Init(path, Name); err != nil {
+		return nil, err
+	}
+	bc := &conf.Bootstrap{
+		Server: new(conf.Server),
+		Data:   new(conf.Data),
+		Otel:   new(conf.OTEL),
+		Log:    new(conf.Log),
+		App:    new(conf.App),
+	}
+	for key, v := range map[string]interface{}{
+		"server": bc.Server,
+		"data":   bc.Data,
+		"otel":   bc.Otel,
+		"log":    bc.Log,
+		"app":    bc.App,
+	} {
+		if err := config.UnmarshalKey(key, v); err != nil {
+			return nil, fmt.Errorf("解析%s配置失败: %w", key, err)
+		}
 	}
+	return bc, nil
+}
 
-	app, cleanup, err := initApp(s, d, tp, logger)
+// setup 读取配置并创建日志，所有需要配置的子命令共用
+func setup() (*conf.Bootstrap, log.Logger, func(), error) {
+	bc, err := loadBootstrap()
 	if err != nil {
-		panic(err)
+		return nil, nil, nil, err
+	}
+	if err = bc.Log.Validate(); err != nil {
+		return nil, nil, nil, err
 	}
-	defer cleanup()
+	// 需要在创建mysql连接和解析时间之前设置
+	if err = tools.SetTimezone(bc.App.Timezone); err != nil {
+		return nil, nil, nil, err
+	}
+	std, closeLog := newLogger(bc.Log)
+	logger := log.With(std,
+		"service.name", Name,
+		"service.version", Version,
+		"service.buildTime", BuildTime,
+		"service.deploy", os.Getenv("DEPLOY_ENV"),
+		"ts", log.DefaultTimestamp,
+		"caller", log.DefaultCaller,
+	)
+	config.SetLogger(logger)
+	nosql.SetLogger(logger)
+	return bc, logger, closeLog, nil
+}
 
-	// start and wait for stop signal
-	if err := app.Run(); err != nil {
-		panic(err)
+func main() {
+	name, args := "serve", os.Args[1:]
+	// 兼容旧的启动方式: server -conf /data/conf
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		name, args = args[0], args[1:]
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(args); err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		}
+		os.Exit(1)
 	}
 }
@@ -10,13 +10,17 @@ import (
 	"github.com/go-kratos/kratos-layout/internal/data"
 	"github.com/go-kratos/kratos-layout/internal/server"
 	"github.com/go-kratos/kratos-layout/internal/service"
-	"github.com/go-kratos/kratos/v2"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/google/wire"
 	"go.opentelemetry.io/otel/trace"
 )
 
 // initApp init kratos application.
-func initApp(*conf.Server, *conf.Data, trace.TracerProvider, log.Logger) (*kratos.App, func(), error) {
-	panic(wire.Build(server.ProviderSet, data.ProviderSet, biz.ProviderSet, service.ProviderSet, newApp))
+func initApp(*conf.Server, *conf.Data, trace.TracerProvider, log.Logger) (*application, func(), error) {
+	panic(wire.Build(server.ProviderSet, data.ProviderSet, biz.ProviderSet, service.ProviderSet, wire.Bind(new(server.HealthChecker), new(*data.Data)), newApp))
+}
+
+// initMigrator init data migrator without starting servers.
+func initMigrator(*conf.Data, log.Logger) (*data.Migrator, func(), error) {
+	panic(wire.Build(data.ProviderSet))
 }
@@ -11,25 +11,51 @@ import (
 	"github.com/go-kratos/kratos-layout/internal/data"
 	"github.com/go-kratos/kratos-layout/internal/server"
 	"github.com/go-kratos/kratos-layout/internal/service"
-	"github.com/go-kratos/kratos/v2"
 	"github.com/go-kratos/kratos/v2/log"
 	"go.opentelemetry.io/otel/trace"
 )
 
 // Injectors from wire.go:
 
-func initApp(confServer *conf.Server, confData *conf.Data, tracerProvider trace.TracerProvider, logger log.Logger) (*kratos.App, func(), error) {
+func initApp(confServer *conf.Server, confData *conf.Data, tracerProvider trace.TracerProvider, logger log.Logger) (*application, func(), error) {
 	dataData, cleanup, err := data.NewData(confData, logger)
 	if err != nil {
 		return nil, nil, err
 	}
 	greeterRepo := data.NewGreeterRepo(dataData, logger)
-	greeterUsecase := biz.NewGreeterUsecase(greeterRepo, logger)
+	transaction := data.NewTransaction(dataData)
+	eventRepo := data.NewEventRepo(confData, dataData)
+	greeterUsecase := biz.NewGreeterUsecase(greeterRepo, transaction, eventRepo, logger)
 	greeterService := service.NewGreeterService(greeterUsecase, logger)
-	httpServer := server.NewHTTPServer(confServer, greeterService, logger)
+	httpServer := server.NewHTTPServer(confServer, greeterService, dataData, logger)
 	grpcServer := server.NewGRPCServer(confServer, greeterService, tracerProvider, logger)
-	app := newApp(logger, httpServer, grpcServer, dataData)
-	return app, func() {
+	counterRepo := data.NewCounterRepo(confData, dataData, logger)
+	migrator := data.NewMigrator(dataData, greeterRepo, eventRepo, counterRepo, logger)
+	counterFlusher := data.NewCounterFlusher(confData, dataData, counterRepo)
+	queue := data.NewQueue(confData, dataData, counterRepo, tracerProvider, logger)
+	scheduler, err := data.NewScheduler(dataData, migrator, counterRepo, queue, eventRepo, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	eventRelay := data.NewEventRelay(confData, dataData, eventRepo, logger)
+	greeterCacheWatcher := data.NewGreeterCacheWatcher(dataData, greeterRepo)
+	mainApplication := newApp(logger, httpServer, grpcServer, dataData, migrator, counterFlusher, queue, scheduler, eventRelay, greeterCacheWatcher)
+	return mainApplication, func() {
+		cleanup()
+	}, nil
+}
+
+func initMigrator(confData *conf.Data, logger log.Logger) (*data.Migrator, func(), error) {
+	dataData, cleanup, err := data.NewData(confData, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	greeterRepo := data.NewGreeterRepo(dataData, logger)
+	eventRepo := data.NewEventRepo(confData, dataData)
+	counterRepo := data.NewCounterRepo(confData, dataData, logger)
+	migrator := data.NewMigrator(dataData, greeterRepo, eventRepo, counterRepo, logger)
+	return migrator, func() {
 		cleanup()
 	}, nil
 }
@@ -0,0 +1,77 @@
+package biz
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// 计数的名字
+const (
+	CounterArticleLike = "article:like"
+)
+
+// Rank 排行榜的一项
+type Rank struct {
+	ID    string
+	Score int64
+}
+
+// CounterRepo 计数，data层基于redis实现，定期写入mysql
+type CounterRepo interface {
+	// Add 计数加delta，member不为空时去重，member重复加入或者没有加入过时退出返回false
+	Add(ctx context.Context, name, id, member string, delta int64) (bool, error)
+	// IsMember member是否已经加入
+	IsMember(ctx context.Context, name, id, member string) (bool, error)
+	// Get 批量查询计数，结果包含全部ids
+	Get(ctx context.Context, name string, ids ...string) (map[string]int64, error)
+	// Top 排行榜前n名
+	Top(ctx context.Context, name string, n int) ([]Rank, error)
+}
+
+type CounterUsecase struct {
+	repo CounterRepo
+	log  *log.Helper
+}
+
+func NewCounterUsecase(repo CounterRepo, logger log.Logger) *CounterUsecase {
+	return &CounterUsecase{repo: repo, log: log.NewHelper(logger)}
+}
+
+// Like 点赞，同一个用户只计一次，返回是否是新的点赞
+func (uc *CounterUsecase) Like(ctx context.Context, articleID int64, userID string) (bool, error) {
+	return uc.repo.Add(ctx, CounterArticleLike, strconv.FormatInt(articleID, 10), userID, 1)
+}
+
+// Unlike 取消点赞，没有点赞过时返回false
+func (uc *CounterUsecase) Unlike(ctx context.Context, articleID int64, userID string) (bool, error) {
+	return uc.repo.Add(ctx, CounterArticleLike, strconv.FormatInt(articleID, 10), userID, -1)
+}
+
+// Liked 用户是否点赞过
+func (uc *CounterUsecase) Liked(ctx context.Context, articleID int64, userID string) (bool, error) {
+	return uc.repo.IsMember(ctx, CounterArticleLike, strconv.FormatInt(articleID, 10), userID)
+}
+
+// Likes 批量查询点赞数
+func (uc *CounterUsecase) Likes(ctx context.Context, articleIDs ...int64) (map[int64]int64, error) {
+	ids := make([]string, 0, len(articleIDs))
+	for _, id := range articleIDs {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	counts, err := uc.repo.Get(ctx, CounterArticleLike, ids...)
+	if err != nil {
+		return nil, err
+	}
+	likes := make(map[int64]int64, len(counts))
+	for i, id := range ids {
+		likes[articleIDs[i]] = counts[id]
+	}
+	return likes, nil
+}
+
+// TopLiked 点赞最多的n篇文章
+func (uc *CounterUsecase) TopLiked(ctx context.Context, n int) ([]Rank, error) {
+	return uc.repo.Top(ctx, CounterArticleLike, n)
+}
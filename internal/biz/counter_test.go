@@ -0,0 +1,27 @@
+package biz
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounterUsecase(t *testing.T) {
+	controller, ctx := gomock.WithContext(context.Background(), t)
+	repo := NewMockCounterRepo(controller)
+	uc := NewCounterUsecase(repo, log.NewStdLogger(ioutil.Discard))
+
+	repo.EXPECT().Add(ctx, CounterArticleLike, "1", "u1", int64(1)).Return(true, nil)
+	liked, err := uc.Like(ctx, 1, "u1")
+	require.NoError(t, err)
+	require.True(t, liked)
+
+	repo.EXPECT().Get(ctx, CounterArticleLike, "1", "2").Return(map[string]int64{"1": 3, "2": 0}, nil)
+	likes, err := uc.Likes(ctx, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, map[int64]int64{1: 3, 2: 0}, likes)
+}
@@ -0,0 +1,37 @@
+package biz
+
+import "context"
+
+// 领域事件的topic
+const (
+	TopicGreeterCreated = "greeter.created"
+	TopicGreeterUpdated = "greeter.updated"
+	TopicGreeterDeleted = "greeter.deleted"
+)
+
+// Event 领域事件
+type Event struct {
+	Topic   string
+	Key     string      // 同一个Key的事件按发布顺序投递，一般为聚合的id
+	Payload interface{} // 使用json编码
+}
+
+// EventPublisher 发布领域事件，必须在Transaction.InTx中调用
+// 事件和状态变更在同一个事务中写入outbox，事务提交后由relay至少一次地投递到broker
+type EventPublisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// GreeterChanged greeter事件的内容，删除时只有ID
+type GreeterChanged struct {
+	ID    string `json:"id"`
+	Hello string `json:"hello,omitempty"`
+}
+
+func greeterEvent(topic string, g *Greeter) Event {
+	return Event{
+		Topic:   topic,
+		Key:     string(g.ID),
+		Payload: GreeterChanged{ID: string(g.ID), Hello: g.Hello},
+	}
+}
@@ -2,7 +2,12 @@ package biz
 
 import (
 	"context"
+	"errors"
 
+	"github.com/go-kratos/kratos-layout/pkg/nosql"
+	"github.com/go-kratos/kratos-layout/pkg/objectid"
+	"github.com/go-kratos/kratos-layout/pkg/tools"
+	"github.com/go-kratos/kratos-layout/pkg/version"
 	"github.com/go-kratos/kratos/v2/log"
 )
 
@@ -11,28 +16,136 @@ const (
 	DBGreeterKey     = "greeter"
 )
 
+var (
+	// ErrGreeterNotFound greeter不存在
+	ErrGreeterNotFound = errors.New("greeter not found")
+	// ErrInvalidResumeToken 继续订阅的位置无效
+	ErrInvalidResumeToken = errors.New("invalid resume token")
+)
+
 type Greeter struct {
-	Hello string
+	ID         objectid.ObjectID `bson:"_id,omitempty"`
+	Hello      string            `bson:"hello"`
+	CreateTime tools.Time        `bson:"createTime"`
+	UpdateTime tools.Time        `bson:"updateTime"`
+	Meta       version.DbMeta    `bson:"meta"`
+}
+
+// GreeterEventType greeter的变更类型
+type GreeterEventType int32
+
+const (
+	GreeterCreated GreeterEventType = iota + 1
+	GreeterUpdated
+	GreeterDeleted
+)
+
+// GreeterEvent greeter的变更，删除时Greeter只有ID
+type GreeterEvent struct {
+	Type        GreeterEventType
+	Greeter     *Greeter
+	ResumeToken string // 从这个变更之后继续订阅
+}
+
+// GreeterWatch 订阅greeter变更的条件
+type GreeterWatch struct {
+	ResumeToken string             // 为空时从当前开始
+	Types       []GreeterEventType // 为空时订阅全部类型
+}
+
+// GreeterWatcher greeter的变更流
+type GreeterWatcher interface {
+	// Next 阻塞到下一个变更，变更流结束时返回io.EOF
+	Next(ctx context.Context) (*GreeterEvent, error)
+	Close(ctx context.Context) error
 }
 
 type GreeterRepo interface {
 	CreateGreeter(context.Context, *Greeter) error
-	UpdateGreeter(context.Context, *Greeter) error
+	GetGreeter(ctx context.Context, id string, fields ...string) (*Greeter, error)
+	ListGreeter(ctx context.Context, req nosql.TableRequest) ([]*Greeter, int64, error)
+	UpdateGreeter(ctx context.Context, g *Greeter, fields []string) error
+	DeleteGreeter(ctx context.Context, id string) error
+	WatchGreeter(ctx context.Context, w GreeterWatch) (GreeterWatcher, error)
 }
 
+// GreeterUsecase greeter和事件都保存在mongo，写操作的事务只使用mongo，不依赖mysql
 type GreeterUsecase struct {
-	repo GreeterRepo
-	log  *log.Helper
+	repo   GreeterRepo
+	tx     Transaction
+	events EventPublisher
+	log    *log.Helper
 }
 
-func NewGreeterUsecase(repo GreeterRepo, logger log.Logger) *GreeterUsecase {
-	return &GreeterUsecase{repo: repo, log: log.NewHelper(logger)}
+func NewGreeterUsecase(repo GreeterRepo, tx Transaction, events EventPublisher, logger log.Logger) *GreeterUsecase {
+	return &GreeterUsecase{repo: repo, tx: tx, events: events, log: log.NewHelper(logger)}
 }
 
+// Create 创建时生成ID和时间，和greeter.created事件在同一个事务中写入
 func (uc *GreeterUsecase) Create(ctx context.Context, g *Greeter) error {
-	return uc.repo.CreateGreeter(ctx, g)
+	now := tools.Now()
+	g.ID = objectid.New()
+	g.CreateTime = now
+	g.UpdateTime = now
+	g.Meta.Version = DBGreeterVersion
+	return uc.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := uc.repo.CreateGreeter(ctx, g); err != nil {
+			return err
+		}
+		return uc.events.Publish(ctx, greeterEvent(TopicGreeterCreated, g))
+	})
+}
+
+// Get fields为空时返回全部字段
+func (uc *GreeterUsecase) Get(ctx context.Context, id string, fields ...string) (*Greeter, error) {
+	return uc.repo.GetGreeter(ctx, id, fields...)
+}
+
+// List 查询条件和排序见data层的greeterQuerySpec
+func (uc *GreeterUsecase) List(ctx context.Context, req nosql.TableRequest) ([]*Greeter, int64, error) {
+	if err := req.Validate(); err != nil {
+		return nil, 0, err
+	}
+	return uc.repo.ListGreeter(ctx, req)
+}
+
+// Update 更新fields中的字段，返回更新后的greeter，在同一个事务中读取，不会读到其他请求的修改
+func (uc *GreeterUsecase) Update(ctx context.Context, g *Greeter, fields []string) (*Greeter, error) {
+	g.UpdateTime = tools.Now()
+	// 复制后再追加，不修改调用方的底层数组
+	fields = append(fields[:len(fields):len(fields)], "updateTime")
+	var updated *Greeter
+	err := uc.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := uc.repo.UpdateGreeter(ctx, g, fields); err != nil {
+			return err
+		}
+		var err error
+		if updated, err = uc.repo.GetGreeter(ctx, string(g.ID)); err != nil {
+			return err
+		}
+		return uc.events.Publish(ctx, greeterEvent(TopicGreeterUpdated, updated))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete 删除和greeter.deleted事件在同一个事务中，id格式错误时返回objectid.ErrInvalid
+func (uc *GreeterUsecase) Delete(ctx context.Context, id string) error {
+	oid, err := objectid.Parse(id)
+	if err != nil {
+		return err
+	}
+	return uc.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := uc.repo.DeleteGreeter(ctx, string(oid)); err != nil {
+			return err
+		}
+		return uc.events.Publish(ctx, greeterEvent(TopicGreeterDeleted, &Greeter{ID: oid}))
+	})
 }
 
-func (uc *GreeterUsecase) Update(ctx context.Context, g *Greeter) error {
-	return uc.repo.UpdateGreeter(ctx, g)
+// Watch 订阅greeter变更，返回时订阅已经生效
+func (uc *GreeterUsecase) Watch(ctx context.Context, w GreeterWatch) (GreeterWatcher, error) {
+	return uc.repo.WatchGreeter(ctx, w)
 }
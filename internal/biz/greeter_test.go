@@ -2,6 +2,9 @@ package biz
 
 import (
 	"context"
+	"errors"
+	"github.com/go-kratos/kratos-layout/pkg/nosql"
+	"github.com/go-kratos/kratos-layout/pkg/objectid"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -17,6 +20,70 @@ func TestInit(t *testing.T) {
 	)
 	controller, ctx = gomock.WithContext(ctx, t)
 	repo := NewMockGreeterRepo(controller)
-	testBiz, err = newBiz(repo)
+	testBiz, err = newBiz(repo, NewMockTransaction(controller), NewMockEventPublisher(controller))
 	require.NoError(t, err)
 }
+
+func TestGreeterUsecase(t *testing.T) {
+	controller, ctx := gomock.WithContext(context.Background(), t)
+	repo := NewMockGreeterRepo(controller)
+	tx := NewMockTransaction(controller)
+	events := NewMockEventPublisher(controller)
+	uc, err := newBiz(repo, tx, events)
+	require.NoError(t, err)
+
+	// 事件和变更在同一个事务中写入
+	var created *Greeter
+	ExpectInTx(tx)
+	gomock.InOrder(
+		repo.EXPECT().CreateGreeter(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, g *Greeter) error {
+			created = g
+			return nil
+		}),
+		events.EXPECT().Publish(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, events ...Event) error {
+			require.Equal(t, []Event{{
+				Topic:   TopicGreeterCreated,
+				Key:     string(created.ID),
+				Payload: GreeterChanged{ID: string(created.ID), Hello: "kratos"},
+			}}, events)
+			return nil
+		}),
+	)
+	g := &Greeter{Hello: "kratos"}
+	require.NoError(t, uc.Create(ctx, g))
+	require.NotEmpty(t, created.ID)
+	require.False(t, created.CreateTime.IsZero())
+	require.Equal(t, created.CreateTime, created.UpdateTime)
+	require.EqualValues(t, DBGreeterVersion, created.Meta.Version)
+
+	ExpectInTx(tx).Times(2)
+	repo.EXPECT().UpdateGreeter(ctx, gomock.Any(), []string{"hello", "updateTime"}).Return(ErrGreeterNotFound)
+	_, err = uc.Update(ctx, &Greeter{ID: g.ID, Hello: "go"}, []string{"hello"})
+	require.ErrorIs(t, err, ErrGreeterNotFound)
+
+	// 更新和读取在同一个事务中
+	gomock.InOrder(
+		repo.EXPECT().UpdateGreeter(ctx, gomock.Any(), []string{"hello", "updateTime"}).Return(nil),
+		repo.EXPECT().GetGreeter(ctx, string(g.ID)).Return(&Greeter{ID: g.ID, Hello: "go"}, nil),
+		events.EXPECT().Publish(ctx, greeterEvent(TopicGreeterUpdated, &Greeter{ID: g.ID, Hello: "go"})).Return(nil),
+	)
+	// 调用方的fields有剩余容量时也不会被修改
+	fields := make([]string, 1, 2)
+	fields[0] = "hello"
+	updated, err := uc.Update(ctx, &Greeter{ID: g.ID, Hello: "go"}, fields)
+	require.NoError(t, err)
+	require.Equal(t, "go", updated.Hello)
+	require.Equal(t, []string{"hello"}, fields)
+	require.Equal(t, "", fields[:2][1])
+
+	// 事件写入失败时删除也回滚
+	ExpectInTx(tx)
+	repo.EXPECT().DeleteGreeter(ctx, string(g.ID)).Return(nil)
+	events.EXPECT().Publish(ctx, greeterEvent(TopicGreeterDeleted, &Greeter{ID: g.ID})).Return(errors.New("outbox"))
+	require.Error(t, uc.Delete(ctx, string(g.ID)))
+	// 格式错误的id不开始事务
+	require.ErrorIs(t, uc.Delete(ctx, "bad"), objectid.ErrInvalid)
+
+	_, _, err = uc.List(ctx, nosql.TableRequest{Limit: -1})
+	require.Error(t, err)
+}
@@ -0,0 +1,23 @@
+package biz
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos-layout/pkg/queue"
+)
+
+// 后台任务的类型
+const (
+	JobCounterReconcile = "counter:reconcile" // 以mysql为准校正计数，payload为CounterReconcile
+)
+
+// CounterReconcile JobCounterReconcile的参数
+type CounterReconcile struct {
+	Name string   `json:"name"`
+	IDs  []string `json:"ids"`
+}
+
+// JobQueue 后台任务，请求中不需要等待的工作入队后由worker执行
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...queue.EnqueueOption) (string, error)
+}
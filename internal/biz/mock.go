@@ -1,3 +1,3 @@
 package biz
 
-//go:generate mockgen  -destination ./mock_IMockService.go -package biz github.com/go-kratos/kratos-layout/internal/biz GreeterRepo
+//go:generate mockgen  -destination ./mock_IMockService.go -package biz github.com/go-kratos/kratos-layout/internal/biz GreeterRepo,GreeterWatcher,Transaction,CounterRepo,EventPublisher
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/go-kratos/kratos-layout/internal/biz (interfaces: GreeterRepo)
+// Source: github.com/go-kratos/kratos-layout/internal/biz (interfaces: GreeterRepo,GreeterWatcher,Transaction,CounterRepo,EventPublisher)
 
 // Package biz is a generated GoMock package.
 package biz
@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	nosql "github.com/go-kratos/kratos-layout/pkg/nosql"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -48,16 +49,300 @@ func (mr *MockGreeterRepoMockRecorder) CreateGreeter(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGreeter", reflect.TypeOf((*MockGreeterRepo)(nil).CreateGreeter), arg0, arg1)
 }
 
+// DeleteGreeter mocks base method.
+func (m *MockGreeterRepo) DeleteGreeter(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGreeter", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGreeter indicates an expected call of DeleteGreeter.
+func (mr *MockGreeterRepoMockRecorder) DeleteGreeter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGreeter", reflect.TypeOf((*MockGreeterRepo)(nil).DeleteGreeter), arg0, arg1)
+}
+
+// GetGreeter mocks base method.
+func (m *MockGreeterRepo) GetGreeter(arg0 context.Context, arg1 string, arg2 ...string) (*Greeter, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetGreeter", varargs...)
+	ret0, _ := ret[0].(*Greeter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGreeter indicates an expected call of GetGreeter.
+func (mr *MockGreeterRepoMockRecorder) GetGreeter(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGreeter", reflect.TypeOf((*MockGreeterRepo)(nil).GetGreeter), varargs...)
+}
+
+// ListGreeter mocks base method.
+func (m *MockGreeterRepo) ListGreeter(arg0 context.Context, arg1 nosql.TableRequest) ([]*Greeter, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGreeter", arg0, arg1)
+	ret0, _ := ret[0].([]*Greeter)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListGreeter indicates an expected call of ListGreeter.
+func (mr *MockGreeterRepoMockRecorder) ListGreeter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGreeter", reflect.TypeOf((*MockGreeterRepo)(nil).ListGreeter), arg0, arg1)
+}
+
 // UpdateGreeter mocks base method.
-func (m *MockGreeterRepo) UpdateGreeter(arg0 context.Context, arg1 *Greeter) error {
+func (m *MockGreeterRepo) UpdateGreeter(arg0 context.Context, arg1 *Greeter, arg2 []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateGreeter", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdateGreeter", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateGreeter indicates an expected call of UpdateGreeter.
-func (mr *MockGreeterRepoMockRecorder) UpdateGreeter(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockGreeterRepoMockRecorder) UpdateGreeter(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGreeter", reflect.TypeOf((*MockGreeterRepo)(nil).UpdateGreeter), arg0, arg1, arg2)
+}
+
+// WatchGreeter mocks base method.
+func (m *MockGreeterRepo) WatchGreeter(arg0 context.Context, arg1 GreeterWatch) (GreeterWatcher, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchGreeter", arg0, arg1)
+	ret0, _ := ret[0].(GreeterWatcher)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchGreeter indicates an expected call of WatchGreeter.
+func (mr *MockGreeterRepoMockRecorder) WatchGreeter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchGreeter", reflect.TypeOf((*MockGreeterRepo)(nil).WatchGreeter), arg0, arg1)
+}
+
+// MockGreeterWatcher is a mock of GreeterWatcher interface.
+type MockGreeterWatcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockGreeterWatcherMockRecorder
+}
+
+// MockGreeterWatcherMockRecorder is the mock recorder for MockGreeterWatcher.
+type MockGreeterWatcherMockRecorder struct {
+	mock *MockGreeterWatcher
+}
+
+// NewMockGreeterWatcher creates a new mock instance.
+func NewMockGreeterWatcher(ctrl *gomock.Controller) *MockGreeterWatcher {
+	mock := &MockGreeterWatcher{ctrl: ctrl}
+	mock.recorder = &MockGreeterWatcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGreeterWatcher) EXPECT() *MockGreeterWatcherMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockGreeterWatcher) Close(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockGreeterWatcherMockRecorder) Close(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockGreeterWatcher)(nil).Close), arg0)
+}
+
+// Next mocks base method.
+func (m *MockGreeterWatcher) Next(arg0 context.Context) (*GreeterEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Next", arg0)
+	ret0, _ := ret[0].(*GreeterEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Next indicates an expected call of Next.
+func (mr *MockGreeterWatcherMockRecorder) Next(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Next", reflect.TypeOf((*MockGreeterWatcher)(nil).Next), arg0)
+}
+
+// MockTransaction is a mock of Transaction interface.
+type MockTransaction struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactionMockRecorder
+}
+
+// MockTransactionMockRecorder is the mock recorder for MockTransaction.
+type MockTransactionMockRecorder struct {
+	mock *MockTransaction
+}
+
+// NewMockTransaction creates a new mock instance.
+func NewMockTransaction(ctrl *gomock.Controller) *MockTransaction {
+	mock := &MockTransaction{ctrl: ctrl}
+	mock.recorder = &MockTransactionMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransaction) EXPECT() *MockTransactionMockRecorder {
+	return m.recorder
+}
+
+// InTx mocks base method.
+func (m *MockTransaction) InTx(arg0 context.Context, arg1 func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InTx indicates an expected call of InTx.
+func (mr *MockTransactionMockRecorder) InTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InTx", reflect.TypeOf((*MockTransaction)(nil).InTx), arg0, arg1)
+}
+
+// MockCounterRepo is a mock of CounterRepo interface.
+type MockCounterRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockCounterRepoMockRecorder
+}
+
+// MockCounterRepoMockRecorder is the mock recorder for MockCounterRepo.
+type MockCounterRepoMockRecorder struct {
+	mock *MockCounterRepo
+}
+
+// NewMockCounterRepo creates a new mock instance.
+func NewMockCounterRepo(ctrl *gomock.Controller) *MockCounterRepo {
+	mock := &MockCounterRepo{ctrl: ctrl}
+	mock.recorder = &MockCounterRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCounterRepo) EXPECT() *MockCounterRepoMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockCounterRepo) Add(arg0 context.Context, arg1, arg2, arg3 string, arg4 int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockCounterRepoMockRecorder) Add(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockCounterRepo)(nil).Add), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Get mocks base method.
+func (m *MockCounterRepo) Get(arg0 context.Context, arg1 string, arg2 ...string) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Get", varargs...)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockCounterRepoMockRecorder) Get(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCounterRepo)(nil).Get), varargs...)
+}
+
+// IsMember mocks base method.
+func (m *MockCounterRepo) IsMember(arg0 context.Context, arg1, arg2, arg3 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMember", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsMember indicates an expected call of IsMember.
+func (mr *MockCounterRepoMockRecorder) IsMember(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMember", reflect.TypeOf((*MockCounterRepo)(nil).IsMember), arg0, arg1, arg2, arg3)
+}
+
+// Top mocks base method.
+func (m *MockCounterRepo) Top(arg0 context.Context, arg1 string, arg2 int) ([]Rank, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Top", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]Rank)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Top indicates an expected call of Top.
+func (mr *MockCounterRepoMockRecorder) Top(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Top", reflect.TypeOf((*MockCounterRepo)(nil).Top), arg0, arg1, arg2)
+}
+
+// MockEventPublisher is a mock of EventPublisher interface.
+type MockEventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventPublisherMockRecorder
+}
+
+// MockEventPublisherMockRecorder is the mock recorder for MockEventPublisher.
+type MockEventPublisherMockRecorder struct {
+	mock *MockEventPublisher
+}
+
+// NewMockEventPublisher creates a new mock instance.
+func NewMockEventPublisher(ctrl *gomock.Controller) *MockEventPublisher {
+	mock := &MockEventPublisher{ctrl: ctrl}
+	mock.recorder = &MockEventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventPublisher) EXPECT() *MockEventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockEventPublisher) Publish(arg0 context.Context, arg1 ...Event) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Publish", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockEventPublisherMockRecorder) Publish(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGreeter", reflect.TypeOf((*MockGreeterRepo)(nil).UpdateGreeter), arg0, arg1)
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockEventPublisher)(nil).Publish), varargs...)
 }
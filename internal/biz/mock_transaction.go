@@ -0,0 +1,14 @@
+package biz
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+)
+
+// ExpectInTx MockTransaction直接在调用方的ctx中执行fn，返回的Call可以继续设置Times等
+func ExpectInTx(m *MockTransaction) *gomock.Call {
+	return m.EXPECT().InTx(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	})
+}
@@ -0,0 +1,16 @@
+package biz
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrPartialCommit 事务涉及多个存储时，部分存储已经提交而其余提交失败，已提交的修改不会回滚，需要补偿或人工处理
+var ErrPartialCommit = errors.New("事务部分提交")
+
+// Transaction 和存储无关的事务，fn中的操作必须使用参数ctx，repo通过ctx加入事务
+type Transaction interface {
+	// InTx fn返回错误时回滚，fn可能因为事务冲突被重试，不能有事务外的副作用
+	// 部分提交时返回的错误可以用errors.Is(err, ErrPartialCommit)判断
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
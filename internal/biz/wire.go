@@ -15,6 +15,6 @@ import (
 )
 
 // newBiz init kratos application.
-func newBiz(repo GreeterRepo) (*GreeterUsecase, error) {
+func newBiz(repo GreeterRepo, tx Transaction, events EventPublisher) (*GreeterUsecase, error) {
 	panic(wire.Build(wire.InterfaceValue(new(io.Writer), os.Stdout), log.NewStdLogger, ProviderSet))
 }
@@ -12,10 +12,10 @@ import (
 
 // Injectors from wire.go:
 
-func newBiz(repo GreeterRepo) (*GreeterUsecase, error) {
+func newBiz(repo GreeterRepo, tx Transaction, events EventPublisher) (*GreeterUsecase, error) {
 	writer := _wireFileValue
 	logger := log.NewStdLogger(writer)
-	greeterUsecase := NewGreeterUsecase(repo, logger)
+	greeterUsecase := NewGreeterUsecase(repo, tx, events, logger)
 	return greeterUsecase, nil
 }
 
@@ -73,6 +73,26 @@ func (m *Bootstrap) Validate() error {
 		}
 	}
 
+	if v, ok := interface{}(m.GetLog()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return BootstrapValidationError{
+				field:  "Log",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if v, ok := interface{}(m.GetApp()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return BootstrapValidationError{
+				field:  "App",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -130,6 +150,72 @@ var _ interface {
 	ErrorName() string
 } = BootstrapValidationError{}
 
+// Validate checks the field values on App with the rules defined in the proto
+// definition for this message. If any rules are violated, an error is returned.
+func (m *App) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	// no validation rules for Timezone
+
+	return nil
+}
+
+// AppValidationError is the validation error returned by App.Validate if the
+// designated constraints aren't met.
+type AppValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e AppValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e AppValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e AppValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e AppValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e AppValidationError) ErrorName() string { return "AppValidationError" }
+
+// Error satisfies the builtin error interface
+func (e AppValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sApp.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = AppValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = AppValidationError{}
+
 // Validate checks the field values on OTEL with the rules defined in the proto
 // definition for this message. If any rules are violated, an error is returned.
 func (m *OTEL) Validate() error {
@@ -196,6 +282,126 @@ var _ interface {
 	ErrorName() string
 } = OTELValidationError{}
 
+// Validate checks the field values on Log with the rules defined in the proto
+// definition for this message. If any rules are violated, an error is returned.
+func (m *Log) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	if _, ok := _Log_Level_InLookup[m.GetLevel()]; !ok {
+		return LogValidationError{
+			field:  "Level",
+			reason: "value must be in list [ debug info warn error DEBUG INFO WARN ERROR]",
+		}
+	}
+
+	if _, ok := _Log_Format_InLookup[m.GetFormat()]; !ok {
+		return LogValidationError{
+			field:  "Format",
+			reason: "value must be in list [ console json]",
+		}
+	}
+
+	if v, ok := interface{}(m.GetRotate()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return LogValidationError{
+				field:  "Rotate",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Modules
+
+	if v, ok := interface{}(m.GetSampling()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return LogValidationError{
+				field:  "Sampling",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Trace
+
+	return nil
+}
+
+// LogValidationError is the validation error returned by Log.Validate if the
+// designated constraints aren't met.
+type LogValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e LogValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e LogValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e LogValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e LogValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e LogValidationError) ErrorName() string { return "LogValidationError" }
+
+// Error satisfies the builtin error interface
+func (e LogValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sLog.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = LogValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = LogValidationError{}
+
+var _Log_Level_InLookup = map[string]struct{}{
+	"":      {},
+	"debug": {},
+	"info":  {},
+	"warn":  {},
+	"error": {},
+	"DEBUG": {},
+	"INFO":  {},
+	"WARN":  {},
+	"ERROR": {},
+}
+
+var _Log_Format_InLookup = map[string]struct{}{
+	"":        {},
+	"console": {},
+	"json":    {},
+}
+
 // Validate checks the field values on HTTP with the rules defined in the proto
 // definition for this message. If any rules are violated, an error is returned.
 func (m *HTTP) Validate() error {
@@ -379,6 +585,16 @@ func (m *Server) Validate() error {
 		}
 	}
 
+	if v, ok := interface{}(m.GetAuth()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ServerValidationError{
+				field:  "Auth",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -436,6 +652,70 @@ var _ interface {
 	ErrorName() string
 } = ServerValidationError{}
 
+// Validate checks the field values on Auth with the rules defined in the proto
+// definition for this message. If any rules are violated, an error is returned.
+func (m *Auth) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	return nil
+}
+
+// AuthValidationError is the validation error returned by Auth.Validate if the
+// designated constraints aren't met.
+type AuthValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e AuthValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e AuthValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e AuthValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e AuthValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e AuthValidationError) ErrorName() string { return "AuthValidationError" }
+
+// Error satisfies the builtin error interface
+func (e AuthValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sAuth.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = AuthValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = AuthValidationError{}
+
 // Validate checks the field values on Mysql with the rules defined in the
 // proto definition for this message. If any rules are violated, an error is returned.
 func (m *Mysql) Validate() error {
@@ -723,37 +1003,48 @@ var _ interface {
 	ErrorName() string
 } = RedisValidationError{}
 
-// Validate checks the field values on Data with the rules defined in the proto
-// definition for this message. If any rules are violated, an error is returned.
-func (m *Data) Validate() error {
+// Validate checks the field values on Cache with the rules defined in the
+// proto definition for this message. If any rules are violated, an error is returned.
+func (m *Cache) Validate() error {
 	if m == nil {
 		return nil
 	}
 
-	if v, ok := interface{}(m.GetMysql()).(interface{ Validate() error }); ok {
+	// no validation rules for Prefix
+
+	if v, ok := interface{}(m.GetTtl()).(interface{ Validate() error }); ok {
 		if err := v.Validate(); err != nil {
-			return DataValidationError{
-				field:  "Mysql",
+			return CacheValidationError{
+				field:  "Ttl",
 				reason: "embedded message failed validation",
 				cause:  err,
 			}
 		}
 	}
 
-	if v, ok := interface{}(m.GetRedis()).(interface{ Validate() error }); ok {
+	if v, ok := interface{}(m.GetNegativeTtl()).(interface{ Validate() error }); ok {
 		if err := v.Validate(); err != nil {
-			return DataValidationError{
-				field:  "Redis",
+			return CacheValidationError{
+				field:  "NegativeTtl",
 				reason: "embedded message failed validation",
 				cause:  err,
 			}
 		}
 	}
 
-	if v, ok := interface{}(m.GetMongodb()).(interface{ Validate() error }); ok {
+	if val := m.GetJitter(); val < 0 || val > 1 {
+		return CacheValidationError{
+			field:  "Jitter",
+			reason: "value must be inside range [0, 1]",
+		}
+	}
+
+	// no validation rules for LocalSize
+
+	if v, ok := interface{}(m.GetLocalTtl()).(interface{ Validate() error }); ok {
 		if err := v.Validate(); err != nil {
-			return DataValidationError{
-				field:  "Mongodb",
+			return CacheValidationError{
+				field:  "LocalTtl",
 				reason: "embedded message failed validation",
 				cause:  err,
 			}
@@ -763,9 +1054,9 @@ func (m *Data) Validate() error {
 	return nil
 }
 
-// DataValidationError is the validation error returned by Data.Validate if the
-// designated constraints aren't met.
-type DataValidationError struct {
+// CacheValidationError is the validation error returned by Cache.Validate if
+// the designated constraints aren't met.
+type CacheValidationError struct {
 	field  string
 	reason string
 	cause  error
@@ -773,22 +1064,22 @@ type DataValidationError struct {
 }
 
 // Field function returns field value.
-func (e DataValidationError) Field() string { return e.field }
+func (e CacheValidationError) Field() string { return e.field }
 
 // Reason function returns reason value.
-func (e DataValidationError) Reason() string { return e.reason }
+func (e CacheValidationError) Reason() string { return e.reason }
 
 // Cause function returns cause value.
-func (e DataValidationError) Cause() error { return e.cause }
+func (e CacheValidationError) Cause() error { return e.cause }
 
 // Key function returns key value.
-func (e DataValidationError) Key() bool { return e.key }
+func (e CacheValidationError) Key() bool { return e.key }
 
 // ErrorName returns error name.
-func (e DataValidationError) ErrorName() string { return "DataValidationError" }
+func (e CacheValidationError) ErrorName() string { return "CacheValidationError" }
 
 // Error satisfies the builtin error interface
-func (e DataValidationError) Error() string {
+func (e CacheValidationError) Error() string {
 	cause := ""
 	if e.cause != nil {
 		cause = fmt.Sprintf(" | caused by: %v", e.cause)
@@ -800,14 +1091,14 @@ func (e DataValidationError) Error() string {
 	}
 
 	return fmt.Sprintf(
-		"invalid %sData.%s: %s%s",
+		"invalid %sCache.%s: %s%s",
 		key,
 		e.field,
 		e.reason,
 		cause)
 }
 
-var _ error = DataValidationError{}
+var _ error = CacheValidationError{}
 
 var _ interface {
 	Field() string
@@ -815,4 +1106,547 @@ var _ interface {
 	Key() bool
 	Cause() error
 	ErrorName() string
-} = DataValidationError{}
+} = CacheValidationError{}
+
+// Validate checks the field values on Counter with the rules defined in the
+// proto definition for this message. If any rules are violated, an error is returned.
+func (m *Counter) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	if v, ok := interface{}(m.GetFlushInterval()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return CounterValidationError{
+				field:  "FlushInterval",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Shards
+
+	return nil
+}
+
+// CounterValidationError is the validation error returned by Counter.Validate
+// if the designated constraints aren't met.
+type CounterValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CounterValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CounterValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CounterValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CounterValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CounterValidationError) ErrorName() string { return "CounterValidationError" }
+
+// Error satisfies the builtin error interface
+func (e CounterValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCounter.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CounterValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CounterValidationError{}
+
+// Validate checks the field values on Queue with the rules defined in the
+// proto definition for this message. If any rules are violated, an error is returned.
+func (m *Queue) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	// no validation rules for Prefix
+
+	// no validation rules for Concurrency
+
+	if v, ok := interface{}(m.GetVisibilityTimeout()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return QueueValidationError{
+				field:  "VisibilityTimeout",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if v, ok := interface{}(m.GetDrainTimeout()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return QueueValidationError{
+				field:  "DrainTimeout",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for MaxRetries
+
+	return nil
+}
+
+// QueueValidationError is the validation error returned by Queue.Validate if
+// the designated constraints aren't met.
+type QueueValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e QueueValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e QueueValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e QueueValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e QueueValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e QueueValidationError) ErrorName() string { return "QueueValidationError" }
+
+// Error satisfies the builtin error interface
+func (e QueueValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sQueue.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = QueueValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = QueueValidationError{}
+
+// Validate checks the field values on Event with the rules defined in the
+// proto definition for this message. If any rules are violated, an error is returned.
+func (m *Event) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	// no validation rules for Prefix
+
+	// no validation rules for MaxLen
+
+	if v, ok := interface{}(m.GetPollInterval()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return EventValidationError{
+				field:  "PollInterval",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for BatchSize
+
+	if v, ok := interface{}(m.GetRetention()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return EventValidationError{
+				field:  "Retention",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// EventValidationError is the validation error returned by Event.Validate if
+// the designated constraints aren't met.
+type EventValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e EventValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e EventValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e EventValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e EventValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e EventValidationError) ErrorName() string { return "EventValidationError" }
+
+// Error satisfies the builtin error interface
+func (e EventValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sEvent.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = EventValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = EventValidationError{}
+
+// Validate checks the field values on Data with the rules defined in the proto
+// definition for this message. If any rules are violated, an error is returned.
+func (m *Data) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	if v, ok := interface{}(m.GetMysql()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return DataValidationError{
+				field:  "Mysql",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if v, ok := interface{}(m.GetRedis()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return DataValidationError{
+				field:  "Redis",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if v, ok := interface{}(m.GetMongodb()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return DataValidationError{
+				field:  "Mongodb",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if v, ok := interface{}(m.GetCache()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return DataValidationError{
+				field:  "Cache",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if v, ok := interface{}(m.GetCounter()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return DataValidationError{
+				field:  "Counter",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if v, ok := interface{}(m.GetQueue()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return DataValidationError{
+				field:  "Queue",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if v, ok := interface{}(m.GetEvent()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return DataValidationError{
+				field:  "Event",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// DataValidationError is the validation error returned by Data.Validate if the
+// designated constraints aren't met.
+type DataValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DataValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DataValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DataValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DataValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DataValidationError) ErrorName() string { return "DataValidationError" }
+
+// Error satisfies the builtin error interface
+func (e DataValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sData.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DataValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DataValidationError{}
+
+// Validate checks the field values on Log_Rotate with the rules defined in the
+// proto definition for this message. If any rules are violated, an error is returned.
+func (m *Log_Rotate) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	// no validation rules for Filename
+
+	// no validation rules for MaxSize
+
+	// no validation rules for MaxBackups
+
+	// no validation rules for MaxAge
+
+	// no validation rules for Compress
+
+	return nil
+}
+
+// Log_RotateValidationError is the validation error returned by
+// Log_Rotate.Validate if the designated constraints aren't met.
+type Log_RotateValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e Log_RotateValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e Log_RotateValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e Log_RotateValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e Log_RotateValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e Log_RotateValidationError) ErrorName() string { return "Log_RotateValidationError" }
+
+// Error satisfies the builtin error interface
+func (e Log_RotateValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sLog_Rotate.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = Log_RotateValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = Log_RotateValidationError{}
+
+// Validate checks the field values on Log_Sampling with the rules defined in
+// the proto definition for this message. If any rules are violated, an error
+// is returned.
+func (m *Log_Sampling) Validate() error {
+	if m == nil {
+		return nil
+	}
+
+	if v, ok := interface{}(m.GetTick()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return Log_SamplingValidationError{
+				field:  "Tick",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Initial
+
+	// no validation rules for Thereafter
+
+	return nil
+}
+
+// Log_SamplingValidationError is the validation error returned by
+// Log_Sampling.Validate if the designated constraints aren't met.
+type Log_SamplingValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e Log_SamplingValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e Log_SamplingValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e Log_SamplingValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e Log_SamplingValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e Log_SamplingValidationError) ErrorName() string { return "Log_SamplingValidationError" }
+
+// Error satisfies the builtin error interface
+func (e Log_SamplingValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sLog_Sampling.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = Log_SamplingValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = Log_SamplingValidationError{}
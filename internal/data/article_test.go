@@ -4,11 +4,8 @@ import (
 	"context"
 	"github.com/go-kratos/kratos-layout/internal/conf"
 	"github.com/go-kratos/kratos-layout/pkg/config"
-	"github.com/go-kratos/kratos-layout/pkg/decodehook"
 	"os"
 	"testing"
-
-	"github.com/spf13/viper"
 )
 
 var testRepo *greeterRepo
@@ -23,7 +20,7 @@ func TestMain(m *testing.M) {
 		panic(err)
 	}
 	data := new(conf.Data)
-	if err := viper.UnmarshalKey("data", data, viper.DecodeHook(decodehook.StringToTimeDurationHookFunc())); err != nil {
+	if err := config.UnmarshalKey("data", data); err != nil {
 		panic(err)
 	}
 	var clear func()
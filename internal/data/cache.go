@@ -0,0 +1,75 @@
+package data
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos-layout/internal/conf"
+	"github.com/go-kratos/kratos-layout/pkg/cache"
+	"github.com/go-kratos/kratos-layout/pkg/nosql"
+	"github.com/go-kratos/kratos-layout/pkg/otelmetric"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+)
+
+// NewCache repo的cache-aside缓存，使用gob编码保留tools.Time的精度
+func NewCache(conf *conf.Data, rdb *redis.Client, l log.Logger) (*cache.Cache, func(), error) {
+	opts := []cache.Option{
+		cache.WithCodec(cache.GobCodec{}),
+		cache.WithLogger(l),
+		cache.WithRequestCounter(otelmetric.NewCounter(otelmetric.Meter(), "cache_requests_total", "缓存命中统计", "result")),
+	}
+	if c := conf.Cache; c != nil {
+		if c.Prefix != "" {
+			opts = append(opts, cache.WithPrefix(c.Prefix))
+		}
+		if c.Ttl != nil {
+			opts = append(opts, cache.WithTTL(c.Ttl.AsDuration()))
+		}
+		if c.NegativeTtl != nil {
+			opts = append(opts, cache.WithNegativeTTL(c.NegativeTtl.AsDuration()))
+		}
+		if c.Jitter > 0 {
+			opts = append(opts, cache.WithJitter(c.Jitter))
+		}
+		if c.LocalSize > 0 && c.LocalTtl != nil {
+			opts = append(opts, cache.WithLocal(int(c.LocalSize), c.LocalTtl.AsDuration()))
+		}
+	}
+	ca := cache.New(rdb, opts...)
+	cleanup := func() {
+		l.Log(log.LevelInfo, "closing the cache resources")
+		_ = ca.Close()
+	}
+	return ca, cleanup, nil
+}
+
+// invalidate 写数据后删除缓存，在mongo事务中时等到提交成功后再删除，避免其他请求读到未提交的数据后重新写入缓存
+func (d *Data) invalidate(ctx context.Context, keys ...string) {
+	d.afterWrite(ctx, func(ctx context.Context) {
+		if err := d.cache.Delete(ctx, keys...); err != nil {
+			d.helper.Errorf("删除缓存%v失败: %+v", keys, err)
+		}
+	})
+}
+
+// invalidateTags 同invalidate，删除标签下的所有缓存
+func (d *Data) invalidateTags(ctx context.Context, tags ...string) {
+	d.afterWrite(ctx, func(ctx context.Context) {
+		if err := d.cache.InvalidateTags(ctx, tags...); err != nil {
+			d.helper.Errorf("删除缓存标签%v失败: %+v", tags, err)
+		}
+	})
+}
+
+// afterWrite 在mongo事务中时提交成功后执行fn，否则直接执行
+func (d *Data) afterWrite(ctx context.Context, fn func(ctx context.Context)) {
+	registered := nosql.OnTransactionEnd(ctx, func(ctx context.Context, committed bool) error {
+		if committed {
+			fn(ctx)
+		}
+		return nil
+	})
+	if !registered {
+		fn(ctx)
+	}
+}
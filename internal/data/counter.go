@@ -0,0 +1,251 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/internal/biz"
+	"github.com/go-kratos/kratos-layout/internal/conf"
+	"github.com/go-kratos/kratos-layout/pkg/counter"
+	"github.com/go-kratos/kratos-layout/pkg/lock"
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Counter 持久化的计数
+type Counter struct {
+	Name      string `gorm:"primaryKey;size:64"`
+	TargetID  string `gorm:"primaryKey;size:64"`
+	Value     int64
+	UpdatedAt time.Time
+}
+
+// counterStore 计数写入mysql
+type counterStore struct {
+	data *Data
+}
+
+func (s counterStore) Apply(ctx context.Context, name string, deltas map[string]int64) error {
+	rows := make([]Counter, 0, len(deltas))
+	for id, delta := range deltas {
+		rows = append(rows, Counter{Name: name, TargetID: id, Value: delta})
+	}
+	return s.data.DB(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"value":      gorm.Expr("`value` + VALUES(`value`)"),
+			"updated_at": gorm.Expr("VALUES(`updated_at`)"),
+		}),
+	}).Create(&rows).Error
+}
+
+func (s counterStore) Load(ctx context.Context, name string, ids []string) (map[string]int64, error) {
+	var rows []Counter
+	if err := s.data.DB(ctx).Where("name = ? AND target_id IN ?", name, ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.TargetID] = row.Value
+	}
+	return counts, nil
+}
+
+type counterRepo struct {
+	data     *Data
+	store    counterStore
+	counters map[string]*counter.Counter
+	log      *log.Helper
+}
+
+// NewCounterRepo 计数的定义，新的计数在这里注册
+// 由wire提供一个实例，CounterFlusher、任务队列和定时任务共用
+func NewCounterRepo(c *conf.Data, data *Data, logger log.Logger) *counterRepo {
+	shards := 1
+	if c.Counter != nil && c.Counter.Shards > 0 {
+		shards = int(c.Counter.Shards)
+	}
+	store := counterStore{data: data}
+	r := &counterRepo{
+		data:     data,
+		store:    store,
+		counters: make(map[string]*counter.Counter),
+		log:      log.NewHelper(logger),
+	}
+	for _, ct := range []*counter.Counter{
+		counter.New(data.rdb, biz.CounterArticleLike,
+			counter.WithShards(shards),
+			counter.WithDedup(counter.DedupSet),
+			counter.WithLeaderboard(0, 0),
+			counter.WithStore(store),
+			counter.WithLogger(logger)),
+	} {
+		r.counters[ct.Name()] = ct
+	}
+	return r
+}
+
+func (r *counterRepo) counter(name string) (*counter.Counter, error) {
+	c, ok := r.counters[name]
+	if !ok {
+		return nil, fmt.Errorf("计数[%s]没有注册", name)
+	}
+	return c, nil
+}
+
+func (r *counterRepo) Add(ctx context.Context, name, id, member string, delta int64) (bool, error) {
+	c, err := r.counter(name)
+	if err != nil {
+		return false, err
+	}
+	return c.Add(ctx, id, member, delta)
+}
+
+func (r *counterRepo) IsMember(ctx context.Context, name, id, member string) (bool, error) {
+	c, err := r.counter(name)
+	if err != nil {
+		return false, err
+	}
+	return c.IsMember(ctx, id, member)
+}
+
+func (r *counterRepo) Get(ctx context.Context, name string, ids ...string) (map[string]int64, error) {
+	c, err := r.counter(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.Get(ctx, ids...)
+}
+
+func (r *counterRepo) Top(ctx context.Context, name string, n int) ([]biz.Rank, error) {
+	c, err := r.counter(name)
+	if err != nil {
+		return nil, err
+	}
+	ranks, err := c.Top(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]biz.Rank, 0, len(ranks))
+	for _, rank := range ranks {
+		result = append(result, biz.Rank{ID: rank.ID, Score: rank.Score})
+	}
+	return result, nil
+}
+
+// legacyLikePrefix 旧版本IncArticleLike使用的key: like:{id}
+const legacyLikePrefix = "like:"
+
+// legacyLikeMigrated 已经迁移的旧点赞数，和计数保存在同一张表中
+const legacyLikeMigrated = "migrated:" + biz.CounterArticleLike
+
+/*migrateLegacyLikes 把旧版本like:{id}中的点赞数加到点赞计数的Store，由Migrator执行
+增量和迁移记录在同一个mysql事务中写入，之后重新计算基数并删除旧的key，重复执行时跳过已经迁移的id
+参数:
+*	ctx	context.Context
+返回值:
+*	error	error
+*/
+func (r *counterRepo) migrateLegacyLikes(ctx context.Context) error {
+	ct, err := r.counter(biz.CounterArticleLike)
+	if err != nil {
+		return err
+	}
+	var cursor uint64
+	for {
+		keys, next, err := r.data.rdb.Scan(ctx, cursor, legacyLikePrefix+"*", 500).Result()
+		if err != nil {
+			return fmt.Errorf("查询旧的点赞数: %w", err)
+		}
+		if err = r.migrateLikes(ctx, ct, keys); err != nil {
+			return err
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+func (r *counterRepo) migrateLikes(ctx context.Context, ct *counter.Counter, keys []string) error {
+	legacy := make([]string, 0, len(keys))
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		id := strings.TrimPrefix(key, legacyLikePrefix)
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil { // 不是旧的点赞数
+			continue
+		}
+		legacy = append(legacy, key)
+		ids = append(ids, id)
+	}
+	if len(legacy) == 0 {
+		return nil
+	}
+	values, err := r.data.rdb.MGet(ctx, legacy...).Result()
+	if err != nil {
+		return fmt.Errorf("查询旧的点赞数: %w", err)
+	}
+	deltas := make(map[string]int64, len(ids))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil && n != 0 {
+				deltas[ids[i]] = n
+			}
+		}
+	}
+	err = r.data.WithGormTransaction(ctx, func(ctx context.Context) error {
+		var migrated []Counter
+		if err := r.data.DB(ctx).Where("name = ? AND target_id IN ?", legacyLikeMigrated, ids).Find(&migrated).Error; err != nil {
+			return err
+		}
+		for _, row := range migrated {
+			delete(deltas, row.TargetID)
+		}
+		if len(deltas) == 0 {
+			return nil
+		}
+		if err := r.store.Apply(ctx, biz.CounterArticleLike, deltas); err != nil {
+			return err
+		}
+		rows := make([]Counter, 0, len(deltas))
+		for id, delta := range deltas {
+			rows = append(rows, Counter{Name: legacyLikeMigrated, TargetID: id, Value: delta})
+		}
+		return r.data.DB(ctx).Create(&rows).Error
+	})
+	if err != nil {
+		return fmt.Errorf("迁移旧的点赞数: %w", err)
+	}
+	// 已经恢复过基数的id按新的Store重新计算
+	if err = ct.Reconcile(ctx, ids...); err != nil {
+		return err
+	}
+	if err = r.data.rdb.Del(ctx, legacy...).Err(); err != nil {
+		return fmt.Errorf("删除旧的点赞数: %w", err)
+	}
+	r.log.Infof("迁移了%d个旧的点赞数", len(deltas))
+	return nil
+}
+
+// CounterFlusher 定期把计数的增量写入mysql，多个实例中只有一个运行
+type CounterFlusher struct {
+	*lock.Election
+}
+
+// NewCounterFlusher .
+func NewCounterFlusher(c *conf.Data, data *Data, counters *counterRepo) *CounterFlusher {
+	interval := 10 * time.Second
+	if c.Counter != nil && c.Counter.FlushInterval != nil {
+		interval = c.Counter.FlushInterval.AsDuration()
+	}
+	all := make([]*counter.Counter, 0, len(counters.counters))
+	for _, ct := range counters.counters {
+		all = append(all, ct)
+	}
+	return &CounterFlusher{Election: lock.NewElection(data.locker, "counter:flush", func(ctx context.Context) error {
+		return counter.Run(ctx, interval, all...)
+	})}
+}
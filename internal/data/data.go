@@ -1,20 +1,30 @@
 package data
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 
+	"github.com/go-kratos/kratos-layout/internal/biz"
 	"github.com/go-kratos/kratos-layout/internal/conf"
+	"github.com/go-kratos/kratos-layout/pkg/cache"
+	"github.com/go-kratos/kratos-layout/pkg/lock"
+	"github.com/go-kratos/kratos-layout/pkg/queue"
+	"github.com/go-kratos/kratos-layout/pkg/tools"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-redis/redis/v8"
 	"github.com/google/wire"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 // ProviderSet is data providers.
-var ProviderSet = wire.NewSet(NewData, NewGreeterRepo)
+var ProviderSet = wire.NewSet(NewData, NewGreeterRepo, wire.Bind(new(biz.GreeterRepo), new(*greeterRepo)), NewMigrator, NewTransaction, NewCounterRepo, wire.Bind(new(biz.CounterRepo), new(*counterRepo)), NewCounterFlusher,
+	NewQueue, wire.Bind(new(biz.JobQueue), new(*queue.Queue)), NewScheduler,
+	NewEventRepo, wire.Bind(new(biz.EventPublisher), new(*eventRepo)), NewEventRelay, NewGreeterCacheWatcher)
 
 // Data .
 type Data struct {
@@ -22,6 +32,8 @@ type Data struct {
 	mysql   *gorm.DB
 	rdb     *redis.Client
 	mongodb *mongo.Database
+	cache   *cache.Cache
+	locker  *lock.Locker
 }
 
 func (d *Data) Endpoint() (string, error) {
@@ -32,6 +44,24 @@ func (d *Data) Start() error {
 	return nil
 }
 
+// Ping 检查mysql、redis、mongodb是否可用，用于健康检查
+func (d *Data) Ping(ctx context.Context) error {
+	db, err := d.mysql.DB()
+	if err != nil {
+		return err
+	}
+	if err = db.PingContext(ctx); err != nil {
+		return fmt.Errorf("mysql: %w", err)
+	}
+	if err = d.rdb.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis: %w", err)
+	}
+	if err = d.mongodb.Client().Ping(ctx, readpref.Primary()); err != nil {
+		return fmt.Errorf("mongodb: %w", err)
+	}
+	return nil
+}
+
 func (d *Data) Stop() error {
 	db, err := d.mysql.DB()
 	if err != nil {
@@ -45,14 +75,14 @@ func NewMysql(conf *conf.Data, l log.Logger) (db *gorm.DB, cleanup func(), err e
 	cleanup = func() {
 		l.Log(log.LevelInfo, "closing the mysql resources")
 	}
+	// DATETIME和DATE按业务时区读写，和tools.Time的格式化一致，不依赖进程的TZ
 	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=%t&loc=%s",
 		conf.Mysql.Username,
 		conf.Mysql.Password,
 		conf.Mysql.Addr,
 		conf.Mysql.DbName,
 		true,
-		//"Asia/Shanghai"),
-		"Local")
+		url.QueryEscape(tools.Location().String()))
 	db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger:                                   logger.Default.LogMode(logger.Info),
 		DisableForeignKeyConstraintWhenMigrating: true,
@@ -74,9 +104,6 @@ func NewMysql(conf *conf.Data, l log.Logger) (db *gorm.DB, cleanup func(), err e
 	if conf.Mysql.ConnMaxLifeTime != nil {
 		sqlDB.SetConnMaxLifetime(conf.Mysql.ConnMaxLifeTime.AsDuration())
 	}
-	if err = db.AutoMigrate(); err != nil {
-		return
-	}
 	// 创建视图需要exec
 	//if db.Find(&[]SysMenu{}).RowsAffected > 0 {
 	//	log.Info("\n[Mysql] --> authority_menu 视图已存在!")
@@ -0,0 +1,114 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/internal/biz"
+	"github.com/go-kratos/kratos-layout/internal/conf"
+	"github.com/go-kratos/kratos-layout/pkg/lock"
+	"github.com/go-kratos/kratos-layout/pkg/nosql"
+	"github.com/go-kratos/kratos-layout/pkg/outbox"
+	"github.com/go-kratos/kratos/v2/log"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/sync/errgroup"
+)
+
+const dbOutboxKey = "outbox"
+
+// errNoTransaction 在事务外发布事件，事件和状态变更不能保证一致
+var errNoTransaction = errors.New("领域事件必须在事务中发布")
+
+// eventRepo 领域事件写入outbox，mongo事务中写入mongo的outbox集合，只有mysql事务时写入mysql的outbox表
+type eventRepo struct {
+	mongo       *outbox.MongoStore
+	gorm        *outbox.GormStore
+	retention   time.Duration
+	collections map[string]*mongo.Collection
+}
+
+// NewEventRepo .
+func NewEventRepo(c *conf.Data, data *Data) *eventRepo {
+	r := &eventRepo{
+		gorm:        outbox.NewGormStore(data.DB),
+		retention:   7 * 24 * time.Hour,
+		collections: make(map[string]*mongo.Collection, 1),
+	}
+	if c.Event != nil && c.Event.Retention != nil {
+		r.retention = c.Event.Retention.AsDuration()
+	}
+	ComponentBind(r, data.mongodb)
+	r.mongo = outbox.NewMongoStore(r.collections[dbOutboxKey])
+	return r
+}
+
+func (r *eventRepo) Keys() map[string]*nosql.Spec {
+	return map[string]*nosql.Spec{dbOutboxKey: nil}
+}
+
+func (r *eventRepo) Init() error {
+	return nil
+}
+
+func (r *eventRepo) Collections() map[string]*mongo.Collection {
+	return r.collections
+}
+
+func (r *eventRepo) Indexes() map[string][]nosql.Index {
+	return map[string][]nosql.Index{dbOutboxKey: r.mongo.Indexes(r.retention)}
+}
+
+func (r *eventRepo) Publish(ctx context.Context, events ...biz.Event) error {
+	msgs := make([]*outbox.Message, 0, len(events))
+	for _, e := range events {
+		m, err := outbox.NewMessage(ctx, e.Topic, e.Key, e.Payload)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, m)
+	}
+	if nosql.InTransaction(ctx) {
+		return r.mongo.Save(ctx, msgs...)
+	}
+	if inGormTx(ctx) {
+		return r.gorm.Save(ctx, msgs...)
+	}
+	return errNoTransaction
+}
+
+// EventRelay 把outbox中的事件投递到redis stream，多个实例中只有一个运行
+type EventRelay struct {
+	*lock.Election
+}
+
+// NewEventRelay 更换broker时在这里替换outbox.NewRedisBroker，例如outbox.NewKafkaBroker
+func NewEventRelay(c *conf.Data, data *Data, events *eventRepo, logger log.Logger) *EventRelay {
+	prefix, maxLen := "event:", int64(0)
+	opts := []outbox.Option{outbox.WithLogger(logger)}
+	if ec := c.Event; ec != nil {
+		if ec.Prefix != "" {
+			prefix = ec.Prefix
+		}
+		maxLen = ec.MaxLen
+		if ec.PollInterval != nil {
+			opts = append(opts, outbox.WithPollInterval(ec.PollInterval.AsDuration()))
+		}
+		if ec.BatchSize > 0 {
+			opts = append(opts, outbox.WithBatchSize(int(ec.BatchSize)))
+		}
+	}
+	broker := outbox.NewRedisBroker(data.rdb, prefix, maxLen, logger)
+	relays := []*outbox.Relay{
+		outbox.NewRelay(events.mongo, broker, opts...),
+		outbox.NewRelay(events.gorm, broker, opts...),
+	}
+	return &EventRelay{Election: lock.NewElection(data.locker, "event:relay", func(ctx context.Context) error {
+		g, ctx := errgroup.WithContext(ctx)
+		for _, r := range relays {
+			r := r
+			g.Go(func() error { return r.Run(ctx) })
+		}
+		return g.Wait()
+	})}
+}
@@ -2,11 +2,20 @@ package data
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/go-kratos/kratos-layout/pkg/cache"
 	"github.com/go-kratos/kratos-layout/pkg/nosql"
+	"github.com/go-kratos/kratos-layout/pkg/objectid"
 
 	"github.com/go-kratos/kratos-layout/internal/biz"
 	"github.com/go-kratos/kratos/v2/log"
@@ -36,23 +45,24 @@ func (r *greeterRepo) Keys() map[string]*nosql.Spec {
 	return specs
 }
 
-// Init 索引还在想
+// Init 索引由Indexes声明，Migrator统一维护
 func (r *greeterRepo) Init() error {
-	//return r.index()
 	return nil
 }
 
-func (r *greeterRepo) index() error {
-	return nosql.EnsureIndex(r.collections[biz.DBGreeterKey], []nosql.Index{
-		{
-			Name: "主键",
-			Data: mongo.IndexModel{
-				Keys:    bson.M{"_id": 1},
-				Options: options.Index(),
+func (r *greeterRepo) Indexes() map[string][]nosql.Index {
+	return map[string][]nosql.Index{
+		biz.DBGreeterKey: {
+			{
+				Name: "hello",
+				Data: mongo.IndexModel{
+					Keys:    bson.D{{Key: "hello", Value: 1}},
+					Options: options.Index(),
+				},
+				Version: 1,
 			},
-			Version: 1,
 		},
-	})
+	}
 }
 
 func (r *greeterRepo) Collections() map[string]*mongo.Collection {
@@ -60,7 +70,7 @@ func (r *greeterRepo) Collections() map[string]*mongo.Collection {
 }
 
 // NewGreeterRepo .
-func NewGreeterRepo(data *Data, logger log.Logger) biz.GreeterRepo {
+func NewGreeterRepo(data *Data, logger log.Logger) *greeterRepo {
 	return newGreeterRepo(data, logger)
 }
 
@@ -70,16 +80,242 @@ func newGreeterRepo(data *Data, logger log.Logger) *greeterRepo {
 		log:         log.NewHelper(logger),
 		collections: make(map[string]*mongo.Collection, 3),
 	}
-	if err := ComponentStart(g, data.mongodb); err != nil {
-		g.log.Errorf("创建GreeterRepo失败: %+v", err)
-	}
+	ComponentBind(g, data.mongodb)
 	return g
 }
 
+// greeterQuerySpec ListGreeter支持的查询条件
+var greeterQuerySpec = nosql.QuerySpec{
+	"hello":      {Field: "hello", Op: "$regex", Convert: nosql.RegexParse},
+	"createTime": nosql.DateRangeSpec("createTime"),
+}
+
+func (r *greeterRepo) collection() *mongo.Collection {
+	return r.collections[biz.DBGreeterKey]
+}
+
 func (r *greeterRepo) CreateGreeter(ctx context.Context, g *biz.Greeter) error {
+	if _, err := r.collection().InsertOne(ctx, g); err != nil {
+		return fmt.Errorf("创建greeter: %w", err)
+	}
+	r.data.invalidateTags(ctx, greeterListTag)
 	return nil
 }
 
-func (r *greeterRepo) UpdateGreeter(ctx context.Context, g *biz.Greeter) error {
+// greeterListTag ListGreeter缓存的标签，任何写入都会改变列表，写入后按标签全部删除
+const greeterListTag = "greeter:list"
+
+func greeterCacheKey(id string) string {
+	return "greeter:" + id
+}
+
+// greeterListCacheKey 按查询条件生成列表缓存的key，map按key排序编码，相同条件的key相同
+func greeterListCacheKey(req nosql.TableRequest) (string, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum(b)
+	return greeterListTag + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// greeterPage ListGreeter的缓存内容
+type greeterPage struct {
+	Items []*biz.Greeter
+	Count int64
+}
+
+// GetGreeter 查询全部字段且不在事务中时使用缓存
+func (r *greeterRepo) GetGreeter(ctx context.Context, id string, fields ...string) (*biz.Greeter, error) {
+	_id, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, biz.ErrGreeterNotFound
+	}
+	if len(fields) > 0 || nosql.InTransaction(ctx) {
+		return r.findGreeter(ctx, _id, fields)
+	}
+	g := new(biz.Greeter)
+	err = r.data.cache.Fetch(ctx, greeterCacheKey(id), g, func(ctx context.Context) (interface{}, error) {
+		found, err := r.findGreeter(ctx, _id, nil)
+		if errors.Is(err, biz.ErrGreeterNotFound) {
+			return nil, cache.ErrNotFound
+		}
+		return found, err
+	})
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, biz.ErrGreeterNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (r *greeterRepo) findGreeter(ctx context.Context, _id primitive.ObjectID, fields []string) (*biz.Greeter, error) {
+	opts := options.FindOne()
+	if len(fields) > 0 {
+		opts.SetProjection(nosql.Projection(fields))
+	}
+	g := new(biz.Greeter)
+	if err := r.collection().FindOne(ctx, bson.M{"_id": _id}, opts).Decode(g); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, biz.ErrGreeterNotFound
+		}
+		return nil, fmt.Errorf("查询greeter: %w", err)
+	}
+	return g, nil
+}
+
+// ListGreeter 不在事务中时使用缓存，缓存带有greeterListTag，写入时删除
+func (r *greeterRepo) ListGreeter(ctx context.Context, req nosql.TableRequest) ([]*biz.Greeter, int64, error) {
+	key, err := greeterListCacheKey(req)
+	if err != nil || nosql.InTransaction(ctx) {
+		return r.listGreeter(ctx, req)
+	}
+	page := new(greeterPage)
+	err = r.data.cache.Fetch(ctx, key, page, func(ctx context.Context) (interface{}, error) {
+		items, count, err := r.listGreeter(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return &greeterPage{Items: items, Count: count}, nil
+	}, cache.Tags(greeterListTag))
+	if err != nil {
+		return nil, 0, err
+	}
+	return page.Items, page.Count, nil
+}
+
+func (r *greeterRepo) listGreeter(ctx context.Context, req nosql.TableRequest) ([]*biz.Greeter, int64, error) {
+	result, count, err := nosql.TableQuery(ctx, r.collection(), req, greeterQuerySpec, biz.Greeter{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询greeter: %w", err)
+	}
+	greeters := make([]*biz.Greeter, 0, len(result))
+	for _, item := range result {
+		g := item.(biz.Greeter)
+		greeters = append(greeters, &g)
+	}
+	return greeters, count, nil
+}
+
+func (r *greeterRepo) UpdateGreeter(ctx context.Context, g *biz.Greeter, fields []string) error {
+	_id, err := g.ID.Primitive()
+	if err != nil {
+		return biz.ErrGreeterNotFound
+	}
+	update, err := nosql.UpdateDocument(g, fields)
+	if err != nil {
+		return fmt.Errorf("构建更新文档: %w", err)
+	}
+	result, err := r.collection().UpdateOne(ctx, bson.M{"_id": _id}, update)
+	if err != nil {
+		return fmt.Errorf("更新greeter: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return biz.ErrGreeterNotFound
+	}
+	r.data.invalidate(ctx, greeterCacheKey(string(g.ID)))
+	r.data.invalidateTags(ctx, greeterListTag)
 	return nil
 }
+
+func (r *greeterRepo) DeleteGreeter(ctx context.Context, id string) error {
+	_id, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return biz.ErrGreeterNotFound
+	}
+	result, err := r.collection().DeleteOne(ctx, bson.M{"_id": _id})
+	if err != nil {
+		return fmt.Errorf("删除greeter: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return biz.ErrGreeterNotFound
+	}
+	r.data.invalidate(ctx, greeterCacheKey(id))
+	r.data.invalidateTags(ctx, greeterListTag)
+	return nil
+}
+
+// greeterOperations 变更类型对应的change stream操作
+var greeterOperations = map[biz.GreeterEventType][]string{
+	biz.GreeterCreated: {nosql.OperationInsert},
+	biz.GreeterUpdated: {nosql.OperationUpdate, nosql.OperationReplace},
+	biz.GreeterDeleted: {nosql.OperationDelete},
+}
+
+// WatchGreeter 基于greeter表的change stream，需要mongodb副本集
+func (r *greeterRepo) WatchGreeter(ctx context.Context, w biz.GreeterWatch) (biz.GreeterWatcher, error) {
+	types := w.Types
+	if len(types) == 0 {
+		types = []biz.GreeterEventType{biz.GreeterCreated, biz.GreeterUpdated, biz.GreeterDeleted}
+	}
+	operations := make(bson.A, 0, 4)
+	for _, t := range types {
+		for _, op := range greeterOperations[t] {
+			operations = append(operations, op)
+		}
+	}
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": operations}}}}}
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if w.ResumeToken != "" {
+		token, err := nosql.DecodeResumeToken(w.ResumeToken)
+		if err != nil {
+			return nil, biz.ErrInvalidResumeToken
+		}
+		opts.SetResumeAfter(token)
+	}
+	cs, err := r.collection().Watch(ctx, pipeline, opts)
+	if err != nil {
+		return nil, fmt.Errorf("订阅greeter: %w", err)
+	}
+	return &greeterWatcher{cs: cs}, nil
+}
+
+type greeterWatcher struct {
+	cs *mongo.ChangeStream
+}
+
+func newGreeter() interface{} {
+	return &biz.Greeter{}
+}
+
+func (w *greeterWatcher) Next(ctx context.Context) (*biz.GreeterEvent, error) {
+	for w.cs.Next(ctx) {
+		change, err := nosql.DecodeChange(w.cs.Current, newGreeter)
+		if err != nil {
+			return nil, fmt.Errorf("解码greeter变更: %w", err)
+		}
+		event := &biz.GreeterEvent{ResumeToken: nosql.EncodeResumeToken(change.ResumeToken)}
+		switch change.OperationType {
+		case nosql.OperationInsert:
+			event.Type = biz.GreeterCreated
+		case nosql.OperationUpdate, nosql.OperationReplace:
+			event.Type = biz.GreeterUpdated
+		case nosql.OperationDelete:
+			event.Type = biz.GreeterDeleted
+		default: // invalidate等事件之后变更流会关闭
+			return nil, io.EOF
+		}
+		if g, ok := change.FullDocument.(*biz.Greeter); ok {
+			event.Greeter = g
+		} else { // 删除或者更新后已经被删除
+			event.Greeter = &biz.Greeter{}
+			if id, ok := change.DocumentID().ObjectIDOK(); ok {
+				event.Greeter.ID = objectid.FromPrimitive(id)
+			}
+		}
+		return event, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := w.cs.Err(); err != nil {
+		return nil, fmt.Errorf("订阅greeter: %w", err)
+	}
+	return nil, io.EOF
+}
+
+func (w *greeterWatcher) Close(ctx context.Context) error {
+	return w.cs.Close(ctx)
+}
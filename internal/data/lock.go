@@ -0,0 +1,31 @@
+package data
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos-layout/pkg/lock"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+)
+
+// NewLocker 分布式锁，用于多个实例中只能有一个执行的任务
+func NewLocker(rdb *redis.Client, l log.Logger) *lock.Locker {
+	return lock.NewLocker(rdb, lock.WithLogger(l))
+}
+
+// Locker 分布式锁，cmd/server用来创建lock.Election
+func (d *Data) Locker() *lock.Locker {
+	return d.locker
+}
+
+// migrateLock 升级数据和索引的锁
+const migrateLock = "migrate"
+
+// Exclusive 持有升级锁执行fn，多个实例同时启动时依次升级，后获得锁的实例检查到已经是最新版本
+// fn中再调用Migrate、ApplyIndex时直接执行，不重复加锁
+func (m *Migrator) Exclusive(ctx context.Context, fn func(ctx context.Context) error) error {
+	if lk, ok := lock.FromContext(ctx); ok && lk.Name() == migrateLock {
+		return fn(ctx)
+	}
+	return m.data.locker.WithLock(ctx, migrateLock, fn)
+}
@@ -0,0 +1,97 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kratos/kratos-layout/pkg/nosql"
+	"github.com/go-kratos/kratos-layout/pkg/outbox"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// mysqlModels 需要AutoMigrate的mysql表
+var mysqlModels = []interface{}{
+	&Counter{},
+	&outbox.OutboxMessage{},
+}
+
+// Migrator 负责mysql表结构、mongo数据版本和索引的升级
+type Migrator struct {
+	data       *Data
+	counters   *counterRepo
+	components []nosql.DBComponent
+	log        *log.Helper
+}
+
+// NewMigrator .
+func NewMigrator(data *Data, greeter *greeterRepo, events *eventRepo, counters *counterRepo, logger log.Logger) *Migrator {
+	return &Migrator{
+		data:       data,
+		counters:   counters,
+		components: []nosql.DBComponent{greeter, events},
+		log:        log.NewHelper(logger),
+	}
+}
+
+// Migrate 升级mysql表结构和低版本的mongo数据，持有升级锁执行
+func (m *Migrator) Migrate(ctx context.Context) error {
+	return m.Exclusive(ctx, m.migrate)
+}
+
+func (m *Migrator) migrate(ctx context.Context) error {
+	if err := m.data.DB(ctx).AutoMigrate(mysqlModels...); err != nil {
+		return fmt.Errorf("升级mysql表结构失败: %w", err)
+	}
+	if err := m.counters.migrateLegacyLikes(ctx); err != nil {
+		return err
+	}
+	for _, component := range m.components {
+		if err := component.Init(); err != nil {
+			return fmt.Errorf("初始化component失败: %w", err)
+		}
+		for key, spec := range component.Keys() {
+			if err := spec.Update(key); err != nil {
+				return fmt.Errorf("检查并升级%s collection数据失败: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// PlanIndex 列出所有模块需要的索引变更
+func (m *Migrator) PlanIndex(ctx context.Context) ([]nosql.IndexChange, error) {
+	var plan []nosql.IndexChange
+	for _, component := range m.components {
+		indexComponent, ok := component.(nosql.IndexComponent)
+		if !ok {
+			continue
+		}
+		for key, indexes := range indexComponent.Indexes() {
+			changes, err := nosql.PlanIndex(ctx, component.Collections()[key], indexes)
+			if err != nil {
+				return nil, fmt.Errorf("检查%s collection索引失败: %w", key, err)
+			}
+			plan = append(plan, changes...)
+		}
+	}
+	return plan, nil
+}
+
+// ApplyIndex 创建或升级所有模块的索引，持有升级锁执行
+func (m *Migrator) ApplyIndex(ctx context.Context) error {
+	return m.Exclusive(ctx, m.applyIndex)
+}
+
+func (m *Migrator) applyIndex(ctx context.Context) error {
+	plan, err := m.PlanIndex(ctx)
+	if err != nil {
+		return err
+	}
+	for _, change := range plan {
+		if err = nosql.ApplyIndex(ctx, m.data.mongodb.Collection(change.Collection), []nosql.IndexChange{change}); err != nil {
+			return fmt.Errorf("升级%s collection索引失败: %w", change.Collection, err)
+		}
+		m.log.Infof("索引: %s", change)
+	}
+	return nil
+}
@@ -2,13 +2,13 @@ package data
 
 import (
 	"context"
-	"fmt"
+	"strings"
 
+	"github.com/go-kratos/kratos-layout/pkg/mongocodec"
 	"github.com/go-kratos/kratos-layout/pkg/nosql"
 
 	"github.com/go-kratos/kratos-layout/internal/conf"
 	"github.com/go-kratos/kratos/v2/log"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -21,9 +21,9 @@ func NewMongoDB(conf *conf.Data, l log.Logger) (db *mongo.Database, cf func(), e
 		Password:      conf.Mongodb.Password,
 		AuthSource:    conf.Mongodb.AuthSource,
 	}
-	//client, err = mongo.Connect(context.Background(), options.Client().ApplyURI(fmt.Sprintf("mongodb://%s:%s", cfg.Host, cfg.Port)).SetAuth(auth).SetRegistry(bson.NewRegistryBuilder().RegisterCodec(reflect.TypeOf((*orderModel.MessageKind)(nil)).Elem(), orderModel.MessageKindCodec{}).RegisterCodec(reflect.TypeOf((*messageModel.Kind)(nil)).Elem(), messageModel.Codec{}).Build()))
-
-	client, err := mongo.Connect(context.Background(), options.Client().SetHosts(conf.Mongodb.Hosts).SetReplicaSet("replicaset").SetReadPreference(readpref.Primary()).SetAuth(auth).SetRegistry(bson.NewRegistryBuilder().Build()))
+	// 各个包在init中通过mongocodec注册codec(tools.Time、objectid.ObjectID、proto枚举和消息等)，这里组装一次
+	l.Log(log.LevelInfo, "msg", "mongodb codecs", "packages", strings.Join(mongocodec.Names(), ","))
+	client, err := mongo.Connect(context.Background(), options.Client().SetHosts(conf.Mongodb.Hosts).SetReplicaSet("replicaset").SetReadPreference(readpref.Primary()).SetAuth(auth).SetRegistry(mongocodec.Default()))
 	if err != nil {
 		return
 	}
@@ -33,29 +33,18 @@ func NewMongoDB(conf *conf.Data, l log.Logger) (db *mongo.Database, cf func(), e
 			l.Log(log.LevelError, "关闭Mongo客户端连接池失败: %#v", err)
 		}
 	}
-	// 这里可以root登录访问别的db  但是目前只使用一个数据库  可能访问oplog的时候需要访问admin数据库
+	// 这里可以root登录访问别的db  但是目前只使用一个数据库  变更订阅使用change stream(nosql.Watcher)，不需要访问oplog
 	db = client.Database(conf.Mongodb.AuthSource)
 	return
 }
 
-func ComponentStart(component nosql.DBComponent, client *mongo.Database) (err error) {
-	keys := component.Keys()
-	for key, spec := range keys {
+// ComponentBind 绑定模块用到的集合，数据升级和索引由Migrator负责
+func ComponentBind(component nosql.DBComponent, client *mongo.Database) {
+	for key, spec := range component.Keys() {
 		collection := client.Collection(key)
 		component.Collections()[key] = collection
 		if spec != nil {
 			spec.SetCollection(collection)
 		}
 	}
-	for key, spec := range keys {
-		if spec != nil {
-			if err = component.Init(); err != nil {
-				return fmt.Errorf("初始化%s component失败: %w", key, err)
-			}
-			if err := spec.Update(key); err != nil {
-				return fmt.Errorf("检查并升级%s collection数据失败: %w", key, err)
-			}
-		}
-	}
-	return
 }
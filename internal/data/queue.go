@@ -0,0 +1,47 @@
+package data
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos-layout/internal/biz"
+	"github.com/go-kratos/kratos-layout/internal/conf"
+	"github.com/go-kratos/kratos-layout/pkg/queue"
+	"github.com/go-kratos/kratos/v2/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewQueue 后台任务队列，任务的处理函数在这里注册，cmd/server注册为kratos的server
+func NewQueue(c *conf.Data, data *Data, counters *counterRepo, tp trace.TracerProvider, logger log.Logger) *queue.Queue {
+	opts := []queue.Option{queue.WithTracerProvider(tp), queue.WithLogger(logger)}
+	if qc := c.Queue; qc != nil {
+		if qc.Prefix != "" {
+			opts = append(opts, queue.WithPrefix(qc.Prefix))
+		}
+		if qc.Concurrency > 0 {
+			opts = append(opts, queue.WithConcurrency(int(qc.Concurrency)))
+		}
+		if qc.VisibilityTimeout != nil {
+			opts = append(opts, queue.WithVisibilityTimeout(qc.VisibilityTimeout.AsDuration()))
+		}
+		if qc.DrainTimeout != nil {
+			opts = append(opts, queue.WithDrainTimeout(qc.DrainTimeout.AsDuration()))
+		}
+		if qc.MaxRetries > 0 {
+			opts = append(opts, queue.WithMaxRetries(int(qc.MaxRetries)))
+		}
+	}
+	q := queue.New(data.rdb, opts...)
+
+	q.Register(biz.JobCounterReconcile, func(ctx context.Context, job *queue.Job) error {
+		var req biz.CounterReconcile
+		if err := job.Decode(&req); err != nil {
+			return err
+		}
+		ct, ok := counters.counters[req.Name]
+		if !ok {
+			return nil
+		}
+		return ct.Reconcile(ctx, req.IDs...)
+	})
+	return q
+}
@@ -0,0 +1,67 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/internal/biz"
+	"github.com/go-kratos/kratos-layout/pkg/otelmetric"
+	"github.com/go-kratos/kratos-layout/pkg/scheduler"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// NewScheduler 定时任务，cron表达式按业务时区(tools.Location())解析，同一个计划时间集群中只执行一次
+func NewScheduler(data *Data, migrator *Migrator, counters *counterRepo, jobs biz.JobQueue, events *eventRepo, logger log.Logger) (*scheduler.Scheduler, error) {
+	s := scheduler.New(data.rdb,
+		scheduler.WithLogger(logger),
+		scheduler.WithRunCounter(otelmetric.NewCounter(otelmetric.Meter(), "scheduler_runs_total", "定时任务执行次数", "job", "result")),
+		scheduler.WithDurationObserver(otelmetric.NewObserver(otelmetric.Meter(), "scheduler_run_seconds", "定时任务执行耗时", "job")),
+	)
+	helper := log.NewHelper(logger)
+
+	// 索引和声明不一致时提醒执行index apply
+	if err := s.Add("index:check", "@hourly", func(ctx context.Context) error {
+		plan, err := migrator.PlanIndex(ctx)
+		if err != nil {
+			return err
+		}
+		for _, change := range plan {
+			helper.Warnf("索引需要升级: %s", change)
+		}
+		return nil
+	}, scheduler.Jitter(time.Minute)); err != nil {
+		return nil, err
+	}
+
+	// 排行榜中的计数每天以mysql为准校正一次
+	if err := s.Add("counter:reconcile", "0 30 4 * * *", func(ctx context.Context) error {
+		for name, ct := range counters.counters {
+			ranks, err := ct.Top(ctx, 1000)
+			if err != nil {
+				return err
+			}
+			ids := make([]string, 0, len(ranks))
+			for _, rank := range ranks {
+				ids = append(ids, rank.ID)
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			if _, err = jobs.Enqueue(ctx, biz.JobCounterReconcile, biz.CounterReconcile{Name: name, IDs: ids}); err != nil {
+				return fmt.Errorf("校正计数[%s]: %w", name, err)
+			}
+		}
+		return nil
+	}, scheduler.Missed(scheduler.MissedRunOnce), scheduler.Timeout(time.Minute)); err != nil {
+		return nil, err
+	}
+
+	// mongo的outbox由TTL索引清理，mysql的outbox定时删除超过retention的已投递消息
+	if err := s.Add("outbox:purge", "@hourly", func(ctx context.Context) error {
+		return events.gorm.Purge(ctx, time.Now().Add(-events.retention))
+	}, scheduler.Jitter(time.Minute), scheduler.Timeout(5*time.Minute)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
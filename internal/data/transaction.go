@@ -0,0 +1,105 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-kratos/kratos-layout/internal/biz"
+	"github.com/go-kratos/kratos-layout/pkg/nosql"
+	"gorm.io/gorm"
+)
+
+type gormTxKey struct{}
+
+// WithTransaction mongo事务，repo使用nosql和mongo的方法时传入fn的ctx即可加入事务
+// ctx中已经有事务时直接加入，TransientTransactionError和UnknownTransactionCommitResult会重试
+func (d *Data) WithTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...nosql.TransactionOption) error {
+	return nosql.WithTransaction(ctx, d.mongodb.Client(), fn, opts...)
+}
+
+// gormTx 事务中的mysql事务，InTx中在repo第一次调用Data.DB时才开始，只使用mongo时不需要mysql
+type gormTx struct {
+	ctx context.Context // 事务的ctx，repo传入的ctx可能更早结束，不能用来开始事务
+	db  *gorm.DB
+	mu  sync.Mutex
+	tx  *gorm.DB
+}
+
+// get 开始失败时返回的*gorm.DB带有错误，后续操作都返回这个错误
+func (t *gormTx) get() *gorm.DB {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tx == nil {
+		t.tx = t.db.WithContext(t.ctx).Begin()
+	}
+	return t.tx
+}
+
+// end 没有开始或者开始失败时不需要结束
+func (t *gormTx) end(committed bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tx := t.tx
+	t.tx = nil
+	if tx == nil || tx.Error != nil {
+		return nil
+	}
+	if committed {
+		return tx.Commit().Error
+	}
+	return tx.Rollback().Error
+}
+
+// WithGormTransaction mysql事务，repo通过Data.DB(ctx)加入事务，ctx中已经有事务时直接加入
+func (d *Data) WithGormTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(gormTxKey{}).(*gormTx); ok {
+		return fn(ctx)
+	}
+	return d.mysql.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, gormTxKey{}, &gormTx{ctx: ctx, db: d.mysql, tx: tx}))
+	})
+}
+
+// inGormTx ctx中是否有mysql事务，InTx中没有开始的事务也算
+func inGormTx(ctx context.Context) bool {
+	_, ok := ctx.Value(gormTxKey{}).(*gormTx)
+	return ok
+}
+
+// DB repo访问mysql的入口，ctx中有事务时返回事务
+func (d *Data) DB(ctx context.Context) *gorm.DB {
+	if t, ok := ctx.Value(gormTxKey{}).(*gormTx); ok {
+		return t.get().WithContext(ctx)
+	}
+	return d.mysql.WithContext(ctx)
+}
+
+// transaction mongo事务在外层，mysql事务在repo第一次使用时开始，在mongo提交成功后提交，
+// mongo事务重试时mysql事务也重新开始，fn panic时两者都回滚
+// 两者不是分布式事务，mongo提交后mysql提交失败时InTx返回biz.ErrPartialCommit，mongo的修改已经生效
+type transaction struct {
+	data *Data
+}
+
+// NewTransaction .
+func NewTransaction(data *Data) biz.Transaction {
+	return &transaction{data: data}
+}
+
+func (t *transaction) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return t.data.WithTransaction(ctx, func(ctx context.Context) error {
+		if inGormTx(ctx) {
+			return fn(ctx)
+		}
+		tx := &gormTx{ctx: ctx, db: t.data.mysql}
+		nosql.OnTransactionEnd(ctx, func(_ context.Context, committed bool) error {
+			err := tx.end(committed)
+			if err != nil && committed {
+				return fmt.Errorf("%w, 提交mysql事务: %v", biz.ErrPartialCommit, err)
+			}
+			return err
+		})
+		return fn(context.WithValue(ctx, gormTxKey{}, tx))
+	})
+}
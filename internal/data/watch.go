@@ -0,0 +1,38 @@
+package data
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos-layout/pkg/nosql"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GreeterCacheWatcher 订阅greeter集合的变更，删除被其他服务或者手工修改的greeter的缓存和列表缓存，
+// 本服务的写入已经由repo删除，重复删除没有影响
+type GreeterCacheWatcher struct {
+	*nosql.Watcher
+}
+
+// NewGreeterCacheWatcher 每个实例都运行，处理位置保存在redis中，需要mongodb副本集
+func NewGreeterCacheWatcher(data *Data, repo *greeterRepo) *GreeterCacheWatcher {
+	operations := bson.A{nosql.OperationInsert, nosql.OperationUpdate, nosql.OperationReplace, nosql.OperationDelete, nosql.OperationInvalidate}
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": operations}}}}}
+	w := nosql.NewWatcher("greeter:cache", func(ctx context.Context, event *nosql.ChangeEvent) error {
+		if err := data.cache.InvalidateTags(ctx, greeterListTag); err != nil {
+			return err
+		}
+		id, ok := event.DocumentID().ObjectIDOK()
+		if !ok {
+			return nil
+		}
+		return data.cache.Delete(ctx, greeterCacheKey(id.Hex()))
+	},
+		nosql.WatchCollection(repo.collection()),
+		nosql.WatchPipeline(pipeline),
+		nosql.WatchFullDocument(options.Default),
+		nosql.WatchTokenStore(nosql.NewRedisTokenStore(data.rdb, "watch:")),
+	)
+	return &GreeterCacheWatcher{Watcher: w}
+}
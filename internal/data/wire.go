@@ -14,7 +14,7 @@ import (
 )
 
 func NewData(conf *conf.Data, l log.Logger) (*Data, func(), error) {
-	panic(wire.Build(log.NewHelper, NewMysql, NewRedis, NewMongoDB, wire.Struct(new(Data), "*")))
+	panic(wire.Build(log.NewHelper, NewMysql, NewRedis, NewMongoDB, NewCache, NewLocker, wire.Struct(new(Data), "*")))
 }
 
 func newTestRepo(*conf.Data) (*greeterRepo, func(), error) {
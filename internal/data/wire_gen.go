@@ -30,13 +30,24 @@ func NewData(conf2 *conf.Data, l log.Logger) (*Data, func(), error) {
 		cleanup()
 		return nil, nil, err
 	}
+	cache, cleanup4, err := NewCache(conf2, client, l)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	locker := NewLocker(client, l)
 	data := &Data{
 		helper:  helper,
 		mysql:   db,
 		rdb:     client,
 		mongodb: database,
+		cache:   cache,
+		locker:  locker,
 	}
 	return data, func() {
+		cleanup4()
 		cleanup3()
 		cleanup2()
 		cleanup()
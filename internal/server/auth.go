@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	v1 "github.com/go-kratos/kratos-layout/api/helloworld/v1"
+	"github.com/go-kratos/kratos-layout/internal/conf"
+	"github.com/go-kratos/kratos/v2/middleware"
+	kgrpc "github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// healthService grpc健康检查不需要认证
+const healthService = "/grpc.health.v1.Health/"
+
+// authenticator 校验Authorization: Bearer <token>，没有配置token时不校验
+type authenticator struct {
+	tokens [][]byte
+}
+
+func newAuthenticator(c *conf.Auth) *authenticator {
+	a := &authenticator{}
+	for _, token := range c.GetTokens() {
+		if token != "" {
+			a.tokens = append(a.tokens, []byte(token))
+		}
+	}
+	return a
+}
+
+// check 从http请求头或grpc metadata中取出token校验
+func (a *authenticator) check(ctx context.Context) error {
+	if len(a.tokens) == 0 {
+		return nil
+	}
+	var authorization string
+	if info, ok := http.FromServerContext(ctx); ok && info.Request != nil {
+		authorization = info.Request.Header.Get("Authorization")
+	} else if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+	const prefix = "Bearer "
+	if len(authorization) < len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return v1.ErrorUnauthorized("missing bearer token")
+	}
+	token := []byte(authorization[len(prefix):])
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare(t, token) == 1 {
+			return nil
+		}
+	}
+	return v1.ErrorUnauthorized("invalid bearer token")
+}
+
+// Server unary请求的认证
+func (a *authenticator) Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if info, ok := kgrpc.FromServerContext(ctx); ok && strings.HasPrefix(info.FullMethod, healthService) {
+				return handler(ctx, req)
+			}
+			if err := a.check(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Stream 流式请求在建立时认证
+func (a *authenticator) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, healthService) {
+			return handler(srv, ss)
+		}
+		if err := a.check(ss.Context()); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/go-kratos/kratos-layout/api/helloworld/v1"
+	"github.com/go-kratos/kratos-layout/internal/biz"
+	"github.com/go-kratos/kratos-layout/internal/conf"
+	"github.com/go-kratos/kratos-layout/internal/service"
+	"github.com/go-kratos/kratos-layout/pkg/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type healthy struct{}
+
+func (healthy) Ping(context.Context) error { return nil }
+
+func newTestServers(t *testing.T, c *conf.Server, uc *biz.GreeterUsecase) (http.Handler, v1.GreeterClient) {
+	logger := log.NewStdLogger(&testWriter{t})
+	greeter := service.NewGreeterService(uc, logger)
+
+	gs := NewGRPCServer(c, greeter, trace.NewNoopTracerProvider(), logger)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.GracefulStop)
+	cc, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cc.Close() })
+
+	return NewHTTPServer(c, greeter, healthy{}, logger).Handler, v1.NewGreeterClient(cc)
+}
+
+type testWriter struct{ t *testing.T }
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.t.Log(string(p))
+	return len(p), nil
+}
+
+func TestErrors(t *testing.T) {
+	hs, client := newTestServers(t, &conf.Server{Http: &conf.HTTP{}, Grpc: &conf.GRPC{}}, nil)
+
+	// http
+	req := httptest.NewRequest(http.MethodGet, "/helloworld/error", nil)
+	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9")
+	rec := httptest.NewRecorder()
+	hs.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, "USER_NOT_FOUND", body["reason"])
+	require.Equal(t, "用户error不存在", body["message"])
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/greeters/bad", nil)
+	rec = httptest.NewRecorder()
+	hs.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), `"reason":"INVALID_ARGUMENT"`)
+	require.Contains(t, rec.Body.String(), `"field":"Id"`)
+
+	// grpc
+	ctx := metadata.AppendToOutgoingContext(context.Background(), errors.AcceptLanguageKey, "zh")
+	_, err := client.SayHello(ctx, &v1.HelloRequest{Name: "error"})
+	require.True(t, v1.IsUserNotFound(err))
+	s := errors.FromError(err)
+	require.Equal(t, "用户error不存在", s.Message())
+	require.Equal(t, "zh", s.Locale())
+
+	_, err = client.GetGreeter(context.Background(), &v1.GetGreeterRequest{Id: "bad"})
+	require.True(t, v1.IsInvalidArgument(err))
+	require.Equal(t, "invalid argument", errors.FromError(err).Message())
+	require.Equal(t, "Id", errors.FromError(err).FieldViolations()[0].Field)
+}
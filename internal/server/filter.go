@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-kratos/kratos-layout/internal/service"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ServiceInfoFilter 在请求的ctx中放入ServiceInfo，handler写响应时处理注册的钩子
+func ServiceInfoFilter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := &service.ServiceInfo{}
+		r = r.WithContext(service.WithServiceKey(r.Context(), info))
+		sw := &serviceInfoWriter{ResponseWriter: w, request: r, info: info}
+		next.ServeHTTP(sw, r)
+		// handler没有写响应时钩子也要生效
+		sw.WriteHeader(http.StatusOK)
+	})
+}
+
+// serviceInfoWriter 第一次写响应头时处理ServiceInfo
+type serviceInfoWriter struct {
+	http.ResponseWriter
+	request     *http.Request
+	info        *service.ServiceInfo
+	wroteHeader bool
+	redirected  bool
+}
+
+func (w *serviceInfoWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	info := w.info
+	if info.HeaderFunc != nil {
+		info.HeaderFunc(w.Header())
+	}
+	for _, cookie := range info.Cookies {
+		http.SetCookie(w.ResponseWriter, cookie)
+	}
+	if code < http.StatusBadRequest {
+		if info.RedirectFunc != nil {
+			// 丢弃handler的响应
+			w.redirected = true
+			info.RedirectFunc(w.ResponseWriter, w.request)
+			return
+		}
+		if info.Status != 0 {
+			code = info.Status
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *serviceInfoWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.redirected {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush 流式响应(例如server-sent events)经过filter时也能立即发送
+func (w *serviceInfoWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.redirected {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// ServiceInfoServer grpc中handler成功返回后把HeaderFunc和Cookies作为header metadata发送
+func ServiceInfoServer() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			info := &service.ServiceInfo{}
+			reply, err := handler(service.WithServiceKey(ctx, info), req)
+			if err != nil {
+				return reply, err
+			}
+			header := http.Header{}
+			if info.HeaderFunc != nil {
+				info.HeaderFunc(header)
+			}
+			for _, cookie := range info.Cookies {
+				if v := cookie.String(); v != "" {
+					header.Add("Set-Cookie", v)
+				}
+			}
+			if len(header) == 0 {
+				return reply, nil
+			}
+			md := make(metadata.MD, len(header))
+			for k, v := range header {
+				md[strings.ToLower(k)] = v
+			}
+			return reply, grpc.SetHeader(ctx, md)
+		}
+	}
+}
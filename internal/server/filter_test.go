@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos-layout/internal/service"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// register 模拟handler和中间件先后注册钩子
+func register(ctx context.Context, order *[]string) {
+	service.WithServiceKey(ctx, &service.ServiceInfo{
+		HeaderFunc: func(header http.Header) {
+			*order = append(*order, "first")
+			header.Set("X-Hook", "first")
+		},
+		Cookies: []*http.Cookie{{Name: "a", Value: "1"}},
+		Status:  http.StatusCreated,
+	})
+	service.WithServiceKey(ctx, &service.ServiceInfo{
+		HeaderFunc: func(header http.Header) {
+			*order = append(*order, "second")
+			header.Set("X-Hook", "second")
+		},
+		Cookies: []*http.Cookie{{Name: "b", Value: "2"}},
+		Status:  http.StatusAccepted,
+	})
+}
+
+func TestServiceInfoFilter(t *testing.T) {
+	var order []string
+	h := ServiceInfoFilter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		register(r.Context(), &order)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, []string{"first", "second"}, order)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Equal(t, "second", rec.Header().Get("X-Hook"))
+	require.Equal(t, []string{"a=1", "b=2"}, rec.Header()["Set-Cookie"])
+	require.Equal(t, "ok", rec.Body.String())
+}
+
+func TestServiceInfoFilterFlush(t *testing.T) {
+	h := ServiceInfoFilter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		service.WithServiceKey(r.Context(), &service.ServiceInfo{Status: http.StatusAccepted})
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+		_, _ = w.Write([]byte("data: 1\n\n"))
+		flusher.Flush()
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.True(t, rec.Flushed)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Equal(t, "data: 1\n\n", rec.Body.String())
+}
+
+func TestServiceInfoFilterRedirect(t *testing.T) {
+	h := ServiceInfoFilter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		service.WithServiceKey(r.Context(), &service.ServiceInfo{
+			RedirectFunc: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/login", http.StatusFound)
+			},
+		})
+		_, _ = w.Write([]byte("dropped"))
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, "/login", rec.Header().Get("Location"))
+	require.NotContains(t, rec.Body.String(), "dropped")
+}
+
+func TestServiceInfoFilterRedirectOnce(t *testing.T) {
+	var calls []string
+	h := ServiceInfoFilter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, target := range []string{"/login", "/home"} {
+			target := target
+			service.WithServiceKey(r.Context(), &service.ServiceInfo{
+				RedirectFunc: func(w http.ResponseWriter, r *http.Request) {
+					calls = append(calls, target)
+					http.Redirect(w, r, target, http.StatusFound)
+				},
+			})
+		}
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, []string{"/login"}, calls)
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, []string{"/login"}, rec.Header()["Location"])
+}
+
+func TestServiceInfoFilterError(t *testing.T) {
+	var order []string
+	h := ServiceInfoFilter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		register(r.Context(), &order)
+		service.WithServiceKey(r.Context(), &service.ServiceInfo{
+			RedirectFunc: func(w http.ResponseWriter, r *http.Request) {
+				t.Fatal("错误响应不应该跳转")
+			},
+		})
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.Equal(t, "second", rec.Header().Get("X-Hook"))
+	require.Len(t, rec.Header()["Set-Cookie"], 2)
+}
+
+type headerStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *headerStream) Method() string { return "/helloworld.v1.Greeter/SayHello" }
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestServiceInfoServer(t *testing.T) {
+	var order []string
+	stream := &headerStream{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	reply, err := ServiceInfoServer()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		register(ctx, &order)
+		return "ok", nil
+	})(ctx, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, "ok", reply)
+	require.Equal(t, []string{"first", "second"}, order)
+	require.Equal(t, []string{"second"}, stream.header.Get("x-hook"))
+	require.Equal(t, []string{"a=1", "b=2"}, stream.header.Get("set-cookie"))
+}
@@ -4,24 +4,37 @@ import (
 	v1 "github.com/go-kratos/kratos-layout/api/helloworld/v1"
 	"github.com/go-kratos/kratos-layout/internal/conf"
 	"github.com/go-kratos/kratos-layout/internal/service"
+	"github.com/go-kratos/kratos-layout/pkg/errors"
+	"github.com/go-kratos/kratos-layout/pkg/logger"
 	"github.com/go-kratos/kratos/v2/log"
-	"github.com/go-kratos/kratos/v2/middleware/logging"
 	"github.com/go-kratos/kratos/v2/middleware/recovery"
 	"github.com/go-kratos/kratos/v2/middleware/tracing"
-	"github.com/go-kratos/kratos/v2/middleware/validate"
 	"github.com/go-kratos/kratos/v2/transport/grpc"
 	"go.opentelemetry.io/otel/trace"
+	grpc1 "google.golang.org/grpc"
 )
 
 // NewGRPCServer new a gRPC server.
-func NewGRPCServer(c *conf.Server, greeter *service.GreeterService, tracer trace.TracerProvider, logger log.Logger) *grpc.Server {
+func NewGRPCServer(c *conf.Server, greeter *service.GreeterService, tracer trace.TracerProvider, l log.Logger) *grpc.Server {
+	auth := newAuthenticator(c.Auth)
 	var opts = []grpc.ServerOption{
 		grpc.Middleware(
 			recovery.Recovery(),
+			ServiceInfoServer(),
+			errors.Server(service.ErrorCatalog),
 			tracing.Server(tracing.WithTracerProvider(tracer)),
-			validate.Validator(),
-			logging.Server(logger),
+			auth.Server(),
+			validator(),
+			logger.Server(l),
 		),
+		grpc.Options(grpc1.ChainStreamInterceptor(
+			streamRecovery(l),
+			streamErrors(service.ErrorCatalog),
+			streamTracing(tracer),
+			streamLogging(l),
+			auth.Stream(),
+			streamValidator(),
+		)),
 	}
 	if c.Grpc.Network != "" {
 		opts = append(opts, grpc.Network(c.Grpc.Network))
@@ -4,15 +4,18 @@ import (
 	v1 "github.com/go-kratos/kratos-layout/api/helloworld/v1"
 	"github.com/go-kratos/kratos-layout/internal/conf"
 	"github.com/go-kratos/kratos-layout/internal/service"
+	"github.com/go-kratos/kratos-layout/pkg/errors"
+	"github.com/go-kratos/kratos-layout/pkg/logger"
 	"github.com/go-kratos/kratos/v2/log"
-	"github.com/go-kratos/kratos/v2/middleware/logging"
+	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/middleware/recovery"
 	"github.com/go-kratos/kratos/v2/middleware/tracing"
 	"github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/go-kratos/kratos/v2/transport/http/health"
 )
 
 // NewHTTPServer new a HTTP server.
-func NewHTTPServer(c *conf.Server, greeter *service.GreeterService, logger log.Logger) *http.Server {
+func NewHTTPServer(c *conf.Server, greeter *service.GreeterService, checker HealthChecker, l log.Logger) *http.Server {
 	var opts = []http.ServerOption{}
 	if c.Http.Network != "" {
 		opts = append(opts, http.Network(c.Http.Network))
@@ -24,12 +27,21 @@ func NewHTTPServer(c *conf.Server, greeter *service.GreeterService, logger log.L
 		opts = append(opts, http.Timeout(c.Http.Timeout.AsDuration()))
 	}
 	srv := http.NewServer(opts...)
-	m := http.Middleware(
+	auth := newAuthenticator(c.Auth)
+	m := middleware.Chain(
 		recovery.Recovery(),
 		tracing.Server(),
-		logging.Server(logger),
+		auth.Server(),
+		validator(),
+		logger.Server(l),
 	)
+	encodeError := errors.ErrorEncoder(service.ErrorCatalog)
 
-	srv.HandlePrefix("/", v1.NewGreeterHandler(greeter, m))
+	hh := health.NewHandler()
+	hh.AddChecker("data", checker.Ping)
+	srv.Handle(HealthPath, hh)
+	srv.Handle(WatchPath, watchHandler(greeter, m, encodeError))
+	srv.HandlePrefix("/", ServiceInfoFilter(v1.NewGreeterHandler(greeter, http.Middleware(m), http.ErrorEncoder(encodeError))))
+	srv.Handler = StreamFilter(srv.Handler)
 	return srv
 }
@@ -1,8 +1,18 @@
 package server
 
 import (
+	"context"
+
 	"github.com/google/wire"
 )
 
+// HealthPath http健康检查地址
+const HealthPath = "/healthz"
+
 // ProviderSet is server providers.
 var ProviderSet = wire.NewSet(NewHTTPServer, NewGRPCServer)
+
+// HealthChecker 依赖的资源是否可用，返回错误时健康检查失败
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	nethttp "net/http"
+	"strings"
+
+	v1 "github.com/go-kratos/kratos-layout/api/helloworld/v1"
+	"github.com/go-kratos/kratos-layout/internal/service"
+	"github.com/go-kratos/kratos-layout/pkg/errors"
+	"github.com/go-kratos/kratos/v2/encoding"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/metadata"
+)
+
+// WatchPath WatchGreeters的server-sent events地址
+// 事件的id为resume_token，断开重连时浏览器通过Last-Event-ID继续，也可以通过resume_token参数指定
+// types参数为GreeterEvent.Type的名字，可以有多个
+const WatchPath = "/v1/greeters:watch"
+
+// untimedKey 保存进入http.Server.ServeHTTP之前的请求ctx
+type untimedKey struct{}
+
+// StreamFilter http.Server.ServeHTTP会给请求加上超时，长连接在进入路由前保存没有超时的ctx，
+// 由watchHandler恢复，路由和中间件和其它接口一致
+func StreamFilter(next nethttp.Handler) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.URL.Path == WatchPath {
+			r = r.WithContext(context.WithValue(r.Context(), untimedKey{}, r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// watchHandler 把WatchGreeters桥接为server-sent events，经过和其它接口相同的中间件，
+// 订阅成功后的错误作为error事件发送
+func watchHandler(greeter *service.GreeterService, m middleware.Middleware, encodeError http.EncodeErrorFunc) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.Method != nethttp.MethodGet {
+			encodeError(w, r, kerrors.New(nethttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", r.Method))
+			return
+		}
+		flusher, ok := w.(nethttp.Flusher)
+		if !ok {
+			encodeError(w, r, kerrors.InternalServer("STREAMING_UNSUPPORTED", "streaming unsupported"))
+			return
+		}
+		ctx := r.Context()
+		if untimed, ok := ctx.Value(untimedKey{}).(context.Context); ok {
+			ctx = transport.NewContext(untimed, transport.Transport{Kind: transport.KindHTTP})
+			ctx = http.NewServerContext(ctx, http.ServerInfo{Request: r, Response: w})
+		}
+		in, err := watchRequest(r)
+		if err != nil {
+			encodeError(w, r, err)
+			return
+		}
+		stream := &sseStream{w: w, flusher: flusher}
+		next := func(ctx context.Context, req interface{}) (interface{}, error) {
+			stream.ctx = ctx
+			return nil, greeter.WatchGreeters(req.(*v1.WatchGreetersRequest), stream)
+		}
+		if m != nil {
+			next = m(next)
+		}
+		if _, err = next(ctx, in); err != nil {
+			if !stream.started {
+				encodeError(w, r, err)
+				return
+			}
+			if ctx.Err() == nil {
+				s := service.ErrorCatalog.Localize(err, r.Header.Get("Accept-Language"))
+				_ = stream.event("", "error", s)
+			}
+		}
+	})
+}
+
+func watchRequest(r *nethttp.Request) (*v1.WatchGreetersRequest, error) {
+	query := r.URL.Query()
+	in := &v1.WatchGreetersRequest{ResumeToken: r.Header.Get("Last-Event-ID")}
+	if in.ResumeToken == "" {
+		in.ResumeToken = query.Get("resume_token")
+	}
+	for _, name := range query["types"] {
+		t, ok := v1.GreeterEvent_Type_value[strings.ToUpper(name)]
+		if !ok {
+			return nil, errors.FromError(v1.ErrorInvalidArgument("unknown type %s", name)).WithFieldViolation("types", "unknown type "+name)
+		}
+		in.Types = append(in.Types, v1.GreeterEvent_Type(t))
+	}
+	return in, nil
+}
+
+// sseStream 实现v1.Greeter_WatchGreetersServer，每个GreeterEvent写成一个事件
+type sseStream struct {
+	ctx     context.Context
+	w       nethttp.ResponseWriter
+	flusher nethttp.Flusher
+	started bool
+}
+
+func (s *sseStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *sseStream) SetHeader(md metadata.MD) error {
+	for k, values := range md {
+		for _, v := range values {
+			s.w.Header().Add(k, v)
+		}
+	}
+	return nil
+}
+
+// SendHeader 订阅成功，开始响应
+func (s *sseStream) SendHeader(md metadata.MD) error {
+	if s.started {
+		return nil
+	}
+	_ = s.SetHeader(md)
+	s.started = true
+	h := s.w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	s.w.WriteHeader(nethttp.StatusOK)
+	s.flusher.Flush()
+	return nil
+}
+
+func (s *sseStream) SetTrailer(metadata.MD) {}
+
+func (s *sseStream) Send(e *v1.GreeterEvent) error {
+	return s.event(e.GetResumeToken(), e.GetType().String(), e)
+}
+
+func (s *sseStream) SendMsg(m interface{}) error {
+	return s.Send(m.(*v1.GreeterEvent))
+}
+
+func (s *sseStream) RecvMsg(interface{}) error {
+	return kerrors.InternalServer("STREAMING_UNSUPPORTED", "server-sent events are send only")
+}
+
+func (s *sseStream) event(id, name string, v interface{}) error {
+	if err := s.SendHeader(nil); err != nil {
+		return err
+	}
+	data, err := encoding.GetCodec("json").Marshal(v)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if id != "" {
+		buf.WriteString("id: " + id + "\n")
+	}
+	buf.WriteString("event: " + name + "\n")
+	buf.WriteString("data: ")
+	buf.Write(data)
+	buf.WriteString("\n\n")
+	if _, err = s.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/errors"
+	"github.com/go-kratos/kratos-layout/pkg/logger"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// grpc的middleware只作用于unary请求，流式请求使用下面的拦截器，顺序和NewGRPCServer中的middleware一致
+
+// serverStream 替换流的ctx
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// streamRecovery 处理流中的panic
+func streamRecovery(l log.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if rerr := recover(); rerr != nil {
+				buf := make([]byte, 64<<10)
+				n := runtime.Stack(buf, false)
+				logger.NewHelper(ss.Context(), l).Errorf("%v: %s\n%s\n", rerr, info.FullMethod, buf[:n])
+				err = kerrors.InternalServer("RECOVERY", fmt.Sprintf("panic triggered: %v", rerr))
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// streamErrors 同errors.Server，流结束时的错误转为本地化的Status
+func streamErrors(c *errors.Catalog) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return c.Localize(err, errors.AcceptLanguage(ss.Context()))
+		}
+		return nil
+	}
+}
+
+// streamTracing 整个流作为一个span
+func streamTracing(tp trace.TracerProvider) grpc.StreamServerInterceptor {
+	tracer := tracing.NewTracer(trace.SpanKindServer, tracing.WithTracerProvider(tp))
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		ctx, span := tracer.Start(ss.Context(), "gRPC", info.FullMethod, tracing.MetadataCarrier(md.Copy()))
+		defer func() { tracer.End(ctx, span, err) }()
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// streamLogging 流结束时记录一条日志
+func streamLogging(l log.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		helper := logger.NewHelper(ss.Context(), l)
+		var traceID string
+		if tid := trace.SpanContextFromContext(ss.Context()).TraceID(); tid.IsValid() {
+			traceID = tid.String()
+		}
+		start := time.Now()
+		err := handler(srv, ss)
+		keyvals := []interface{}{
+			"kind", "server",
+			"component", "gRPC",
+			"traceID", traceID,
+			"path", info.FullMethod,
+			"stream", streamKind(info),
+			"latency", time.Since(start).String(),
+		}
+		if err != nil {
+			helper.Errorw(append(keyvals, "code", errors.FromError(err).StatusCode(), "error", err.Error())...)
+			return err
+		}
+		helper.Infow(append(keyvals, "code", 0)...)
+		return nil
+	}
+}
+
+func streamKind(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return "bidi"
+	case info.IsClientStream:
+		return "client"
+	default:
+		return "server"
+	}
+}
+
+// streamValidator 校验流中收到的每个请求
+func streamValidator() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validateStream{ss})
+	}
+}
+
+type validateStream struct {
+	grpc.ServerStream
+}
+
+func (s *validateStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validate(m)
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/go-kratos/kratos-layout/api/helloworld/v1"
+	"github.com/go-kratos/kratos-layout/internal/biz"
+	"github.com/go-kratos/kratos-layout/internal/conf"
+	"github.com/go-kratos/kratos-layout/pkg/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+// newWatchUsecase 每次订阅返回一个created事件后结束
+func newWatchUsecase(t *testing.T, watches int) *biz.GreeterUsecase {
+	ctrl := gomock.NewController(t)
+	repo := biz.NewMockGreeterRepo(ctrl)
+	repo.EXPECT().WatchGreeter(gomock.Any(), biz.GreeterWatch{ResumeToken: "t0", Types: []biz.GreeterEventType{biz.GreeterCreated}}).
+		DoAndReturn(func(context.Context, biz.GreeterWatch) (biz.GreeterWatcher, error) {
+			w := biz.NewMockGreeterWatcher(ctrl)
+			gomock.InOrder(
+				w.EXPECT().Next(gomock.Any()).DoAndReturn(func(ctx context.Context) (*biz.GreeterEvent, error) {
+					// 长连接不受http.Server的请求超时限制
+					_, ok := ctx.Deadline()
+					require.False(t, ok)
+					return &biz.GreeterEvent{
+						Type:        biz.GreeterCreated,
+						Greeter:     &biz.Greeter{ID: "60d5ec49f1a2c8b1f8e4b1a1", Hello: "hi"},
+						ResumeToken: "t1",
+					}, nil
+				}),
+				w.EXPECT().Next(gomock.Any()).Return(nil, io.EOF),
+			)
+			w.EXPECT().Close(gomock.Any()).Return(nil)
+			return w, nil
+		}).Times(watches)
+	return biz.NewGreeterUsecase(repo, biz.NewMockTransaction(ctrl), biz.NewMockEventPublisher(ctrl), log.DefaultLogger)
+}
+
+func TestWatchGreeters(t *testing.T) {
+	c := &conf.Server{Http: &conf.HTTP{}, Grpc: &conf.GRPC{}}
+	hs, client := newTestServers(t, c, newWatchUsecase(t, 2))
+
+	// grpc
+	stream, err := client.WatchGreeters(context.Background(), &v1.WatchGreetersRequest{
+		ResumeToken: "t0",
+		Types:       []v1.GreeterEvent_Type{v1.GreeterEvent_CREATED},
+	})
+	require.NoError(t, err)
+	e, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, v1.GreeterEvent_CREATED, e.GetType())
+	require.Equal(t, "hi", e.GetGreeter().GetHello())
+	require.Equal(t, "t1", e.GetResumeToken())
+	_, err = stream.Recv()
+	require.Equal(t, io.EOF, err)
+
+	// server-sent events
+	req := httptest.NewRequest(http.MethodGet, WatchPath+"?types=created", nil)
+	req.Header.Set("Last-Event-ID", "t0")
+	rec := httptest.NewRecorder()
+	hs.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	// protojson的输出中随机加入空格，data按json比较
+	body := rec.Body.String()
+	require.True(t, strings.HasPrefix(body, "id: t1\nevent: CREATED\ndata: "), body)
+	require.True(t, strings.HasSuffix(body, "\n\n"), body)
+	require.JSONEq(t, `{"type":"CREATED","greeter":{"id":"60d5ec49f1a2c8b1f8e4b1a1","hello":"hi","createTime":null,"updateTime":null},"resumeToken":"t1"}`,
+		strings.TrimSpace(strings.TrimPrefix(body, "id: t1\nevent: CREATED\ndata: ")))
+
+	req = httptest.NewRequest(http.MethodGet, WatchPath+"?types=unknown", nil)
+	rec = httptest.NewRecorder()
+	hs.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), `"field":"types"`)
+}
+
+func TestSayHelloStream(t *testing.T) {
+	_, client := newTestServers(t, &conf.Server{Http: &conf.HTTP{}, Grpc: &conf.GRPC{}}, nil)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), errors.AcceptLanguageKey, "zh")
+	stream, err := client.SayHelloStream(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&v1.HelloRequest{Name: "kratos"}))
+	reply, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "Hello kratos", reply.GetMessage())
+
+	// 错误结束整个流，经过拦截器本地化
+	require.NoError(t, stream.Send(&v1.HelloRequest{Name: "error"}))
+	_, err = stream.Recv()
+	require.True(t, v1.IsUserNotFound(err))
+	require.Equal(t, "用户error不存在", errors.FromError(err).Message())
+}
+
+func TestAuth(t *testing.T) {
+	c := &conf.Server{Http: &conf.HTTP{}, Grpc: &conf.GRPC{}, Auth: &conf.Auth{Tokens: []string{"secret"}}}
+	hs, client := newTestServers(t, c, newWatchUsecase(t, 1))
+
+	// unary
+	_, err := client.SayHello(context.Background(), &v1.HelloRequest{Name: "kratos"})
+	require.True(t, v1.IsUnauthorized(err))
+	authorized := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	_, err = client.SayHello(authorized, &v1.HelloRequest{Name: "kratos"})
+	require.NoError(t, err)
+
+	// stream
+	stream, err := client.SayHelloStream(metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong"))
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.True(t, v1.IsUnauthorized(err))
+
+	// http
+	req := httptest.NewRequest(http.MethodGet, "/helloworld/kratos", nil)
+	rec := httptest.NewRecorder()
+	hs.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	req = httptest.NewRequest(http.MethodGet, WatchPath+"?types=CREATED&resume_token=t0", nil)
+	rec = httptest.NewRecorder()
+	hs.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	req.Header.Set("Authorization", "bearer secret")
+	rec = httptest.NewRecorder()
+	hs.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// 健康检查不需要认证
+	req = httptest.NewRequest(http.MethodGet, HealthPath, nil)
+	rec = httptest.NewRecorder()
+	hs.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+}
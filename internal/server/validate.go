@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+	stderrors "errors"
+
+	v1 "github.com/go-kratos/kratos-layout/api/helloworld/v1"
+	"github.com/go-kratos/kratos-layout/pkg/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// fieldError protoc-gen-validate生成的错误
+type fieldError interface {
+	Field() string
+	Reason() string
+}
+
+// validator 参数校验失败时返回INVALID_ARGUMENT，并记录出错的字段
+func validator() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if err := validate(req); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func validate(req interface{}) error {
+	if v, ok := req.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			s := errors.FromError(v1.ErrorInvalidArgument("%s", err.Error()))
+			var fe fieldError
+			if stderrors.As(err, &fe) {
+				s = s.WithFieldViolation(fe.Field(), fe.Reason())
+			}
+			return s
+		}
+	}
+	return nil
+}
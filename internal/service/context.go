@@ -3,13 +3,20 @@ package service
 import (
 	"context"
 	"net/http"
-	"reflect"
 )
 
 type serviceKey struct{}
 
+// ServiceInfo handler通过WithServiceKey注册的响应钩子，transport在handler返回后处理
+// 多次注册时按注册顺序合并:
+// HeaderFunc按注册顺序依次调用，Cookies追加，Status非0时后注册的覆盖先注册的
+// RedirectFunc只使用第一个注册的，跳转只能写一次响应
+// 错误响应只处理HeaderFunc和Cookies，grpc不处理RedirectFunc和Status
 type ServiceInfo struct {
-	RedirectFunc func(w http.ResponseWriter, r *http.Request)
+	RedirectFunc func(w http.ResponseWriter, r *http.Request) // 代替handler的响应，例如http.Redirect
+	HeaderFunc   func(header http.Header)                     // 修改响应头，grpc中作为header metadata
+	Cookies      []*http.Cookie                               // Set-Cookie
+	Status       int                                          // 成功时代替默认的200
 }
 
 func ContextServiceKey(ctx context.Context) *ServiceInfo {
@@ -17,50 +24,36 @@ func ContextServiceKey(ctx context.Context) *ServiceInfo {
 	return biz
 }
 
+// WithServiceKey ctx中已经有ServiceInfo时合并到原来的ServiceInfo，transport可以拿到handler注册的钩子
 func WithServiceKey(ctx context.Context, info *ServiceInfo) context.Context {
 	if info == nil {
 		panic("nil info")
 	}
-	old := ContextServiceKey(ctx)
-	info.compose(old)
+	if old := ContextServiceKey(ctx); old != nil {
+		old.compose(info)
+		return ctx
+	}
 	ctx = context.WithValue(ctx, serviceKey{}, info)
 	return ctx
 }
 
-func (b *ServiceInfo) compose(old *ServiceInfo) {
-	if old == nil {
-		return
+// compose 把后注册的add合并进b
+func (b *ServiceInfo) compose(add *ServiceInfo) {
+	// 两个跳转都执行会写两次响应头和响应体，只使用第一个
+	if b.RedirectFunc == nil {
+		b.RedirectFunc = add.RedirectFunc
 	}
-	tv := reflect.ValueOf(b).Elem()
-	ov := reflect.ValueOf(old).Elem()
-	structType := tv.Type()
-	for i := 0; i < structType.NumField(); i++ {
-		tf := tv.Field(i)
-		bizType := tf.Type()
-		if bizType.Kind() != reflect.Func {
-			continue
-		}
-		of := ov.Field(i)
-		if of.IsNil() {
-			// 如果新方法为nil 则使用旧方法
-			continue
-		}
-		if tf.IsNil() {
-			// 旧方法如果为nil 则使用新方法
-			tf.Set(of)
-			continue
+	if before, after := b.HeaderFunc, add.HeaderFunc; before == nil {
+		b.HeaderFunc = after
+	} else if after != nil {
+		// 先调用之前注册的方法
+		b.HeaderFunc = func(header http.Header) {
+			before(header)
+			after(header)
 		}
-
-		// Make a copy of tf for tf to call. (Otherwise it
-		// creates a recursive call cycle and stack overflows)
-		// 意思是新方法里面不能直接调用旧的方法
-		tfCopy := reflect.ValueOf(tf.Interface())
-
-		// We need to call both tf and of in some order. 合并之前的方法也会调用
-		newFunc := reflect.MakeFunc(bizType, func(args []reflect.Value) []reflect.Value {
-			tfCopy.Call(args)
-			return of.Call(args)
-		})
-		tv.Field(i).Set(newFunc)
+	}
+	b.Cookies = append(b.Cookies, add.Cookies...)
+	if add.Status != 0 {
+		b.Status = add.Status
 	}
 }
@@ -0,0 +1,22 @@
+package service
+
+import (
+	v1 "github.com/go-kratos/kratos-layout/api/helloworld/v1"
+	"github.com/go-kratos/kratos-layout/pkg/errors"
+)
+
+// ErrorCatalog 错误信息模板，按照Accept-Language选择语言，{key}替换为错误的metadata
+var ErrorCatalog = errors.MustCatalog("en", map[string]map[string]string{
+	"en": {
+		v1.ErrorReason_USER_NOT_FOUND.String():    "user {name} not found",
+		v1.ErrorReason_GREETER_NOT_FOUND.String(): "greeter {id} not found",
+		v1.ErrorReason_INVALID_ARGUMENT.String():  "invalid argument",
+		v1.ErrorReason_UNAUTHORIZED.String():      "unauthorized",
+	},
+	"zh": {
+		v1.ErrorReason_USER_NOT_FOUND.String():    "用户{name}不存在",
+		v1.ErrorReason_GREETER_NOT_FOUND.String(): "问候{id}不存在",
+		v1.ErrorReason_INVALID_ARGUMENT.String():  "参数错误",
+		v1.ErrorReason_UNAUTHORIZED.String():      "未认证",
+	},
+})
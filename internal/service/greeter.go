@@ -2,31 +2,213 @@ package service
 
 import (
 	"context"
+	stderrors "errors"
+	"io"
 
+	commonv1 "github.com/go-kratos/kratos-layout/api/common/v1"
 	v1 "github.com/go-kratos/kratos-layout/api/helloworld/v1"
 	"github.com/go-kratos/kratos-layout/internal/biz"
-	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos-layout/pkg/errors"
+	"github.com/go-kratos/kratos-layout/pkg/fieldmask"
+	"github.com/go-kratos/kratos-layout/pkg/logger"
+	"github.com/go-kratos/kratos-layout/pkg/objectid"
 	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/grpc/metadata"
 )
 
 // GreeterService is a greeter service.
 type GreeterService struct {
 	v1.UnimplementedGreeterServer
 
-	uc  *biz.GreeterUsecase
-	log *log.Helper
+	uc     *biz.GreeterUsecase
+	logger log.Logger
 }
 
 // NewGreeterService new a greeter service.
 func NewGreeterService(uc *biz.GreeterUsecase, logger log.Logger) *GreeterService {
-	return &GreeterService{uc: uc, log: log.NewHelper(logger)}
+	return &GreeterService{uc: uc, logger: logger}
 }
 
 // SayHello implements helloworld.GreeterServer
 func (s *GreeterService) SayHello(ctx context.Context, in *v1.HelloRequest) (*v1.HelloReply, error) {
-	s.log.Infof("SayHello Received: %v", in.GetName())
+	logger.NewHelper(ctx, s.logger).Infof("SayHello Received: %v", in.GetName())
 	if in.GetName() == "error" {
-		return nil, errors.NotFound(v1.ErrorReason_USER_NOT_FOUND.String(), in.GetName())
+		return nil, errors.FromError(v1.ErrorUserNotFound("user %s not found", in.GetName())).
+			WithMetadata(map[string]string{"name": in.GetName()})
 	}
 	return &v1.HelloReply{Message: "Hello " + in.GetName()}, nil
 }
+
+// CreateGreeter implements helloworld.GreeterServer
+func (s *GreeterService) CreateGreeter(ctx context.Context, in *v1.CreateGreeterRequest) (*v1.GreeterReply, error) {
+	g := &biz.Greeter{Hello: in.GetHello()}
+	if err := s.uc.Create(ctx, g); err != nil {
+		return nil, s.error(err, "")
+	}
+	return greeterReply(g), nil
+}
+
+// GetGreeter implements helloworld.GreeterServer
+func (s *GreeterService) GetGreeter(ctx context.Context, in *v1.GetGreeterRequest) (*v1.GreeterReply, error) {
+	id, err := objectid.Parse(in.GetId())
+	if err != nil {
+		return nil, invalidArgument("id", err)
+	}
+	fields, err := greeterFields.Read(in.GetReadMask())
+	if err != nil {
+		return nil, invalidArgument("read_mask", err)
+	}
+	g, err := s.uc.Get(ctx, string(id), fields...)
+	if err != nil {
+		return nil, s.error(err, in.GetId())
+	}
+	return greeterReply(g), nil
+}
+
+// greeterFields GreeterReply字段->数据库字段，用于排序和field mask
+var greeterFields = fieldmask.MustNew(&v1.GreeterReply{}, map[string]fieldmask.Field{
+	"id":          {DB: "_id", Immutable: true},
+	"hello":       {DB: "hello"},
+	"create_time": {DB: "createTime", Immutable: true},
+	"update_time": {DB: "updateTime", Immutable: true},
+})
+
+// ListGreeters implements helloworld.GreeterServer
+func (s *GreeterService) ListGreeters(ctx context.Context, in *commonv1.ListRequest) (*v1.ListGreetersReply, error) {
+	req, err := in.TableRequest(greeterFields)
+	if err != nil {
+		return nil, invalidArgument("list", err)
+	}
+	greeters, count, err := s.uc.List(ctx, req)
+	if err != nil {
+		return nil, s.error(err, "")
+	}
+	reply := &v1.ListGreetersReply{Meta: commonv1.NewListResponse(req, count), Result: make([]*v1.GreeterReply, 0, len(greeters))}
+	for _, g := range greeters {
+		reply.Result = append(reply.Result, greeterReply(g))
+	}
+	return reply, nil
+}
+
+// UpdateGreeter implements helloworld.GreeterServer
+func (s *GreeterService) UpdateGreeter(ctx context.Context, in *v1.UpdateGreeterRequest) (*v1.GreeterReply, error) {
+	id, err := objectid.Parse(in.GetId())
+	if err != nil {
+		return nil, invalidArgument("id", err)
+	}
+	fields, err := greeterFields.Update(in.GetUpdateMask())
+	if err != nil {
+		return nil, invalidArgument("update_mask", err)
+	}
+	g, err := s.uc.Update(ctx, &biz.Greeter{ID: id, Hello: in.GetHello()}, fields)
+	if err != nil {
+		return nil, s.error(err, in.GetId())
+	}
+	return greeterReply(g), nil
+}
+
+// DeleteGreeter implements helloworld.GreeterServer
+func (s *GreeterService) DeleteGreeter(ctx context.Context, in *v1.DeleteGreeterRequest) (*v1.DeleteGreeterReply, error) {
+	id, err := objectid.Parse(in.GetId())
+	if err != nil {
+		return nil, invalidArgument("id", err)
+	}
+	if err := s.uc.Delete(ctx, string(id)); err != nil {
+		return nil, s.error(err, in.GetId())
+	}
+	return &v1.DeleteGreeterReply{}, nil
+}
+
+// WatchGreeters implements helloworld.GreeterServer
+func (s *GreeterService) WatchGreeters(in *v1.WatchGreetersRequest, stream v1.Greeter_WatchGreetersServer) error {
+	ctx := stream.Context()
+	watch := biz.GreeterWatch{ResumeToken: in.GetResumeToken()}
+	for _, t := range in.GetTypes() {
+		if t != v1.GreeterEvent_TYPE_UNSPECIFIED {
+			watch.Types = append(watch.Types, biz.GreeterEventType(t))
+		}
+	}
+	w, err := s.uc.Watch(ctx, watch)
+	if err != nil {
+		return s.error(err, "")
+	}
+	defer func() {
+		if err := w.Close(context.Background()); err != nil {
+			logger.NewHelper(ctx, s.logger).Errorf("关闭greeter订阅: %+v", err)
+		}
+	}()
+	// 订阅已经生效，先发送header，客户端不用等到第一个变更就能确认订阅成功
+	if err = stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	for {
+		e, err := w.Next(ctx)
+		if err != nil {
+			if stderrors.Is(err, io.EOF) {
+				return nil
+			}
+			return s.error(err, "")
+		}
+		if err = stream.Send(greeterEvent(e)); err != nil {
+			return err
+		}
+	}
+}
+
+// SayHelloStream implements helloworld.GreeterServer
+func (s *GreeterService) SayHelloStream(stream v1.Greeter_SayHelloStreamServer) error {
+	for {
+		in, err := stream.Recv()
+		if err != nil {
+			if stderrors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		reply, err := s.SayHello(stream.Context(), in)
+		if err != nil {
+			return err
+		}
+		if err = stream.Send(reply); err != nil {
+			return err
+		}
+	}
+}
+
+// error 把biz的错误转为api的错误
+func (s *GreeterService) error(err error, id string) error {
+	if stderrors.Is(err, biz.ErrGreeterNotFound) {
+		return errors.FromError(v1.ErrorGreeterNotFound("greeter %s not found", id)).
+			WithMetadata(map[string]string{"id": id})
+	}
+	if stderrors.Is(err, biz.ErrInvalidResumeToken) {
+		return invalidArgument("resume_token", err)
+	}
+	if stderrors.Is(err, objectid.ErrInvalid) {
+		return invalidArgument("id", err)
+	}
+	return err
+}
+
+// invalidArgument 错误原因记录在字段错误中，信息会被本地化
+func invalidArgument(field string, err error) error {
+	return errors.FromError(v1.ErrorInvalidArgument("%s", err.Error())).WithFieldViolation(field, err.Error())
+}
+
+// greeterReply field_mask没有选择的时间字段为空
+func greeterReply(g *biz.Greeter) *v1.GreeterReply {
+	return &v1.GreeterReply{
+		Id:         string(g.ID),
+		Hello:      g.Hello,
+		CreateTime: g.CreateTime.Timestamp(),
+		UpdateTime: g.UpdateTime.Timestamp(),
+	}
+}
+
+func greeterEvent(e *biz.GreeterEvent) *v1.GreeterEvent {
+	return &v1.GreeterEvent{
+		Type:        v1.GreeterEvent_Type(e.Type),
+		Greeter:     greeterReply(e.Greeter),
+		ResumeToken: e.ResumeToken,
+	}
+}
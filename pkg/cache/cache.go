@@ -0,0 +1,425 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/logger"
+	"github.com/go-kratos/kratos/v2/encoding"
+	_ "github.com/go-kratos/kratos/v2/encoding/json" // 默认codec
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNotFound Loader返回这个错误时缓存空结果，Fetch在NegativeTTL内直接返回这个错误
+var ErrNotFound = errors.New("cache: not found")
+
+// 缓存值的第一个字节
+const (
+	flagNotFound byte = iota
+	flagValue
+)
+
+// WithRequestCounter的result标签，和Stats的字段对应
+const (
+	ResultLocalHit    = "local_hit"
+	ResultRedisHit    = "redis_hit"
+	ResultNegativeHit = "negative_hit"
+	ResultMiss        = "miss"
+	ResultLoad        = "load"
+	ResultLoadError   = "load_error"
+	ResultRedisError  = "redis_error"
+)
+
+// Loader 缓存没有命中时加载数据，返回的值由Codec编码
+type Loader func(ctx context.Context) (interface{}, error)
+
+// Stats 命中统计
+type Stats struct {
+	LocalHits    uint64 // 进程内缓存命中
+	RedisHits    uint64 // redis命中
+	NegativeHits uint64 // 命中空结果，也计入LocalHits或RedisHits
+	Misses       uint64 // 没有命中，需要加载
+	Loads        uint64 // 实际调用Loader的次数，singleflight合并后小于Misses
+	LoadErrors   uint64 // Loader返回错误的次数，不包括ErrNotFound
+	RedisErrors  uint64 // 读写redis失败的次数，失败时直接加载
+}
+
+// Option Cache的选项
+type Option func(c *Cache)
+
+// WithPrefix redis key的前缀，默认"cache:"
+func WithPrefix(prefix string) Option {
+	return func(c *Cache) {
+		c.prefix = prefix
+	}
+}
+
+// WithTTL 默认过期时间，默认10分钟
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithJitter 过期时间随机增加[0, ttl*jitter)，避免同时过期，默认0.1
+func WithJitter(jitter float64) Option {
+	return func(c *Cache) {
+		c.jitter = jitter
+	}
+}
+
+// WithNegativeTTL 空结果的过期时间，默认1分钟，为0时不缓存空结果
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.negativeTTL = ttl
+	}
+}
+
+// WithCodec 值的编码，默认kratos的json，可以使用GobCodec或者encoding.GetCodec("proto")
+func WithCodec(codec encoding.Codec) Option {
+	return func(c *Cache) {
+		c.codec = codec
+	}
+}
+
+// WithLocal 在redis前增加进程内LRU缓存，size为最大数量，ttl为最长保存时间
+// 其他实例的失效通过redis pub/sub通知，通知丢失时最多保留ttl
+func WithLocal(size int, ttl time.Duration) Option {
+	return func(c *Cache) {
+		if size > 0 && ttl > 0 {
+			c.local = newLRU(size)
+			c.localTTL = ttl
+		}
+	}
+}
+
+// WithLoadTimeout Loader的超时时间，默认10秒
+// Loader使用第一个调用方ctx中的值，但是不受它的取消和超时影响，等待的调用方各自在自己的ctx结束时返回
+func WithLoadTimeout(timeout time.Duration) Option {
+	return func(c *Cache) {
+		c.loadTimeout = timeout
+	}
+}
+
+// WithRequestCounter 和Stats相同的统计，标签为result
+func WithRequestCounter(counter metrics.Counter) Option {
+	return func(c *Cache) {
+		c.requests = counter
+	}
+}
+
+// WithLogger 日志
+func WithLogger(l log.Logger) Option {
+	return func(c *Cache) {
+		c.logger = logger.Module(l, "cache")
+	}
+}
+
+// FetchOption Fetch的选项
+type FetchOption func(o *fetchOptions)
+
+type fetchOptions struct {
+	ttl  time.Duration
+	tags []string
+}
+
+// TTL 代替默认过期时间
+func TTL(ttl time.Duration) FetchOption {
+	return func(o *fetchOptions) {
+		o.ttl = ttl
+	}
+}
+
+// Tags 缓存的标签，InvalidateTags删除标签下的所有缓存
+func Tags(tags ...string) FetchOption {
+	return func(o *fetchOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// Cache cache-aside缓存，redis为共享缓存，可选进程内LRU缓存
+type Cache struct {
+	stats Stats // 原子操作，放在第一个字段保证对齐
+
+	rdb         redis.UniversalClient
+	prefix      string
+	ttl         time.Duration
+	jitter      float64
+	negativeTTL time.Duration
+	codec       encoding.Codec
+	local       *lru
+	localTTL    time.Duration
+	loadTimeout time.Duration
+	requests    metrics.Counter
+	logger      log.Logger // 按ctx带上trace_id/span_id
+	group       singleflight.Group
+	pubsub      *redis.PubSub
+}
+
+/*New 创建缓存，使用进程内缓存时订阅失效通知，需要调用Close
+参数:
+*	rdb 	redis.UniversalClient
+*	opts	...Option
+返回值:
+*	*Cache	*Cache
+*/
+func New(rdb redis.UniversalClient, opts ...Option) *Cache {
+	c := &Cache{
+		rdb:         rdb,
+		prefix:      "cache:",
+		ttl:         10 * time.Minute,
+		jitter:      0.1,
+		negativeTTL: time.Minute,
+		loadTimeout: 10 * time.Second,
+		codec:       encoding.GetCodec("json"),
+		logger:      logger.Module(log.DefaultLogger, "cache"),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	if c.local != nil {
+		c.pubsub = rdb.Subscribe(context.Background(), c.channel())
+		go c.subscribe(c.pubsub.Channel())
+	}
+	return c
+}
+
+// Close 停止订阅失效通知
+func (c *Cache) Close() error {
+	if c.pubsub != nil {
+		return c.pubsub.Close()
+	}
+	return nil
+}
+
+// Stats 命中统计的快照
+func (c *Cache) Stats() Stats {
+	return Stats{
+		LocalHits:    atomic.LoadUint64(&c.stats.LocalHits),
+		RedisHits:    atomic.LoadUint64(&c.stats.RedisHits),
+		NegativeHits: atomic.LoadUint64(&c.stats.NegativeHits),
+		Misses:       atomic.LoadUint64(&c.stats.Misses),
+		Loads:        atomic.LoadUint64(&c.stats.Loads),
+		LoadErrors:   atomic.LoadUint64(&c.stats.LoadErrors),
+		RedisErrors:  atomic.LoadUint64(&c.stats.RedisErrors),
+	}
+}
+
+/*Fetch 依次查询进程内缓存、redis，都没有命中时调用load，同一个key同时只有一个load，
+load不随ctx取消，ctx结束时Fetch直接返回ctx的错误
+参数:
+*	ctx 	context.Context
+*	key 	string			不包括前缀
+*	dest	interface{}		解码的目标，指针
+*	load	Loader			返回ErrNotFound时缓存空结果
+*	opts	...FetchOption
+返回值:
+*	error	error	空结果返回ErrNotFound，load的错误原样返回
+*/
+func (c *Cache) Fetch(ctx context.Context, key string, dest interface{}, load Loader, opts ...FetchOption) error {
+	o := fetchOptions{ttl: c.ttl}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	key = c.prefix + key
+	if c.local != nil {
+		if data, ok := c.local.get(key); ok {
+			c.count(&c.stats.LocalHits, ResultLocalHit)
+			return c.hit(data, dest)
+		}
+	}
+	data, err := c.rdb.Get(ctx, key).Bytes()
+	switch {
+	case err == nil:
+		c.count(&c.stats.RedisHits, ResultRedisHit)
+		ttl := o.ttl
+		if len(data) == 0 || data[0] == flagNotFound {
+			ttl = c.negativeTTL
+		}
+		c.setLocal(key, data, ttl)
+		return c.hit(data, dest)
+	case err != redis.Nil:
+		c.count(&c.stats.RedisErrors, ResultRedisError)
+		logger.NewHelper(ctx, c.logger).Errorf("查询缓存[%s]失败: %+v", key, err)
+	}
+	c.count(&c.stats.Misses, ResultMiss)
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		// 第一个调用方取消时其他调用方还在等待，不能使用它的ctx
+		ctx, cancel := context.WithTimeout(detachedContext{ctx}, c.loadTimeout)
+		defer cancel()
+		return c.load(ctx, key, load, o)
+	})
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case r := <-ch:
+		if r.Err != nil {
+			return r.Err
+		}
+		return c.decode(r.Val.([]byte), dest)
+	}
+}
+
+// detachedContext 保留ctx中的值，不继承取消和超时，Go 1.15没有context.WithoutCancel
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// count 更新Stats和WithRequestCounter
+func (c *Cache) count(field *uint64, result string) {
+	atomic.AddUint64(field, 1)
+	if c.requests != nil {
+		c.requests.With(result).Inc()
+	}
+}
+
+// load 加载并写入缓存，返回编码后的值
+func (c *Cache) load(ctx context.Context, key string, load Loader, o fetchOptions) ([]byte, error) {
+	c.count(&c.stats.Loads, ResultLoad)
+	v, err := load(ctx)
+	var data []byte
+	ttl := o.ttl
+	switch {
+	case errors.Is(err, ErrNotFound):
+		if c.negativeTTL <= 0 {
+			return nil, ErrNotFound
+		}
+		data, ttl = []byte{flagNotFound}, c.negativeTTL
+	case err != nil:
+		c.count(&c.stats.LoadErrors, ResultLoadError)
+		return nil, err
+	default:
+		encoded, err := c.codec.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data = append([]byte{flagValue}, encoded...)
+	}
+	ttl = c.withJitter(ttl)
+	pipe := c.rdb.TxPipeline()
+	pipe.Set(ctx, key, data, ttl)
+	for _, tag := range o.tags {
+		pipe.SAdd(ctx, c.tagKey(tag), key)
+		// 标签比缓存多保留一段时间，过期的key删除时不会出错
+		pipe.Expire(ctx, c.tagKey(tag), ttl+c.ttl)
+	}
+	if _, err = pipe.Exec(ctx); err != nil {
+		c.count(&c.stats.RedisErrors, ResultRedisError)
+		logger.NewHelper(ctx, c.logger).Errorf("写入缓存[%s]失败: %+v", key, err)
+	}
+	c.setLocal(key, data, ttl)
+	return data, nil
+}
+
+func (c *Cache) hit(data []byte, dest interface{}) error {
+	if len(data) == 0 || data[0] == flagNotFound {
+		c.count(&c.stats.NegativeHits, ResultNegativeHit)
+	}
+	return c.decode(data, dest)
+}
+
+func (c *Cache) decode(data []byte, dest interface{}) error {
+	if len(data) == 0 || data[0] == flagNotFound {
+		return ErrNotFound
+	}
+	return c.codec.Unmarshal(data[1:], dest)
+}
+
+func (c *Cache) withJitter(ttl time.Duration) time.Duration {
+	if c.jitter <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Int63n(int64(float64(ttl)*c.jitter)+1))
+}
+
+func (c *Cache) setLocal(key string, data []byte, ttl time.Duration) {
+	if c.local == nil {
+		return
+	}
+	if ttl > c.localTTL {
+		ttl = c.localTTL
+	}
+	c.local.set(key, data, ttl)
+}
+
+/*Delete 删除缓存，写数据后调用，其他实例的进程内缓存也会失效
+参数:
+*	ctx 	context.Context
+*	keys	...string		不包括前缀
+返回值:
+*	error	error
+*/
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	full := make([]string, 0, len(keys))
+	for _, key := range keys {
+		full = append(full, c.prefix+key)
+	}
+	return c.delete(ctx, full)
+}
+
+// InvalidateTags 删除标签下的所有缓存
+func (c *Cache) InvalidateTags(ctx context.Context, tags ...string) error {
+	var keys []string
+	for _, tag := range tags {
+		members, err := c.rdb.SMembers(ctx, c.tagKey(tag)).Result()
+		if err != nil {
+			return err
+		}
+		keys = append(keys, members...)
+		keys = append(keys, c.tagKey(tag))
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.delete(ctx, keys)
+}
+
+func (c *Cache) delete(ctx context.Context, keys []string) error {
+	if c.local != nil {
+		c.local.delete(keys...)
+	}
+	if err := c.rdb.Del(ctx, keys...).Err(); err != nil {
+		return err
+	}
+	if c.local != nil {
+		return c.rdb.Publish(ctx, c.channel(), strings.Join(keys, "\n")).Err()
+	}
+	return nil
+}
+
+func (c *Cache) tagKey(tag string) string {
+	return c.prefix + "tag:" + tag
+}
+
+func (c *Cache) channel() string {
+	return c.prefix + "invalidate"
+}
+
+// subscribe 其他实例删除缓存时删除进程内缓存
+func (c *Cache) subscribe(ch <-chan *redis.Message) {
+	for msg := range ch {
+		c.local.delete(strings.Split(msg.Payload, "\n")...)
+	}
+}
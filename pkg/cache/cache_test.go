@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	ID   int
+	Name string
+}
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return mr, rdb
+}
+
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+	mr, rdb := newTestRedis(t)
+	requests := &testCounter{values: map[string]float64{}}
+	c := New(rdb, WithCodec(GobCodec{}), WithTTL(time.Minute), WithJitter(0.5), WithRequestCounter(requests))
+
+	loads := 0
+	load := func(ctx context.Context) (interface{}, error) {
+		loads++
+		return item{ID: 1, Name: "kratos"}, nil
+	}
+	var got item
+	require.NoError(t, c.Fetch(ctx, "item:1", &got, load, Tags("items")))
+	require.Equal(t, item{ID: 1, Name: "kratos"}, got)
+	got = item{}
+	require.NoError(t, c.Fetch(ctx, "item:1", &got, load))
+	require.Equal(t, item{ID: 1, Name: "kratos"}, got)
+	require.Equal(t, 1, loads)
+
+	// 过期时间在[ttl, ttl*1.5]之间
+	ttl := mr.TTL("cache:item:1")
+	require.True(t, ttl >= time.Minute && ttl <= 90*time.Second, ttl)
+
+	// 空结果
+	notFound := func(ctx context.Context) (interface{}, error) {
+		loads++
+		return nil, ErrNotFound
+	}
+	require.ErrorIs(t, c.Fetch(ctx, "item:2", &got, notFound), ErrNotFound)
+	require.ErrorIs(t, c.Fetch(ctx, "item:2", &got, notFound), ErrNotFound)
+	require.Equal(t, 2, loads)
+
+	// 加载失败不缓存
+	failed := errors.New("db down")
+	require.Equal(t, failed, c.Fetch(ctx, "item:3", &got, func(ctx context.Context) (interface{}, error) { return nil, failed }))
+	require.False(t, mr.Exists("cache:item:3"))
+
+	require.NoError(t, c.InvalidateTags(ctx, "items"))
+	require.False(t, mr.Exists("cache:item:1"))
+	require.False(t, mr.Exists("cache:tag:items"))
+	require.NoError(t, c.Delete(ctx, "item:2"))
+	require.False(t, mr.Exists("cache:item:2"))
+
+	require.Equal(t, Stats{RedisHits: 2, NegativeHits: 1, Misses: 3, Loads: 3, LoadErrors: 1}, c.Stats())
+	require.Equal(t, map[string]float64{
+		ResultRedisHit: 2, ResultNegativeHit: 1, ResultMiss: 3, ResultLoad: 3, ResultLoadError: 1,
+	}, requests.values)
+}
+
+// testCounter 按result标签累计
+type testCounter struct {
+	values map[string]float64
+	label  string
+}
+
+func (c *testCounter) With(lvs ...string) metrics.Counter {
+	return &testCounter{values: c.values, label: lvs[0]}
+}
+
+func (c *testCounter) Inc() {
+	c.Add(1)
+}
+
+func (c *testCounter) Add(delta float64) {
+	c.values[c.label] += delta
+}
+
+func TestFetchCancel(t *testing.T) {
+	_, rdb := newTestRedis(t)
+	c := New(rdb)
+	release := make(chan struct{})
+	loadErr := make(chan error, 1)
+	load := func(ctx context.Context) (interface{}, error) {
+		<-release
+		loadErr <- ctx.Err()
+		return item{ID: 1}, nil
+	}
+
+	// 第一个调用方取消后直接返回，加载继续，等待的调用方得到结果
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		var got item
+		first <- c.Fetch(ctx, "item:1", &got, load)
+	}()
+	require.Eventually(t, func() bool { return c.Stats().Misses == 1 }, time.Second, time.Millisecond)
+	second := make(chan error, 1)
+	var got item
+	go func() { second <- c.Fetch(context.Background(), "item:1", &got, load) }()
+	require.Eventually(t, func() bool { return c.Stats().Misses == 2 }, time.Second, time.Millisecond)
+
+	cancel()
+	require.ErrorIs(t, <-first, context.Canceled)
+	close(release)
+	require.NoError(t, <-second)
+	require.Equal(t, 1, got.ID)
+	require.NoError(t, <-loadErr)
+
+	// 超过WithLoadTimeout时load的ctx结束
+	c = New(rdb, WithLoadTimeout(10*time.Millisecond))
+	require.ErrorIs(t, c.Fetch(context.Background(), "item:2", &got, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}), context.DeadlineExceeded)
+}
+
+func TestFetchSingleflight(t *testing.T) {
+	_, rdb := newTestRedis(t)
+	c := New(rdb)
+	var loads int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return item{ID: 1}, nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var got item
+			require.NoError(t, c.Fetch(context.Background(), "item:1", &got, load))
+			require.Equal(t, 1, got.ID)
+		}()
+	}
+	// 等待所有请求都进入加载
+	require.Eventually(t, func() bool { return c.Stats().Misses == 10 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.EqualValues(t, 1, loads)
+}
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	_, rdb := newTestRedis(t)
+	c1 := New(rdb, WithLocal(10, time.Minute))
+	c2 := New(rdb, WithLocal(10, time.Minute))
+	t.Cleanup(func() {
+		_ = c1.Close()
+		_ = c2.Close()
+	})
+
+	name := "v1"
+	load := func(ctx context.Context) (interface{}, error) { return item{Name: name}, nil }
+	var got item
+	require.NoError(t, c1.Fetch(ctx, "item", &got, load))
+	require.NoError(t, c2.Fetch(ctx, "item", &got, load))
+	require.NoError(t, c2.Fetch(ctx, "item", &got, load))
+	require.Equal(t, "v1", got.Name)
+	require.EqualValues(t, 1, c2.Stats().RedisHits)
+	require.EqualValues(t, 1, c2.Stats().LocalHits)
+
+	// c1删除后c2的进程内缓存通过pub/sub失效
+	name = "v2"
+	require.NoError(t, c1.Delete(ctx, "item"))
+	require.Eventually(t, func() bool { return c2.local.len() == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, c2.Fetch(ctx, "item", &got, load))
+	require.Equal(t, "v2", got.Name)
+}
+
+func TestLRU(t *testing.T) {
+	l := newLRU(2)
+	l.set("a", []byte("1"), time.Minute)
+	l.set("b", []byte("2"), time.Minute)
+	_, ok := l.get("a")
+	require.True(t, ok)
+	l.set("c", []byte("3"), time.Minute) // 淘汰最久没有使用的b
+	_, ok = l.get("b")
+	require.False(t, ok)
+	_, ok = l.get("a")
+	require.True(t, ok)
+
+	l.set("d", []byte("4"), -time.Second)
+	_, ok = l.get("d")
+	require.False(t, ok)
+	require.Equal(t, 1, l.len()) // d淘汰了a，过期后被删除
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+)
+
+// GobCodec 使用gob编码，保留tools.Time等实现了GobEncoder的类型的全部精度
+type GobCodec struct{}
+
+func (GobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (GobCodec) Name() string {
+	return "gob"
+}
+
+var _ encoding.Codec = GobCodec{}
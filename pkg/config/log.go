@@ -0,0 +1,13 @@
+package config
+
+import (
+	"github.com/go-kratos/kratos-layout/pkg/logger"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var helper = log.NewHelper(logger.Module(log.DefaultLogger, "config"))
+
+// SetLogger 设置config输出日志使用的logger，配置热加载的日志也会使用
+func SetLogger(l log.Logger) {
+	helper = log.NewHelper(logger.Module(l, "config"))
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+/* 配置文件中的敏感值引用，解码配置时解析
+*	${env:NAME}		读取环境变量NAME
+*	${file:/path}	读取文件内容，去掉首尾空白
+*	${enc:xxx}		AES-256-GCM密文(base64)，密钥来自环境变量 SecretKeyEnv
+ */
+
+const (
+	// SecretKeyEnv 解密${enc:...}使用的密钥所在的环境变量，值为base64编码的32字节密钥
+	SecretKeyEnv = "CONFIG_SECRET_KEY"
+
+	secretKeySize = 32
+)
+
+var secretPattern = regexp.MustCompile(`\$\{(env|file|enc):([^}]*)\}`)
+
+// SecretHookFunc 返回一个DecodeHookFunc，将字符串中的密文和引用替换为真实的值
+func SecretHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String {
+			return data, nil
+		}
+		// 命名的字符串类型也是String，不能直接断言为string
+		s := reflect.ValueOf(data).String()
+		if !strings.Contains(s, "${") {
+			return data, nil
+		}
+		return ResolveSecret(s)
+	}
+}
+
+/*ResolveSecret 解析字符串中所有的${kind:value}引用，不包含引用时原样返回
+参数:
+*	s	string
+返回值:
+*	string	string
+*	error	error
+*/
+func ResolveSecret(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var err error
+	result := secretPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if err != nil {
+			return ref
+		}
+		match := secretPattern.FindStringSubmatch(ref)
+		var value string
+		switch match[1] {
+		case "env":
+			var exist bool
+			if value, exist = os.LookupEnv(match[2]); !exist {
+				err = fmt.Errorf("环境变量[%s]不存在", match[2])
+			}
+		case "file":
+			var content []byte
+			if content, err = ioutil.ReadFile(match[2]); err != nil {
+				err = fmt.Errorf("读取密钥文件[%s]: %w", match[2], err)
+			}
+			value = strings.TrimSpace(string(content))
+		case "enc":
+			var key []byte
+			if key, err = SecretKeyFromEnv(); err == nil {
+				value, err = Decrypt(key, match[2])
+			}
+		}
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// SecretKeyFromEnv 从环境变量SecretKeyEnv中读取密钥
+func SecretKeyFromEnv() ([]byte, error) {
+	encoded, exist := os.LookupEnv(SecretKeyEnv)
+	if !exist {
+		return nil, fmt.Errorf("未设置环境变量[%s]", SecretKeyEnv)
+	}
+	return DecodeSecretKey(encoded)
+}
+
+// DecodeSecretKey 解码base64编码的密钥并校验长度
+func DecodeSecretKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("密钥不是合法的base64: %w", err)
+	}
+	if len(key) != secretKeySize {
+		return nil, fmt.Errorf("密钥长度必须为%d字节,实际为%d", secretKeySize, len(key))
+	}
+	return key, nil
+}
+
+// GenerateSecretKey 生成一个新的base64编码的随机密钥
+func GenerateSecretKey() (string, error) {
+	key := make([]byte, secretKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+/*Encrypt 使用AES-256-GCM加密，返回可以直接写入配置文件的${enc:...}
+参数:
+*	key      	[]byte		32字节密钥
+*	plaintext	string		明文
+返回值:
+*	string	string
+*	error	error
+*/
+func Encrypt(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return "${enc:" + base64.StdEncoding.EncodeToString(sealed) + "}", nil
+}
+
+/*Decrypt 解密Encrypt生成的密文
+参数:
+*	key       	[]byte		32字节密钥
+*	ciphertext	string		base64密文，可以带${enc:}包裹
+返回值:
+*	string	string
+*	error	error
+*/
+func Decrypt(key []byte, ciphertext string) (string, error) {
+	ciphertext = strings.TrimSuffix(strings.TrimPrefix(ciphertext, "${enc:"), "}")
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("密文不是合法的base64: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("密文长度不足")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("解密失败: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
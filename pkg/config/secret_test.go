@@ -0,0 +1,93 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	encoded, err := GenerateSecretKey()
+	require.NoError(t, err)
+	key, err := DecodeSecretKey(encoded)
+	require.NoError(t, err)
+
+	cipherText, err := Encrypt(key, "password123")
+	require.NoError(t, err)
+	plainText, err := Decrypt(key, cipherText)
+	require.NoError(t, err)
+	require.Equal(t, "password123", plainText)
+
+	other, err := GenerateSecretKey()
+	require.NoError(t, err)
+	otherKey, err := DecodeSecretKey(other)
+	require.NoError(t, err)
+	_, err = Decrypt(otherKey, cipherText)
+	require.Error(t, err)
+}
+
+func TestResolveSecret(t *testing.T) {
+	encoded, err := GenerateSecretKey()
+	require.NoError(t, err)
+	key, err := DecodeSecretKey(encoded)
+	require.NoError(t, err)
+	cipherText, err := Encrypt(key, "mongo-pass")
+	require.NoError(t, err)
+
+	file, err := ioutil.TempFile("", "secret")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString("file-pass\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	os.Setenv(SecretKeyEnv, encoded)
+	os.Setenv("TEST_REDIS_PASSWORD", "redis-pass")
+	defer os.Unsetenv(SecretKeyEnv)
+	defer os.Unsetenv("TEST_REDIS_PASSWORD")
+
+	testCases := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "plain", want: "plain"},
+		{in: "${env:TEST_REDIS_PASSWORD}", want: "redis-pass"},
+		{in: "redis://:${env:TEST_REDIS_PASSWORD}@127.0.0.1", want: "redis://:redis-pass@127.0.0.1"},
+		{in: "${file:" + file.Name() + "}", want: "file-pass"},
+		{in: cipherText, want: "mongo-pass"},
+		{in: "${env:TEST_NOT_EXIST}", err: true},
+		{in: "${enc:bm90LWVuY3J5cHRlZA==}", err: true},
+	}
+	for _, tc := range testCases {
+		got, err := ResolveSecret(tc.in)
+		if tc.err {
+			require.Error(t, err, tc.in)
+			continue
+		}
+		require.NoError(t, err, tc.in)
+		require.Equal(t, tc.want, got)
+	}
+}
+
+func TestSecretHookNamedString(t *testing.T) {
+	type password string
+	os.Setenv("TEST_NAMED_PASSWORD", "named-pass")
+	defer os.Unsetenv("TEST_NAMED_PASSWORD")
+
+	var target struct {
+		Password password
+		Plain    password
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{DecodeHook: SecretHookFunc(), Result: &target})
+	require.NoError(t, err)
+	require.NoError(t, decoder.Decode(map[string]interface{}{
+		"password": password("${env:TEST_NAMED_PASSWORD}"),
+		"plain":    password("plain"),
+	}))
+	require.Equal(t, password("named-pass"), target.Password)
+	require.Equal(t, password("plain"), target.Plain)
+}
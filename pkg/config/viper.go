@@ -4,13 +4,14 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"runtime/debug"
 	"strings"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/go-kratos/kratos-layout/pkg/decodehook"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
@@ -27,6 +28,19 @@ func Init(confPath string, prefix string) error {
 	return nil
 }
 
+// UnmarshalKey 将key对应的配置解码到rawVal，会解析${env:}/${file:}/${enc:}引用
+func UnmarshalKey(key string, rawVal interface{}) error {
+	return viper.UnmarshalKey(key, rawVal, viper.DecodeHook(DecodeHook()))
+}
+
+// DecodeHook 配置解码时使用的hook，先解析引用再做类型转换
+func DecodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		SecretHookFunc(),
+		decodehook.ProtoHookFunc(),
+	)
+}
+
 // initConfig init config from conf file
 func initConfig(confPath string, prefix string) error {
 	if confPath != "" {
@@ -47,7 +61,7 @@ func initConfig(confPath string, prefix string) error {
 	if viper.GetString("deploy.env") == "uat" {
 		os.Setenv("DEPLOY_ENV", "uat")
 	}
-	log.Printf("%s 启动, 部署环境: %s", prefix, os.Getenv("DEPLOY_ENV"))
+	helper.Infof("%s 启动, 部署环境: %s", prefix, os.Getenv("DEPLOY_ENV"))
 
 	return nil
 }
@@ -56,7 +70,7 @@ func initConfig(confPath string, prefix string) error {
 func watchConfig() {
 	viper.WatchConfig()
 	viper.OnConfigChange(func(e fsnotify.Event) {
-		log.Printf("Config file changed: %s", e.Name)
+		helper.Infof("Config file changed: %s", e.Name)
 	})
 }
 func IsDebug() bool {
@@ -74,7 +88,7 @@ func GuessTestDirPath() (string, error) {
 	ok := false
 	if IsDebug() {
 		for wd, _ := os.Getwd(); !ok; wd = filepath.Dir(wd) {
-			log.Printf("判断目录[%s]是否为主目录\n", wd)
+			helper.Infof("判断目录[%s]是否为主目录", wd)
 			file, err := os.Open(wd)
 			if err != nil {
 				return "", fmt.Errorf("定位主目录: %w", err)
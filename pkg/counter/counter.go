@@ -0,0 +1,652 @@
+package counter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/lock"
+	"github.com/go-kratos/kratos-layout/pkg/logger"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrInvalidMember DedupBitmap的member不是uint32
+var ErrInvalidMember = errors.New("counter: bitmap member must be uint32")
+
+// Dedup 同一个member对同一个计数只计一次的方式
+type Dedup int
+
+const (
+	DedupNone   Dedup = iota // 不去重
+	DedupSet                 // set保存member，适用于任意字符串
+	DedupBitmap              // bitmap保存member，member为数字id时占用更少内存
+)
+
+// Store 计数的持久化，Flush定期写入增量，redis中没有基数时从Store加载
+type Store interface {
+	// Apply 把增量加到持久化的计数上，调用可能重复(写入成功但删除redis中的增量失败)
+	Apply(ctx context.Context, name string, deltas map[string]int64) error
+	// Load 持久化的计数，没有的id可以不返回
+	Load(ctx context.Context, name string, ids []string) (map[string]int64, error)
+}
+
+// Rank 排行榜的一项
+type Rank struct {
+	ID    string
+	Score int64
+}
+
+// Option Counter的选项
+type Option func(c *Counter)
+
+// WithPrefix redis key的前缀，默认"counter:"
+func WithPrefix(prefix string) Option {
+	return func(c *Counter) {
+		c.prefix = prefix
+	}
+}
+
+// WithShards 每个计数拆分成n个key，热点计数的写入分散到多个key(redis cluster时分散到多个节点)，默认1
+func WithShards(n int) Option {
+	return func(c *Counter) {
+		if n > 0 {
+			c.shards = n
+		}
+	}
+}
+
+// WithDedup 去重方式，默认DedupNone
+func WithDedup(dedup Dedup) Option {
+	return func(c *Counter) {
+		c.dedup = dedup
+	}
+}
+
+// WithLeaderboard 使用sorted set维护排行榜
+// bucket为0时为总榜；否则按bucket分段，只保留最近buckets段，Top返回这段时间内的增量排行，旧的增量自然衰减
+func WithLeaderboard(bucket time.Duration, buckets int) Option {
+	return func(c *Counter) {
+		c.leaderboard = true
+		c.bucket = bucket
+		c.buckets = buckets
+	}
+}
+
+// WithStore 持久化，没有Store时计数只保存在redis
+func WithStore(store Store) Option {
+	return func(c *Counter) {
+		c.store = store
+	}
+}
+
+// WithLogger 日志
+func WithLogger(l log.Logger) Option {
+	return func(c *Counter) {
+		c.log = log.NewHelper(logger.Module(l, "counter"))
+	}
+}
+
+/*Counter 基于redis的计数，计数值为基数加上各分片的增量
+基数在第一次读取时由Store中的计数减去已经写入Store的增量得到，redis数据丢失后也可以恢复
+同一个分片的key使用相同的hash tag，去重和增量在一个脚本中完成，cluster中也是原子的
+redis的key:
+*	{prefix}{name}:{id}:base               	基数
+*	{prefix}{{name}:{shard}}:{id}          	分片的增量
+*	{prefix}{{name}:{shard}}:{id}:members  	去重，member固定对应一个分片
+*	{prefix}{{name}:{shard}}:delta         	等待写入Store的增量，hash
+*	{prefix}{{name}}:top[:{bucket}]        	排行榜
+*/
+type Counter struct {
+	rdb         redis.UniversalClient
+	name        string
+	prefix      string
+	shards      int
+	dedup       Dedup
+	leaderboard bool
+	bucket      time.Duration
+	buckets     int
+	store       Store
+	log         *log.Helper
+}
+
+/*New 创建计数
+参数:
+*	rdb 	redis.UniversalClient
+*	name	string		计数的名字，例如"article:like"
+*	opts	...Option
+返回值:
+*	*Counter	*Counter
+*/
+func New(rdb redis.UniversalClient, name string, opts ...Option) *Counter {
+	c := &Counter{
+		rdb:    rdb,
+		name:   name,
+		prefix: "counter:",
+		shards: 1,
+		log:    log.NewHelper(logger.Module(log.DefaultLogger, "counter")),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Name 计数的名字
+func (c *Counter) Name() string {
+	return c.name
+}
+
+func (c *Counter) key(id, suffix string) string {
+	return c.prefix + c.name + ":" + id + ":" + suffix
+}
+
+func (c *Counter) shardKey(id string, shard int) string {
+	return fmt.Sprintf("%s{%s:%d}:%s", c.prefix, c.name, shard, id)
+}
+
+func (c *Counter) membersKey(id string, shard int) string {
+	return c.shardKey(id, shard) + ":members"
+}
+
+// deltaKey 增量和正在写入的增量使用相同的hash tag，cluster中可以rename
+func (c *Counter) deltaKey(shard int) string {
+	return fmt.Sprintf("%s{%s:%d}:delta", c.prefix, c.name, shard)
+}
+
+func (c *Counter) flushingKey(shard int) string {
+	return fmt.Sprintf("%s{%s:%d}:flushing", c.prefix, c.name, shard)
+}
+
+func (c *Counter) topKey(bucket int64) string {
+	if c.bucket <= 0 {
+		return c.prefix + "{" + c.name + "}:top"
+	}
+	return c.prefix + "{" + c.name + "}:top:" + strconv.FormatInt(bucket, 10)
+}
+
+func (c *Counter) currentBucket() int64 {
+	if c.bucket <= 0 {
+		return 0
+	}
+	return time.Now().UnixNano() / int64(c.bucket)
+}
+
+// addScript 去重和增量在一个脚本中，member没有变化时不计数
+// KEYS[1]分片的增量 KEYS[2]等待写入Store的增量 KEYS[3]去重
+// ARGV[1]增量 ARGV[2]id ARGV[3]去重方式 ARGV[4]member或者bit的偏移 ARGV[5]是否记录等待写入的增量
+var addScript = redis.NewScript(`
+local delta = tonumber(ARGV[1])
+if ARGV[3] == 'set' then
+	local n
+	if delta > 0 then
+		n = redis.call('SADD', KEYS[3], ARGV[4])
+	else
+		n = redis.call('SREM', KEYS[3], ARGV[4])
+	end
+	if n == 0 then
+		return 0
+	end
+elseif ARGV[3] == 'bitmap' then
+	local bit = 0
+	if delta > 0 then
+		bit = 1
+	end
+	if redis.call('SETBIT', KEYS[3], ARGV[4], bit) == bit then
+		return 0
+	end
+end
+redis.call('INCRBY', KEYS[1], delta)
+if ARGV[5] == '1' then
+	redis.call('HINCRBY', KEYS[2], ARGV[2], delta)
+end
+return 1
+`)
+
+/*Add 计数加delta，去重和计数是原子的；排行榜随后更新，失败时只记录日志，总榜由Reconcile校正
+参数:
+*	ctx   	context.Context
+*	id    	string		计数的对象，例如文章id
+*	member	string		去重的成员，例如用户id，为空时不去重
+*	delta 	int64		去重时大于0为加入，小于0为退出
+返回值:
+*	bool	bool	去重时member已经加入或者没有加入过返回false，计数不变
+*	error	error	返回错误时计数没有改变
+*/
+func (c *Counter) Add(ctx context.Context, id, member string, delta int64) (bool, error) {
+	if delta == 0 {
+		return false, nil
+	}
+	shard := rand.Intn(c.shards)
+	mode, arg := "none", ""
+	if member != "" && c.dedup != DedupNone {
+		var err error
+		if shard, arg, err = c.memberShard(member); err != nil {
+			return false, err
+		}
+		mode = "set"
+		if c.dedup == DedupBitmap {
+			mode = "bitmap"
+		}
+	}
+	store := "0"
+	if c.store != nil {
+		store = "1"
+	}
+	keys := []string{c.shardKey(id, shard), c.deltaKey(shard), c.membersKey(id, shard)}
+	changed, err := addScript.Run(ctx, c.rdb, keys, delta, id, mode, arg, store).Int()
+	if err != nil {
+		return false, fmt.Errorf("计数[%s:%s]: %w", c.name, id, err)
+	}
+	if changed == 0 {
+		return false, nil
+	}
+	if c.leaderboard {
+		top := c.topKey(c.currentBucket())
+		pipe := c.rdb.Pipeline()
+		pipe.ZIncrBy(ctx, top, float64(delta), id)
+		if c.bucket > 0 {
+			pipe.Expire(ctx, top, c.bucket*time.Duration(c.buckets+1))
+		}
+		if _, err = pipe.Exec(ctx); err != nil {
+			c.log.Errorf("更新排行榜[%s:%s]失败: %+v", c.name, id, err)
+		}
+	}
+	return true, nil
+}
+
+// memberShard 去重时member固定对应一个分片，bitmap的偏移按分片数缩小，返回分片和脚本中使用的member
+func (c *Counter) memberShard(member string) (int, string, error) {
+	if c.dedup == DedupBitmap {
+		offset, err := strconv.ParseUint(member, 10, 32)
+		if err != nil {
+			return 0, "", ErrInvalidMember
+		}
+		shards := uint64(c.shards)
+		return int(offset % shards), strconv.FormatUint(offset/shards, 10), nil
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(member))
+	return int(h.Sum32() % uint32(c.shards)), member, nil
+}
+
+// IsMember member是否已经加入
+func (c *Counter) IsMember(ctx context.Context, id, member string) (bool, error) {
+	shard, arg, err := c.memberShard(member)
+	if err != nil {
+		return false, err
+	}
+	key := c.membersKey(id, shard)
+	if c.dedup == DedupBitmap {
+		offset, _ := strconv.ParseInt(arg, 10, 64)
+		bit, err := c.rdb.GetBit(ctx, key, offset).Result()
+		return bit == 1, err
+	}
+	return c.rdb.SIsMember(ctx, key, arg).Result()
+}
+
+/*Get 批量查询计数，没有基数的id从Store恢复基数
+参数:
+*	ctx	context.Context
+*	ids	...string
+返回值:
+*	map[string]int64	map[string]int64	包含全部ids，没有计数的为0
+*	error           	error
+*/
+func (c *Counter) Get(ctx context.Context, ids ...string) (map[string]int64, error) {
+	bases, sums, err := c.read(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, id := range ids {
+		if _, ok := bases[id]; !ok && c.store != nil {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		seeded, err := c.bases(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		pipe := c.rdb.Pipeline()
+		cmds := make(map[string]*redis.BoolCmd, len(seeded))
+		for id, base := range seeded {
+			cmds[id] = pipe.SetNX(ctx, c.key(id, "base"), base, 0)
+		}
+		if _, err = pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf("恢复计数[%s]: %w", c.name, err)
+		}
+		// 同时有其他实例恢复时以先写入的为准
+		retry := make([]string, 0)
+		for id, cmd := range cmds {
+			if cmd.Val() {
+				bases[id] = seeded[id]
+			} else {
+				retry = append(retry, id)
+			}
+		}
+		if len(retry) > 0 {
+			again, _, err := c.read(ctx, retry)
+			if err != nil {
+				return nil, err
+			}
+			for id, base := range again {
+				bases[id] = base
+			}
+		}
+	}
+	result := make(map[string]int64, len(ids))
+	for _, id := range ids {
+		result[id] = bases[id] + sums[id]
+	}
+	return result, nil
+}
+
+// read 读取基数和分片增量之和，没有基数的id不在bases中
+func (c *Counter) read(ctx context.Context, ids []string) (bases, sums map[string]int64, err error) {
+	pipe := c.rdb.Pipeline()
+	baseCmds := make([]*redis.StringCmd, len(ids))
+	shardCmds := make([][]*redis.StringCmd, len(ids))
+	for i, id := range ids {
+		baseCmds[i] = pipe.Get(ctx, c.key(id, "base"))
+		shardCmds[i] = make([]*redis.StringCmd, c.shards)
+		for shard := 0; shard < c.shards; shard++ {
+			shardCmds[i][shard] = pipe.Get(ctx, c.shardKey(id, shard))
+		}
+	}
+	if _, err = pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, nil, fmt.Errorf("查询计数[%s]: %w", c.name, err)
+	}
+	bases = make(map[string]int64, len(ids))
+	sums = make(map[string]int64, len(ids))
+	for i, id := range ids {
+		if v, err := baseCmds[i].Int64(); err == nil {
+			bases[id] = v
+		}
+		for _, cmd := range shardCmds[i] {
+			v, _ := cmd.Int64()
+			sums[id] += v
+		}
+	}
+	return bases, sums, nil
+}
+
+// flushedScript 同一个分片的计数和等待写入的增量在一个脚本中读取，和Add互斥
+// 返回每个id已经写入Store的增量(计数-等待写入的增量)，以及是否正在写入
+// KEYS[1]等待写入的增量 KEYS[2]正在写入的增量 KEYS[3...]每个id的计数，ARGV为id
+var flushedScript = redis.NewScript(`
+local result = {}
+for i, id in ipairs(ARGV) do
+	local n = tonumber(redis.call('GET', KEYS[i + 2]) or '0')
+	n = n - tonumber(redis.call('HGET', KEYS[1], id) or '0')
+	local flushing = redis.call('HGET', KEYS[2], id)
+	local busy = 0
+	if flushing then
+		n = n - tonumber(flushing)
+		busy = 1
+	end
+	result[#result + 1] = tostring(n)
+	result[#result + 1] = busy
+end
+return result
+`)
+
+// seedAttempts 恢复基数时和Flush冲突的最大重试次数
+const seedAttempts = 5
+
+// flushed 已经写入Store的增量，busy为正在写入Store的id
+func (c *Counter) flushed(ctx context.Context, ids []string) (flushed map[string]int64, busy map[string]bool, err error) {
+	flushed = make(map[string]int64, len(ids))
+	busy = make(map[string]bool)
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	for shard := 0; shard < c.shards; shard++ {
+		keys := make([]string, 0, len(ids)+2)
+		keys = append(keys, c.deltaKey(shard), c.flushingKey(shard))
+		for _, id := range ids {
+			keys = append(keys, c.shardKey(id, shard))
+		}
+		v, err := flushedScript.Run(ctx, c.rdb, keys, args...).Result()
+		if err != nil {
+			return nil, nil, fmt.Errorf("查询计数[%s]的增量: %w", c.name, err)
+		}
+		values, _ := v.([]interface{})
+		if len(values) != 2*len(ids) {
+			return nil, nil, fmt.Errorf("查询计数[%s]的增量: 错误的结果%v", c.name, v)
+		}
+		for i, id := range ids {
+			s, _ := values[2*i].(string)
+			n, _ := strconv.ParseInt(s, 10, 64)
+			flushed[id] += n
+			if v, _ := values[2*i+1].(int64); v == 1 {
+				busy[id] = true
+			}
+		}
+	}
+	return flushed, busy, nil
+}
+
+/*bases 计算基数: Store中的计数包含已经写入的增量，基数=Store中的计数-已经写入的增量
+Store和redis不能一起读取，读取Store前后已经写入的增量不同或者正在写入时，说明和Flush冲突，重新读取
+参数:
+*	ctx	context.Context
+*	ids	[]string
+返回值:
+*	map[string]int64	map[string]int64
+*	error           	error
+*/
+func (c *Counter) bases(ctx context.Context, ids []string) (map[string]int64, error) {
+	for attempt := 1; ; attempt++ {
+		before, busy, err := c.flushed(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		stored, err := c.store.Load(ctx, c.name, ids)
+		if err != nil {
+			return nil, fmt.Errorf("加载计数[%s]: %w", c.name, err)
+		}
+		after, busyAfter, err := c.flushed(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		consistent := len(busy) == 0 && len(busyAfter) == 0
+		for _, id := range ids {
+			consistent = consistent && before[id] == after[id]
+		}
+		// 写入Store一直失败时正在写入的增量没有写入，按没有写入计算
+		if consistent || attempt == seedAttempts {
+			if !consistent {
+				c.log.Warnf("恢复计数[%s]时增量正在写入，基数可能不准确，可以之后Reconcile: %v", c.name, ids)
+			}
+			bases := make(map[string]int64, len(ids))
+			for _, id := range ids {
+				bases[id] = stored[id] - after[id]
+			}
+			return bases, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Duration(attempt) * 10 * time.Millisecond):
+		}
+	}
+}
+
+/*Reconcile 以Store为准重新计算基数，总榜同时更新为最新的计数
+用于redis从旧的快照恢复或者手动修改了Store之后校正，分段排行榜无法恢复
+参数:
+*	ctx	context.Context
+*	ids	...string
+返回值:
+*	error	error
+*/
+func (c *Counter) Reconcile(ctx context.Context, ids ...string) error {
+	if c.store == nil || len(ids) == 0 {
+		return nil
+	}
+	bases, err := c.bases(ctx, ids)
+	if err != nil {
+		return err
+	}
+	_, sums, err := c.read(ctx, ids)
+	if err != nil {
+		return err
+	}
+	pipe := c.rdb.Pipeline()
+	for id, base := range bases {
+		pipe.Set(ctx, c.key(id, "base"), base, 0)
+		if c.leaderboard && c.bucket <= 0 {
+			pipe.ZAdd(ctx, c.topKey(0), &redis.Z{Score: float64(base + sums[id]), Member: id})
+		}
+	}
+	if _, err = pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("校正计数[%s]: %w", c.name, err)
+	}
+	return nil
+}
+
+/*Top 排行榜前n名
+参数:
+*	ctx	context.Context
+*	n  	int
+返回值:
+*	[]Rank	[]Rank
+*	error 	error
+*/
+func (c *Counter) Top(ctx context.Context, n int) ([]Rank, error) {
+	if !c.leaderboard || n <= 0 {
+		return nil, nil
+	}
+	key := c.topKey(0)
+	if c.bucket > 0 {
+		current := c.currentBucket()
+		keys := make([]string, 0, c.buckets)
+		for i := 0; i < c.buckets; i++ {
+			keys = append(keys, c.topKey(current-int64(i)))
+		}
+		key = c.prefix + "{" + c.name + "}:top:union"
+		pipe := c.rdb.TxPipeline()
+		pipe.ZUnionStore(ctx, key, &redis.ZStore{Keys: keys})
+		pipe.Expire(ctx, key, time.Minute)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf("合并排行榜[%s]: %w", c.name, err)
+		}
+	}
+	zs, err := c.rdb.ZRevRangeWithScores(ctx, key, 0, int64(n-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("查询排行榜[%s]: %w", c.name, err)
+	}
+	ranks := make([]Rank, 0, len(zs))
+	for _, z := range zs {
+		ranks = append(ranks, Rank{ID: z.Member.(string), Score: int64(z.Score)})
+	}
+	return ranks, nil
+}
+
+/*Flush 把增量写入Store，同一时间只能有一个实例执行，一般通过lock.Election运行Run
+写入失败时增量保留，下次继续写入
+参数:
+*	ctx	context.Context
+返回值:
+*	error	error
+*/
+func (c *Counter) Flush(ctx context.Context) error {
+	if c.store == nil {
+		return nil
+	}
+	for shard := 0; shard < c.shards; shard++ {
+		if err := c.flushShard(ctx, shard); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushShard 把增量rename为正在写入，写入Store后删除；上次没有写完的先写完
+func (c *Counter) flushShard(ctx context.Context, shard int) error {
+	src, dst := c.deltaKey(shard), c.flushingKey(shard)
+	n, err := c.rdb.Exists(ctx, dst).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		renamed, err := c.rdb.RenameNX(ctx, src, dst).Result()
+		if err != nil {
+			if strings.Contains(err.Error(), "no such key") {
+				return nil
+			}
+			return fmt.Errorf("计数[%s]的增量: %w", c.name, err)
+		}
+		if !renamed {
+			return nil
+		}
+	}
+	values, err := c.rdb.HGetAll(ctx, dst).Result()
+	if err != nil {
+		return err
+	}
+	deltas := make(map[string]int64, len(values))
+	for id, v := range values {
+		delta, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.log.Errorf("计数[%s:%s]的增量无效: %s", c.name, id, v)
+			continue
+		}
+		if delta != 0 {
+			deltas[id] = delta
+		}
+	}
+	if len(deltas) > 0 {
+		if err = c.store.Apply(ctx, c.name, deltas); err != nil {
+			return fmt.Errorf("写入计数[%s]: %w", c.name, err)
+		}
+	}
+	return c.rdb.Del(ctx, dst).Err()
+}
+
+/*Run 每隔interval调用Flush，ctx结束时最后写入一次，可以作为lock.Election的fn
+参数:
+*	ctx     	context.Context
+*	interval	time.Duration
+*	counters	...*Counter
+返回值:
+*	error	error
+*/
+func Run(ctx context.Context, interval time.Duration, counters ...*Counter) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	flush := func(ctx context.Context) {
+		for _, c := range counters {
+			if err := c.Flush(ctx); err != nil {
+				c.log.Errorf("%+v", err)
+			}
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			// 失去锁时其他实例可能已经开始写入
+			if lk, ok := lock.FromContext(ctx); ok {
+				select {
+				case <-lk.Done():
+					return nil
+				default:
+				}
+			}
+			final, cancel := context.WithTimeout(context.Background(), interval)
+			flush(final)
+			cancel()
+			return nil
+		case <-ticker.C:
+			flush(ctx)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package counter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore 测试用的Store
+type memoryStore struct {
+	mu     sync.Mutex
+	counts map[string]int64
+	fail   bool
+	onLoad func() // Load读取之后调用
+}
+
+func (s *memoryStore) Apply(_ context.Context, name string, deltas map[string]int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("store down")
+	}
+	for id, delta := range deltas {
+		s.counts[name+":"+id] += delta
+	}
+	return nil
+}
+
+func (s *memoryStore) Load(_ context.Context, name string, ids []string) (map[string]int64, error) {
+	s.mu.Lock()
+	result := make(map[string]int64, len(ids))
+	for _, id := range ids {
+		if v, ok := s.counts[name+":"+id]; ok {
+			result[id] = v
+		}
+	}
+	onLoad := s.onLoad
+	s.mu.Unlock()
+	if onLoad != nil {
+		onLoad()
+	}
+	return result, nil
+}
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return mr, rdb
+}
+
+func TestCounter(t *testing.T) {
+	ctx := context.Background()
+	mr, rdb := newTestRedis(t)
+	store := &memoryStore{counts: map[string]int64{"like:1": 100}}
+	c := New(rdb, "like", WithShards(4), WithDedup(DedupSet), WithStore(store), WithLeaderboard(0, 0))
+
+	// 去重
+	for _, user := range []string{"a", "b", "a"} {
+		_, err := c.Add(ctx, "1", user, 1)
+		require.NoError(t, err)
+	}
+	changed, err := c.Add(ctx, "2", "a", 1)
+	require.NoError(t, err)
+	require.True(t, changed)
+	changed, err = c.Add(ctx, "2", "c", -1)
+	require.NoError(t, err)
+	require.False(t, changed)
+	ok, err := c.IsMember(ctx, "1", "b")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// 基数从Store加载
+	counts, err := c.Get(ctx, "1", "2", "3")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"1": 102, "2": 1, "3": 0}, counts)
+
+	// 写入失败时保留增量
+	store.fail = true
+	require.Error(t, c.Flush(ctx))
+	store.fail = false
+	require.NoError(t, c.Flush(ctx))
+	require.Equal(t, int64(102), store.counts["like:1"])
+	require.Equal(t, int64(1), store.counts["like:2"])
+	require.NoError(t, c.Flush(ctx))
+	require.Equal(t, int64(102), store.counts["like:1"])
+
+	_, err = c.Add(ctx, "1", "c", 1)
+	require.NoError(t, err)
+	counts, err = c.Get(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, int64(103), counts["1"])
+
+	ranks, err := c.Top(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []Rank{{ID: "1", Score: 3}}, ranks)
+
+	// redis丢失基数，部分增量已经写入Store，恢复后不重复计算
+	mr.Del(c.key("1", "base"))
+	counts, err = c.Get(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, int64(103), counts["1"])
+
+	// redis数据全部丢失，恢复为Store中的计数，总榜通过Reconcile恢复
+	require.NoError(t, c.Flush(ctx))
+	mr.FlushAll()
+	counts, err = c.Get(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, int64(103), counts["1"])
+	require.NoError(t, c.Reconcile(ctx, "1", "2"))
+	ranks, err = c.Top(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, []Rank{{ID: "1", Score: 103}, {ID: "2", Score: 1}}, ranks)
+}
+
+func TestBitmapWindow(t *testing.T) {
+	ctx := context.Background()
+	_, rdb := newTestRedis(t)
+	c := New(rdb, "view", WithDedup(DedupBitmap), WithLeaderboard(time.Hour, 24))
+
+	_, err := c.Add(ctx, "1", "user", 1)
+	require.ErrorIs(t, err, ErrInvalidMember)
+	for _, user := range []string{"7", "7", "9"} {
+		_, err = c.Add(ctx, "1", user, 1)
+		require.NoError(t, err)
+	}
+	_, err = c.Add(ctx, "2", "7", 1)
+	require.NoError(t, err)
+	changed, err := c.Add(ctx, "1", "9", -1)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	// 没有Store时只在redis中计数
+	counts, err := c.Get(ctx, "1", "2")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"1": 1, "2": 1}, counts)
+
+	// 旧的分段不计入排行
+	require.NoError(t, rdb.ZIncrBy(ctx, c.topKey(c.currentBucket()-24), 100, "3").Err())
+	require.NoError(t, rdb.ZIncrBy(ctx, c.topKey(c.currentBucket()-23), 5, "2").Err())
+	ranks, err := c.Top(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, []Rank{{ID: "2", Score: 6}, {ID: "1", Score: 1}}, ranks)
+}
+
+func TestSeedDuringFlush(t *testing.T) {
+	ctx := context.Background()
+	_, rdb := newTestRedis(t)
+	store := &memoryStore{counts: map[string]int64{"like:1": 100}}
+	c := New(rdb, "like", WithShards(2), WithStore(store))
+	for i := 0; i < 5; i++ {
+		_, err := c.Add(ctx, "1", "", 1)
+		require.NoError(t, err)
+	}
+
+	// 读取Store之后、再次读取增量之前增量写入了Store，需要重新读取
+	var once sync.Once
+	store.onLoad = func() {
+		once.Do(func() { require.NoError(t, c.Flush(ctx)) })
+	}
+	counts, err := c.Get(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, int64(105), counts["1"])
+	require.Equal(t, int64(105), store.counts["like:1"])
+}
+
+func TestDedupShards(t *testing.T) {
+	ctx := context.Background()
+	for _, dedup := range []Dedup{DedupSet, DedupBitmap} {
+		_, rdb := newTestRedis(t)
+		c := New(rdb, "like", WithShards(4), WithDedup(dedup))
+		for _, user := range []string{"1", "2", "3", "4", "5", "1", "2"} {
+			_, err := c.Add(ctx, "a", user, 1)
+			require.NoError(t, err)
+		}
+		changed, err := c.Add(ctx, "a", "5", -1)
+		require.NoError(t, err)
+		require.True(t, changed)
+		for user, expect := range map[string]bool{"1": true, "4": true, "5": false, "6": false} {
+			ok, err := c.IsMember(ctx, "a", user)
+			require.NoError(t, err)
+			require.Equal(t, expect, ok, user)
+		}
+		counts, err := c.Get(ctx, "a")
+		require.NoError(t, err)
+		require.Equal(t, int64(4), counts["a"])
+	}
+}
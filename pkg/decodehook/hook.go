@@ -1,31 +1,77 @@
 package decodehook
 
 import (
-	"github.com/golang/protobuf/ptypes/duration"
-	"github.com/mitchellh/mapstructure"
-	"google.golang.org/protobuf/types/known/durationpb"
 	"reflect"
 	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
+var (
+	durationType      = reflect.TypeOf(time.Duration(0))
+	protoDurationType = reflect.TypeOf(&durationpb.Duration{})
+)
+
+// ProtoHookFunc 组合了所有的hook，用于把配置解码到protobuf生成的结构体中
+func ProtoHookFunc() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		ProtoMessageHookFunc(),
+		StringToTimeDurationHookFunc(),
+		NumberToDurationHookFunc(),
+		StringToTimestampHookFunc(),
+		WrapperHookFunc(),
+		StringToEnumHookFunc(),
+		ByteSizeHookFunc(),
+	)
+}
+
 // StringToTimeDurationHookFunc returns a DecodeHookFunc that converts
-// strings to time.Duration.
+// strings to durationpb.Duration and time.Duration.
 func StringToTimeDurationHookFunc() mapstructure.DecodeHookFunc {
 	return func(
 		f reflect.Type,
 		t reflect.Type,
 		data interface{}) (interface{}, error) {
-		if f.Kind() != reflect.String {
+		if f == nil || f.Kind() != reflect.String {
 			return data, nil
 		}
-		if t != reflect.TypeOf(&duration.Duration{}) {
+		if t != protoDurationType && t != durationType {
 			return data, nil
 		}
-		// Convert it by parsing
-		d, err := time.ParseDuration(data.(string))
-		if err == nil {
-			return durationpb.New(d), nil
+		// Convert it by parsing, named string types are also reflect.String
+		d, err := time.ParseDuration(reflect.ValueOf(data).String())
+		if err != nil {
+			return data, err
+		}
+		if t == durationType {
+			return d, nil
+		}
+		return durationpb.New(d), nil
+	}
+}
+
+// NumberToDurationHookFunc returns a DecodeHookFunc that converts
+// numbers to durationpb.Duration, the number is seconds.
+func NumberToDurationHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if f == nil || t != protoDurationType {
+			return data, nil
+		}
+		var seconds float64
+		switch f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			seconds = float64(reflect.ValueOf(data).Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			seconds = float64(reflect.ValueOf(data).Uint())
+		case reflect.Float32, reflect.Float64:
+			seconds = reflect.ValueOf(data).Float()
+		default:
+			return data, nil
 		}
-		return data, err
+		return durationpb.New(time.Duration(seconds * float64(time.Second))), nil
 	}
 }
@@ -0,0 +1,146 @@
+package decodehook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func decode(t *testing.T, input interface{}, output interface{}) {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       ProtoHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           output,
+	})
+	require.NoError(t, err)
+	require.NoError(t, decoder.Decode(input))
+}
+
+func TestProtoHookFunc(t *testing.T) {
+	var result struct {
+		Timeout   *durationpb.Duration
+		Interval  *durationpb.Duration
+		Wait      time.Duration
+		StartAt   *timestamppb.Timestamp
+		Enabled   *wrapperspb.BoolValue
+		Retry     *wrapperspb.Int32Value
+		Name      *wrapperspb.StringValue
+		MaxSize   int64
+		BatchSize uint32
+	}
+	decode(t, map[string]interface{}{
+		"timeout":   "1.5s",
+		"interval":  30,
+		"wait":      "100ms",
+		"startAt":   "2021-06-01T08:00:00Z",
+		"enabled":   "true",
+		"retry":     "3",
+		"name":      "greeter",
+		"maxSize":   "64MiB",
+		"batchSize": "1KB",
+	}, &result)
+
+	require.Equal(t, 1500*time.Millisecond, result.Timeout.AsDuration())
+	require.Equal(t, 30*time.Second, result.Interval.AsDuration())
+	require.Equal(t, 100*time.Millisecond, result.Wait)
+	require.Equal(t, time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC), result.StartAt.AsTime())
+	require.True(t, result.Enabled.GetValue())
+	require.EqualValues(t, 3, result.Retry.GetValue())
+	require.Equal(t, "greeter", result.Name.GetValue())
+	require.EqualValues(t, 64<<20, result.MaxSize)
+	require.EqualValues(t, 1000, result.BatchSize)
+}
+
+// 命名的字符串类型，例如yaml解码得到的自定义类型
+type text string
+
+func TestNamedString(t *testing.T) {
+	var result struct {
+		Timeout *durationpb.Duration
+		Wait    time.Duration
+		StartAt *timestamppb.Timestamp
+		Type    descriptorpb.FieldDescriptorProto_Type
+		MaxSize int64
+	}
+	decode(t, map[string]interface{}{
+		"timeout": text("1.5s"),
+		"wait":    text("100ms"),
+		"startAt": text("2021-06-01T08:00:00Z"),
+		"type":    text("type_message"),
+		"maxSize": text("64MiB"),
+	}, &result)
+	require.Equal(t, 1500*time.Millisecond, result.Timeout.AsDuration())
+	require.Equal(t, 100*time.Millisecond, result.Wait)
+	require.Equal(t, time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC), result.StartAt.AsTime())
+	require.Equal(t, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, result.Type)
+	require.EqualValues(t, 64<<20, result.MaxSize)
+}
+
+func TestByteSizeOverflow(t *testing.T) {
+	var result struct {
+		Small int8
+		Batch uint16
+		Max   int32
+	}
+	for field, size := range map[string]string{"small": "1KB", "batch": "1MiB", "max": "4GiB"} {
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook:       ProtoHookFunc(),
+			WeaklyTypedInput: true,
+			Result:           &result,
+		})
+		require.NoError(t, err)
+		require.Error(t, decoder.Decode(map[string]interface{}{field: size}), field)
+	}
+	decode(t, map[string]interface{}{"small": "100B", "batch": "64KB", "max": "1GiB"}, &result)
+	require.EqualValues(t, 100, result.Small)
+	require.EqualValues(t, 64000, result.Batch)
+	require.EqualValues(t, 1<<30, result.Max)
+}
+
+func TestProtoMessageHookFunc(t *testing.T) {
+	result := &descriptorpb.FieldDescriptorProto{}
+	decode(t, map[string]interface{}{
+		"name":      "dial_timeout",
+		"json_name": "dialTimeout",
+		"type":      "type_message",
+		"label":     "LABEL_REPEATED",
+		"number":    5,
+	}, result)
+	require.True(t, proto.Equal(&descriptorpb.FieldDescriptorProto{
+		Name:     proto.String("dial_timeout"),
+		JsonName: proto.String("dialTimeout"),
+		Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+		Number:   proto.Int32(5),
+	}, result), result.String())
+}
+
+func TestParseByteSize(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want uint64
+		err  bool
+	}{
+		{in: "512B", want: 512},
+		{in: "1.5 GiB", want: 3 << 29},
+		{in: "2mb", want: 2e6},
+		{in: "10", err: true},
+		{in: "10PB", err: true},
+	}
+	for _, tc := range testCases {
+		got, err := ParseByteSize(tc.in)
+		if tc.err {
+			require.Error(t, err, tc.in)
+			continue
+		}
+		require.NoError(t, err, tc.in)
+		require.Equal(t, tc.want, got, tc.in)
+	}
+}
@@ -0,0 +1,203 @@
+package decodehook
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/tools"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var (
+	enumType         = reflect.TypeOf((*protoreflect.Enum)(nil)).Elem()
+	messageType      = reflect.TypeOf((*proto.Message)(nil)).Elem()
+	timeType         = reflect.TypeOf(time.Time{})
+	toolsTimeType    = reflect.TypeOf(tools.Time{})
+	protoTimeType    = reflect.TypeOf(&timestamppb.Timestamp{})
+	protoWrapperType = map[reflect.Type]func(interface{}) (interface{}, error){
+		reflect.TypeOf(&wrapperspb.BoolValue{}): func(data interface{}) (interface{}, error) {
+			v, err := cast.ToBoolE(data)
+			return wrapperspb.Bool(v), err
+		},
+		reflect.TypeOf(&wrapperspb.StringValue{}): func(data interface{}) (interface{}, error) {
+			v, err := cast.ToStringE(data)
+			return wrapperspb.String(v), err
+		},
+		reflect.TypeOf(&wrapperspb.BytesValue{}): func(data interface{}) (interface{}, error) {
+			v, err := cast.ToStringE(data)
+			return wrapperspb.Bytes([]byte(v)), err
+		},
+		reflect.TypeOf(&wrapperspb.Int32Value{}): func(data interface{}) (interface{}, error) {
+			v, err := cast.ToInt32E(data)
+			return wrapperspb.Int32(v), err
+		},
+		reflect.TypeOf(&wrapperspb.Int64Value{}): func(data interface{}) (interface{}, error) {
+			v, err := cast.ToInt64E(data)
+			return wrapperspb.Int64(v), err
+		},
+		reflect.TypeOf(&wrapperspb.UInt32Value{}): func(data interface{}) (interface{}, error) {
+			v, err := cast.ToUint32E(data)
+			return wrapperspb.UInt32(v), err
+		},
+		reflect.TypeOf(&wrapperspb.UInt64Value{}): func(data interface{}) (interface{}, error) {
+			v, err := cast.ToUint64E(data)
+			return wrapperspb.UInt64(v), err
+		},
+		reflect.TypeOf(&wrapperspb.FloatValue{}): func(data interface{}) (interface{}, error) {
+			v, err := cast.ToFloat32E(data)
+			return wrapperspb.Float(v), err
+		},
+		reflect.TypeOf(&wrapperspb.DoubleValue{}): func(data interface{}) (interface{}, error) {
+			v, err := cast.ToFloat64E(data)
+			return wrapperspb.Double(v), err
+		},
+	}
+)
+
+// StringToTimestampHookFunc returns a DecodeHookFunc that converts
+// strings accepted by tools.ParseTime, time.Time and unix seconds
+// to timestamppb.Timestamp.
+func StringToTimestampHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if f == nil || t != protoTimeType {
+			return data, nil
+		}
+		switch f {
+		case timeType:
+			return timestamppb.New(data.(time.Time)), nil
+		case toolsTimeType:
+			return data.(tools.Time).Timestamp(), nil
+		}
+		switch f.Kind() {
+		case reflect.String:
+			s := reflect.ValueOf(data).String()
+			parsed, err := tools.ParseTime(s)
+			if err != nil {
+				return data, fmt.Errorf("错误的时间[%s]: %w", s, err)
+			}
+			return parsed.Timestamp(), nil
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			return timestamppb.New(time.Unix(reflect.ValueOf(data).Int(), 0)), nil
+		}
+		return data, nil
+	}
+}
+
+// WrapperHookFunc returns a DecodeHookFunc that converts scalars to
+// wrapperspb types, so that nullable config fields can be written as plain values.
+func WrapperHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if f == nil || f == t {
+			return data, nil
+		}
+		convert, exist := protoWrapperType[t]
+		if !exist {
+			return data, nil
+		}
+		switch f.Kind() {
+		case reflect.Map, reflect.Struct, reflect.Ptr, reflect.Slice:
+			return data, nil
+		}
+		return convert(data)
+	}
+}
+
+// StringToEnumHookFunc returns a DecodeHookFunc that converts enum value
+// names (case insensitive) to proto enums.
+func StringToEnumHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if f == nil || f.Kind() != reflect.String || t.Kind() != reflect.Int32 || !t.Implements(enumType) {
+			return data, nil
+		}
+		s := strings.TrimSpace(reflect.ValueOf(data).String())
+		values := reflect.Zero(t).Interface().(protoreflect.Enum).Descriptor().Values()
+		value := values.ByName(protoreflect.Name(s))
+		if value == nil {
+			value = values.ByName(protoreflect.Name(strings.ToUpper(s)))
+		}
+		if value == nil {
+			number, err := strconv.ParseInt(s, 10, 32)
+			if err != nil {
+				return data, fmt.Errorf("枚举[%s]没有值[%s]", t.String(), s)
+			}
+			value = values.ByNumber(protoreflect.EnumNumber(number))
+			if value == nil {
+				return data, fmt.Errorf("枚举[%s]没有值[%s]", t.String(), s)
+			}
+		}
+		return reflect.ValueOf(int32(value.Number())).Convert(t).Interface(), nil
+	}
+}
+
+// ProtoMessageHookFunc returns a DecodeHookFunc that renames the keys of a map
+// to the go field names of the target proto message, so that keys can be
+// written as proto names, json names or snake_case (dial_timeout -> DialTimeout).
+func ProtoMessageHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if f == nil || f.Kind() != reflect.Map {
+			return data, nil
+		}
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return data, nil
+		}
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || !reflect.PtrTo(t).Implements(messageType) {
+			return data, nil
+		}
+		fields := protoFieldNames(t)
+		result := make(map[string]interface{}, len(m))
+		for key, value := range m {
+			if name, exist := fields[normalizeName(key)]; exist {
+				key = name
+			}
+			result[key] = value
+		}
+		return result, nil
+	}
+}
+
+// protoFieldNames 返回 归一化后的字段名->go字段名
+func protoFieldNames(t reflect.Type) map[string]string {
+	names := make(map[string]string, t.NumField()*2)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("protobuf")
+		if field.PkgPath != "" || tag == "" {
+			continue
+		}
+		names[normalizeName(field.Name)] = field.Name
+		for _, part := range strings.Split(tag, ",") {
+			if strings.HasPrefix(part, "name=") || strings.HasPrefix(part, "json=") {
+				names[normalizeName(part[5:])] = field.Name
+			}
+		}
+	}
+	return names
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
@@ -0,0 +1,90 @@
+package decodehook
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// 字节大小单位，B/KB/MB为1000进制，KiB/MiB为1024进制
+var byteUnits = map[string]float64{
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+var byteSizePattern = regexp.MustCompile(`^\s*([0-9]+(?:\.[0-9]+)?)\s*([a-zA-Z]+)\s*$`)
+
+/*ParseByteSize 解析带单位的字节大小，比如 64MiB、1.5GB、512B
+参数:
+*	s	string
+返回值:
+*	uint64	uint64
+*	error	error
+*/
+func ParseByteSize(s string) (uint64, error) {
+	match := byteSizePattern.FindStringSubmatch(s)
+	if match == nil {
+		return 0, fmt.Errorf("错误的字节大小[%s]", s)
+	}
+	unit, exist := byteUnits[strings.ToLower(match[2])]
+	if !exist {
+		return 0, fmt.Errorf("未知的字节单位[%s]", match[2])
+	}
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("错误的字节大小[%s]: %w", s, err)
+	}
+	return uint64(number * unit), nil
+}
+
+// ByteSizeHookFunc returns a DecodeHookFunc that converts strings
+// with unit like "64MiB" to integers, strings without unit are left alone.
+// It returns an error when the size overflows the target type.
+func ByteSizeHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if f == nil || f.Kind() != reflect.String || t == durationType || t.Implements(enumType) {
+			return data, nil
+		}
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return data, nil
+		}
+		s := reflect.ValueOf(data).String()
+		if !byteSizePattern.MatchString(s) {
+			return data, nil
+		}
+		size, err := ParseByteSize(s)
+		if err != nil {
+			return data, err
+		}
+		v := reflect.New(t).Elem()
+		switch t.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if v.OverflowUint(size) {
+				return data, fmt.Errorf("字节大小[%s]超出%s的范围", s, t)
+			}
+		default:
+			if size > math.MaxInt64 || v.OverflowInt(int64(size)) {
+				return data, fmt.Errorf("字节大小[%s]超出%s的范围", s, t)
+			}
+		}
+		return size, nil
+	}
+}
@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var catalog = MustCatalog("en", map[string]map[string]string{
+	"en": {"NOT_FOUND": "{id} not found", "ONLY_EN": "english"},
+	"zh": {"NOT_FOUND": "{id}不存在"},
+})
+
+func TestCatalog(t *testing.T) {
+	for _, c := range []struct {
+		accept, reason, locale, message string
+		ok                              bool
+	}{
+		{"zh-CN,zh;q=0.9,en;q=0.8", "NOT_FOUND", "zh", "1不存在", true},
+		{"en-US", "NOT_FOUND", "en", "1 not found", true},
+		{"fr", "NOT_FOUND", "en", "1 not found", true},
+		{"", "NOT_FOUND", "en", "1 not found", true},
+		{"zh", "ONLY_EN", "en", "english", true},
+		{"zh", "UNKNOWN", "", "", false},
+	} {
+		locale, message, ok := catalog.Message(c.accept, c.reason, map[string]string{"id": "1"})
+		require.Equal(t, c.ok, ok, c.accept)
+		require.Equal(t, c.locale, locale, c.accept)
+		require.Equal(t, c.message, message, c.accept)
+	}
+	_, err := NewCatalog("fr", map[string]map[string]string{"en": {}})
+	require.Error(t, err)
+}
+
+func TestStatus(t *testing.T) {
+	s := FromError(kerrors.NotFound("NOT_FOUND", "raw")).
+		WithMetadata(map[string]string{"id": "1"}).
+		WithFieldViolation("id", "不存在").
+		WithRetryDelay(time.Second)
+	s = catalog.Localize(s, "zh")
+	require.Equal(t, "zh", s.Locale())
+	require.True(t, kerrors.IsNotFound(s))
+	require.Equal(t, "NOT_FOUND", kerrors.Reason(s))
+
+	gs := s.GRPCStatus()
+	require.Equal(t, codes.NotFound, gs.Code())
+	got := FromError(gs.Err())
+	require.Equal(t, s.Reason(), got.Reason())
+	require.Equal(t, s.Message(), got.Message())
+	require.Equal(t, s.Metadata(), got.Metadata())
+	require.Equal(t, s.FieldViolations(), got.FieldViolations())
+	require.Equal(t, time.Second, got.RetryDelay())
+	require.Equal(t, "zh", got.Locale())
+
+	b, err := json.Marshal(s)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"code": 404,
+		"reason": "NOT_FOUND",
+		"message": "1不存在",
+		"metadata": {"id": "1"},
+		"field_violations": [{"field": "id", "description": "不存在"}],
+		"retry_delay": "1s",
+		"locale": "zh"
+	}`, string(b))
+
+	plain := FromError(status.Error(codes.NotFound, "x"))
+	require.Equal(t, 404, plain.StatusCode())
+	require.Empty(t, plain.FieldViolations())
+}
@@ -0,0 +1,108 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Catalog 按照reason和语言查找错误信息模板，模板中的{key}替换为metadata中的值
+type Catalog struct {
+	tags     []language.Tag
+	matcher  language.Matcher
+	messages []map[string]string // 和tags一一对应
+}
+
+/*NewCatalog 创建错误信息目录
+参数:
+*	fallback	string							Accept-Language没有匹配时使用的语言
+*	messages	map[string]map[string]string	语言->reason->模板
+返回值:
+*	*Catalog	*Catalog
+*	error   	error
+*/
+func NewCatalog(fallback string, messages map[string]map[string]string) (*Catalog, error) {
+	if _, exist := messages[fallback]; !exist {
+		return nil, fmt.Errorf("没有默认语言[%s]的错误信息", fallback)
+	}
+	langs := make([]string, 0, len(messages))
+	for lang := range messages {
+		if lang != fallback {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs)
+	// matcher没有匹配时返回第一个
+	langs = append([]string{fallback}, langs...)
+	c := &Catalog{}
+	for _, lang := range langs {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return nil, fmt.Errorf("错误的语言[%s]: %w", lang, err)
+		}
+		c.tags = append(c.tags, tag)
+		c.messages = append(c.messages, messages[lang])
+	}
+	c.matcher = language.NewMatcher(c.tags)
+	return c, nil
+}
+
+// MustCatalog NewCatalog出错时panic
+func MustCatalog(fallback string, messages map[string]map[string]string) *Catalog {
+	c, err := NewCatalog(fallback, messages)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+/*Message 根据Accept-Language查找信息
+参数:
+*	acceptLanguage	string				例如zh-CN,zh;q=0.9,en;q=0.8
+*	reason        	string
+*	metadata      	map[string]string	替换模板中的{key}
+返回值:
+*	locale 	string	匹配的语言
+*	message	string
+*	ok     	bool	没有对应reason的模板时为false
+*/
+func (c *Catalog) Message(acceptLanguage, reason string, metadata map[string]string) (locale, message string, ok bool) {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, index, _ := c.matcher.Match(tags...)
+	tpl, ok := c.messages[index][reason]
+	if !ok {
+		// 匹配的语言没有时使用默认语言
+		index = 0
+		if tpl, ok = c.messages[0][reason]; !ok {
+			return "", "", false
+		}
+	}
+	if len(metadata) > 0 {
+		pairs := make([]string, 0, len(metadata)*2)
+		for k, v := range metadata {
+			pairs = append(pairs, "{"+k+"}", v)
+		}
+		tpl = strings.NewReplacer(pairs...).Replace(tpl)
+	}
+	return c.tags[index].String(), tpl, true
+}
+
+/*Localize 把错误转为Status并替换为本地化的信息
+参数:
+*	err           	error
+*	acceptLanguage	string
+返回值:
+*	*Status	*Status		err为nil时返回nil
+*/
+func (c *Catalog) Localize(err error, acceptLanguage string) *Status {
+	s := FromError(err)
+	if s == nil || c == nil {
+		return s
+	}
+	if locale, message, ok := c.Message(acceptLanguage, s.Reason(), s.Metadata()); ok {
+		s = s.WithLocalizedMessage(locale, message)
+	}
+	return s
+}
@@ -0,0 +1,200 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// FieldViolation 参数错误的字段
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// Status 在kratos错误的基础上增加字段错误、重试时间和本地化信息
+// http返回json，grpc作为status details返回
+type Status struct {
+	err        *kerrors.Error
+	violations []FieldViolation
+	retryDelay time.Duration
+	locale     string
+}
+
+/*FromError 把错误转为Status，已经是Status时原样返回
+参数:
+*	err	error
+返回值:
+*	*Status	*Status		err为nil时返回nil
+*/
+func FromError(err error) *Status {
+	if err == nil {
+		return nil
+	}
+	if s := new(Status); errors.As(err, &s) {
+		return s
+	}
+	s := &Status{err: kerrors.FromError(err)}
+	if gs, ok := status.FromError(err); ok {
+		for _, detail := range gs.Details() {
+			switch d := detail.(type) {
+			case *errdetails.BadRequest:
+				for _, v := range d.GetFieldViolations() {
+					s.violations = append(s.violations, FieldViolation{Field: v.GetField(), Description: v.GetDescription()})
+				}
+			case *errdetails.RetryInfo:
+				s.retryDelay = d.GetRetryDelay().AsDuration()
+			case *errdetails.LocalizedMessage:
+				s.locale = d.GetLocale()
+			}
+		}
+	}
+	return s
+}
+
+func (s *Status) clone() *Status {
+	c := *s
+	c.err = proto.Clone(s.err).(*kerrors.Error)
+	c.violations = append([]FieldViolation(nil), s.violations...)
+	return &c
+}
+
+func (s *Status) Error() string {
+	return s.err.Error()
+}
+
+// Unwrap kratos的errors.FromError/Is可以拿到原始错误
+func (s *Status) Unwrap() error {
+	return s.err
+}
+
+// StatusCode http状态码
+func (s *Status) StatusCode() int {
+	return s.err.StatusCode()
+}
+
+func (s *Status) Reason() string {
+	return s.err.Reason
+}
+
+func (s *Status) Message() string {
+	return s.err.Message
+}
+
+func (s *Status) Metadata() map[string]string {
+	return s.err.Metadata
+}
+
+// FieldViolations 参数错误的字段
+func (s *Status) FieldViolations() []FieldViolation {
+	return s.violations
+}
+
+// RetryDelay 建议的重试间隔，为0时不建议重试
+func (s *Status) RetryDelay() time.Duration {
+	return s.retryDelay
+}
+
+// Locale 本地化信息的语言，为空时没有本地化
+func (s *Status) Locale() string {
+	return s.locale
+}
+
+// WithFieldViolation 增加一个参数错误的字段
+func (s *Status) WithFieldViolation(field, description string) *Status {
+	c := s.clone()
+	c.violations = append(c.violations, FieldViolation{Field: field, Description: description})
+	return c
+}
+
+// WithRetryDelay 设置建议的重试间隔
+func (s *Status) WithRetryDelay(delay time.Duration) *Status {
+	c := s.clone()
+	c.retryDelay = delay
+	return c
+}
+
+// WithMetadata 合并metadata，信息模板中可以使用
+func (s *Status) WithMetadata(md map[string]string) *Status {
+	c := s.clone()
+	if c.err.Metadata == nil {
+		c.err.Metadata = make(map[string]string, len(md))
+	}
+	for k, v := range md {
+		c.err.Metadata[k] = v
+	}
+	return c
+}
+
+// WithLocalizedMessage 替换为本地化的信息
+func (s *Status) WithLocalizedMessage(locale, message string) *Status {
+	c := s.clone()
+	c.locale = locale
+	c.err.Message = message
+	return c
+}
+
+// GRPCStatus ErrorInfo之外增加BadRequest、RetryInfo和LocalizedMessage
+func (s *Status) GRPCStatus() *status.Status {
+	details := []proto.Message{&errdetails.ErrorInfo{
+		Reason:   s.err.Reason,
+		Metadata: s.err.Metadata,
+	}}
+	if len(s.violations) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, v := range s.violations {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+		details = append(details, br)
+	}
+	if s.retryDelay > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(s.retryDelay)})
+	}
+	if s.locale != "" {
+		details = append(details, &errdetails.LocalizedMessage{Locale: s.locale, Message: s.err.Message})
+	}
+	p := s.err.GRPCStatus().Proto()
+	p.Details = p.Details[:0]
+	for _, detail := range details {
+		if any, err := anypb.New(detail); err == nil {
+			p.Details = append(p.Details, any)
+		}
+	}
+	return status.FromProto(p)
+}
+
+// statusJSON http返回的错误
+type statusJSON struct {
+	Code            int32             `json:"code"`
+	Reason          string            `json:"reason"`
+	Message         string            `json:"message"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	FieldViolations []FieldViolation  `json:"field_violations,omitempty"`
+	RetryDelay      string            `json:"retry_delay,omitempty"`
+	Locale          string            `json:"locale,omitempty"`
+}
+
+func (s *Status) MarshalJSON() ([]byte, error) {
+	v := statusJSON{
+		Code:            s.err.Code,
+		Reason:          s.err.Reason,
+		Message:         s.err.Message,
+		Metadata:        s.err.Metadata,
+		FieldViolations: s.violations,
+		Locale:          s.locale,
+	}
+	if s.retryDelay > 0 {
+		v.RetryDelay = s.retryDelay.String()
+	}
+	return json.Marshal(v)
+}
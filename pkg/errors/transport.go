@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"context"
+	nethttp "net/http"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/metadata"
+)
+
+// AcceptLanguageKey grpc中传递语言的metadata
+const AcceptLanguageKey = "accept-language"
+
+// AcceptLanguage 从http请求头或grpc metadata中取出Accept-Language
+func AcceptLanguage(ctx context.Context) string {
+	if info, ok := http.FromServerContext(ctx); ok && info.Request != nil {
+		return info.Request.Header.Get("Accept-Language")
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(AcceptLanguageKey); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// Server 所有错误转为本地化的Status，grpc通过GRPCStatus返回详情
+func Server(c *Catalog) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			reply, err := handler(ctx, req)
+			if err != nil {
+				return nil, c.Localize(err, AcceptLanguage(ctx))
+			}
+			return reply, nil
+		}
+	}
+}
+
+// ErrorEncoder http的错误编码，请求解码失败等没有经过中间件的错误也会本地化
+func ErrorEncoder(c *Catalog) http.EncodeErrorFunc {
+	return func(w nethttp.ResponseWriter, r *nethttp.Request, err error) {
+		s := c.Localize(err, r.Header.Get("Accept-Language"))
+		codec := encoding.GetCodec("json")
+		body, err := codec.Marshal(s)
+		if err != nil {
+			w.WriteHeader(nethttp.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if s.Locale() != "" {
+			w.Header().Set("Content-Language", s.Locale())
+		}
+		w.WriteHeader(s.StatusCode())
+		_, _ = w.Write(body)
+	}
+}
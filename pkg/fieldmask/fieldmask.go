@@ -0,0 +1,154 @@
+package fieldmask
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Field 接口字段对应的数据库字段
+type Field struct {
+	DB        string // 数据库字段，mongo为bson名，mysql为列名
+	Immutable bool   // 不能通过update mask修改
+}
+
+// Mask 根据proto消息校验field mask，并转为数据库字段
+type Mask struct {
+	message protoreflect.MessageDescriptor
+	fields  map[string]Field
+}
+
+/*New 创建Mask
+参数:
+*	msg   	proto.Message		资源对应的proto消息，path按照proto字段名校验
+*	fields	map[string]Field	可以使用的字段，key为proto字段名
+返回值:
+*	*Mask	*Mask
+*	error	error				fields中有消息不存在的字段
+*/
+func New(msg proto.Message, fields map[string]Field) (*Mask, error) {
+	m := &Mask{
+		message: msg.ProtoReflect().Descriptor(),
+		fields:  fields,
+	}
+	for path := range fields {
+		if err := m.validPath(path); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// MustNew New出错时panic，用于包级变量
+func MustNew(msg proto.Message, fields map[string]Field) *Mask {
+	m, err := New(msg, fields)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// validPath path中的每一段都必须是消息中的字段
+func (m *Mask) validPath(path string) error {
+	md := m.message
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		if md == nil {
+			return fmt.Errorf("字段[%s]不是消息，不能使用[%s]", strings.Join(names[:i], "."), path)
+		}
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return fmt.Errorf("%s没有字段[%s]", m.message.FullName(), path)
+		}
+		md = nil
+		if fd.Kind() == protoreflect.MessageKind && !fd.IsList() && !fd.IsMap() {
+			md = fd.Message()
+		}
+	}
+	return nil
+}
+
+/*Field 把proto字段路径转为数据库字段
+参数:
+*	path	string	proto字段路径，fields中没有时按照最长的前缀转换，例如a.b在a->x时为x.b
+返回值:
+*	string	string	数据库字段
+*	Field 	Field	匹配的规则
+*	error 	error
+*/
+func (m *Mask) Field(path string) (string, Field, error) {
+	if m == nil {
+		return path, Field{DB: path}, nil
+	}
+	if err := m.validPath(path); err != nil {
+		return "", Field{}, err
+	}
+	for prefix, rest := path, ""; ; {
+		if field, exist := m.fields[prefix]; exist {
+			return field.DB + rest, field, nil
+		}
+		i := strings.LastIndexByte(prefix, '.')
+		if i < 0 {
+			return "", Field{}, fmt.Errorf("不支持的字段[%s]", path)
+		}
+		prefix, rest = prefix[:i], prefix[i:]+rest
+	}
+}
+
+/*Read 读取时的field mask转为数据库字段
+参数:
+*	mask	*fieldmaskpb.FieldMask	为空时返回nil，表示全部字段
+返回值:
+*	[]string	[]string
+*	error   	error
+*/
+func (m *Mask) Read(mask *fieldmaskpb.FieldMask) ([]string, error) {
+	paths := mask.GetPaths()
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	fields := make([]string, 0, len(paths))
+	for _, path := range paths {
+		field, _, err := m.Field(path)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+/*Update 更新时的field mask转为数据库字段，不能包含Immutable字段
+参数:
+*	mask	*fieldmaskpb.FieldMask	为空时返回所有可以修改的字段
+返回值:
+*	[]string	[]string
+*	error   	error
+*/
+func (m *Mask) Update(mask *fieldmaskpb.FieldMask) ([]string, error) {
+	paths := mask.GetPaths()
+	if len(paths) == 0 {
+		for path, field := range m.fields {
+			if !field.Immutable {
+				paths = append(paths, path)
+			}
+		}
+		sort.Strings(paths)
+	}
+	fields := make([]string, 0, len(paths))
+	for _, path := range paths {
+		field, rule, err := m.Field(path)
+		if err != nil {
+			return nil, err
+		}
+		if rule.Immutable {
+			return nil, fmt.Errorf("字段[%s]不能修改", path)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
@@ -0,0 +1,38 @@
+package fieldmask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+func TestMask(t *testing.T) {
+	_, err := New(&typepb.Type{}, map[string]Field{"unknown": {DB: "unknown"}})
+	require.Error(t, err)
+
+	m := MustNew(&typepb.Type{}, map[string]Field{
+		"name":           {DB: "_id", Immutable: true},
+		"source_context": {DB: "source"},
+		"syntax":         {DB: "syntax"},
+	})
+
+	fields, err := m.Read(nil)
+	require.NoError(t, err)
+	require.Nil(t, fields)
+	fields, err = m.Read(&fieldmaskpb.FieldMask{Paths: []string{"name", "source_context.file_name"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"_id", "source.file_name"}, fields)
+
+	for _, path := range []string{"fields", "source_context.unknown", "syntax.value", "oneofs"} {
+		_, err = m.Read(&fieldmaskpb.FieldMask{Paths: []string{path}})
+		require.Error(t, err, path)
+	}
+
+	fields, err = m.Update(nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"source", "syntax"}, fields)
+	_, err = m.Update(&fieldmaskpb.FieldMask{Paths: []string{"name"}})
+	require.Error(t, err)
+}
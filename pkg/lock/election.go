@@ -0,0 +1,130 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Election 多个实例中只有持有锁的实例运行fn，实现transport.Server，可以注册到kratos.App，Stop之后可以再次Start
+type Election struct {
+	locker *Locker
+	name   string
+	fn     func(ctx context.Context) error
+	leader int32
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc // 运行中时不为nil
+	done    chan struct{}
+	stopped bool // Start之前调用了Stop，下一次Start直接返回
+}
+
+var _ transport.Server = (*Election)(nil)
+
+/*NewElection 创建选主，Start后竞争锁，获得锁的实例运行fn
+参数:
+*	locker	*Locker
+*	name  	string		锁的名字，同名的Election互斥
+*	fn    	func(ctx context.Context) error		应该运行到ctx结束，失去锁或Stop时ctx取消
+返回值:
+*	*Election	*Election
+*/
+func NewElection(locker *Locker, name string, fn func(ctx context.Context) error) *Election {
+	return &Election{
+		locker: locker,
+		name:   name,
+		fn:     fn,
+	}
+}
+
+func (e *Election) Endpoint() (string, error) {
+	return "redis://" + e.locker.prefix + e.name, nil
+}
+
+// IsLeader 当前是否持有锁并在运行fn
+func (e *Election) IsLeader() bool {
+	return atomic.LoadInt32(&e.leader) == 1
+}
+
+// Start 阻塞到Stop，fn返回或失去锁后等待重试间隔再重新竞争
+// Stop先于Start调用时(例如kratos应用启动中退出)，Start直接返回
+func (e *Election) Start() error {
+	e.mu.Lock()
+	if e.stopped {
+		e.stopped = false
+		e.mu.Unlock()
+		return nil
+	}
+	if e.cancel != nil {
+		e.mu.Unlock()
+		return fmt.Errorf("选主[%s]已经在运行", e.name)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	e.cancel, e.done = cancel, done
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		e.cancel, e.done = nil, nil
+		e.mu.Unlock()
+		cancel()
+		close(done)
+	}()
+
+	for {
+		if err := e.term(ctx); err != nil {
+			e.locker.log.Errorf("选主[%s]: %+v", e.name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(e.locker.retry):
+		}
+	}
+}
+
+// term 获得锁并运行fn，直到fn返回或者失去锁
+func (e *Election) term(parent context.Context) error {
+	lk, err := e.locker.Lock(parent, e.name)
+	if err != nil {
+		if parent.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	e.locker.log.Infof("选主[%s]当选, token: %d", e.name, lk.Token())
+	atomic.StoreInt32(&e.leader, 1)
+	ctx, cancel := lk.Context(parent)
+	err = e.fn(ctx)
+	cancel()
+	atomic.StoreInt32(&e.leader, 0)
+	if releaseErr := lk.Release(context.Background()); releaseErr != nil && !errors.Is(releaseErr, ErrLockLost) {
+		e.locker.log.Errorf("选主[%s]释放锁失败: %+v", e.name, releaseErr)
+	}
+	e.locker.log.Infof("选主[%s]卸任", e.name)
+	if parent.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// Stop 取消fn并等待Start返回，没有运行时记录停止，之后的Start直接返回
+func (e *Election) Stop() error {
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	if cancel == nil {
+		e.stopped = true
+	}
+	e.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	<-done
+	return nil
+}
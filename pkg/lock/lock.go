@@ -0,0 +1,323 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/logger"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+)
+
+var (
+	// ErrNotAcquired TryLock时锁被其他人持有
+	ErrNotAcquired = errors.New("lock: not acquired")
+	// ErrLockLost 锁已经过期或者被其他人持有，续期和释放时返回
+	ErrLockLost = errors.New("lock: lost")
+)
+
+// acquireScript 加锁成功时递增fencing token，两个操作在一个脚本中保证原子
+// KEYS[1] 锁, KEYS[2] fencing token计数; ARGV[1] 持有者, ARGV[2] 过期毫秒
+var acquireScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0
+`)
+
+// refreshScript 只有持有者可以续期
+var refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript 只有持有者可以释放，避免删除过期后被其他人获得的锁
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Option Locker的选项
+type Option func(l *Locker)
+
+// WithPrefix redis key的前缀，默认"lock:"
+func WithPrefix(prefix string) Option {
+	return func(l *Locker) {
+		l.prefix = prefix
+	}
+}
+
+// WithTTL 锁的过期时间，持有期间每ttl/3自动续期，默认30秒
+func WithTTL(ttl time.Duration) Option {
+	return func(l *Locker) {
+		l.ttl = ttl
+	}
+}
+
+// WithRetryInterval Lock等待锁时的重试间隔，默认100毫秒
+func WithRetryInterval(interval time.Duration) Option {
+	return func(l *Locker) {
+		l.retry = interval
+	}
+}
+
+// WithLogger 日志
+func WithLogger(l log.Logger) Option {
+	return func(lk *Locker) {
+		lk.log = log.NewHelper(logger.Module(l, "lock"))
+	}
+}
+
+// Locker 基于redis的分布式锁，适用于单个redis实例或主从
+type Locker struct {
+	rdb    redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+	retry  time.Duration
+	log    *log.Helper
+}
+
+/*NewLocker 创建分布式锁
+参数:
+*	rdb 	redis.UniversalClient
+*	opts	...Option
+返回值:
+*	*Locker	*Locker
+*/
+func NewLocker(rdb redis.UniversalClient, opts ...Option) *Locker {
+	l := &Locker{
+		rdb:    rdb,
+		prefix: "lock:",
+		ttl:    30 * time.Second,
+		retry:  100 * time.Millisecond,
+		log:    log.NewHelper(logger.Module(log.DefaultLogger, "lock")),
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return l
+}
+
+// Lock 持有中的锁，持有期间自动续期，续期失败时Done关闭
+type Lock struct {
+	locker *Locker
+	name   string
+	key    string
+	value  string
+	token  int64
+	done   chan struct{}
+	stop   chan struct{}
+	once   sync.Once // 关闭done
+	halt   sync.Once // 关闭stop
+	wg     sync.WaitGroup
+}
+
+/*TryLock 尝试获取锁，不等待
+参数:
+*	ctx 	context.Context
+*	name	string			锁的名字，不包括前缀
+返回值:
+*	*Lock	*Lock
+*	error	error	锁被其他人持有时返回ErrNotAcquired
+*/
+func (l *Locker) TryLock(ctx context.Context, name string) (*Lock, error) {
+	value, err := randomValue()
+	if err != nil {
+		return nil, err
+	}
+	key := l.prefix + name
+	token, err := acquireScript.Run(ctx, l.rdb, []string{key, key + ":fence"}, value, l.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("获取锁[%s]: %w", name, err)
+	}
+	if token == 0 {
+		return nil, ErrNotAcquired
+	}
+	lk := &Lock{
+		locker: l,
+		name:   name,
+		key:    key,
+		value:  value,
+		token:  token,
+		done:   make(chan struct{}),
+		stop:   make(chan struct{}),
+	}
+	lk.wg.Add(1)
+	go lk.keepAlive()
+	return lk, nil
+}
+
+/*Lock 获取锁，被其他人持有时每隔重试间隔再尝试，直到获得锁或ctx结束
+参数:
+*	ctx 	context.Context
+*	name	string			锁的名字，不包括前缀
+返回值:
+*	*Lock	*Lock
+*	error	error
+*/
+func (l *Locker) Lock(ctx context.Context, name string) (*Lock, error) {
+	ticker := time.NewTicker(l.retry)
+	defer ticker.Stop()
+	for {
+		lk, err := l.TryLock(ctx, name)
+		if !errors.Is(err, ErrNotAcquired) {
+			return lk, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+/*WithLock 持有锁执行fn，失去锁时取消fn的ctx，fn返回后释放锁
+参数:
+*	ctx 	context.Context
+*	name	string
+*	fn  	func(ctx context.Context) error		ctx中可以通过FromContext取得锁和fencing token
+返回值:
+*	error	error	fn的错误优先，其次是失去锁时的ErrLockLost
+*/
+func (l *Locker) WithLock(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	lk, err := l.Lock(ctx, name)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := lk.Context(ctx)
+	defer cancel()
+	err = fn(ctx)
+	if releaseErr := lk.Release(context.Background()); err == nil {
+		err = releaseErr
+	}
+	return err
+}
+
+// Name 锁的名字
+func (lk *Lock) Name() string {
+	return lk.name
+}
+
+// Token fencing token，每次获得锁时递增
+// 写入受保护的资源时带上token，资源拒绝小于已见过的token的写入，避免暂停后锁已过期的持有者继续写
+func (lk *Lock) Token() int64 {
+	return lk.token
+}
+
+// Done 续期失败、失去锁时关闭
+func (lk *Lock) Done() <-chan struct{} {
+	return lk.done
+}
+
+type lockKey struct{}
+
+// Context 返回失去锁时取消的ctx，ctx中带有锁
+func (lk *Lock) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithValue(parent, lockKey{}, lk))
+	go func() {
+		select {
+		case <-lk.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// FromContext 取得Context、WithLock和Election放入ctx的锁
+func FromContext(ctx context.Context) (*Lock, bool) {
+	lk, ok := ctx.Value(lockKey{}).(*Lock)
+	return lk, ok
+}
+
+// Refresh 手动续期，自动续期之外需要立即确认仍然持有锁时调用
+func (lk *Lock) Refresh(ctx context.Context) error {
+	ok, err := refreshScript.Run(ctx, lk.locker.rdb, []string{lk.key}, lk.value, lk.locker.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return fmt.Errorf("续期锁[%s]: %w", lk.name, err)
+	}
+	if ok == 0 {
+		lk.lost()
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Release 停止续期并释放锁，锁已经过期时返回ErrLockLost
+func (lk *Lock) Release(ctx context.Context) error {
+	lk.halt.Do(func() {
+		close(lk.stop)
+	})
+	lk.wg.Wait()
+	select {
+	case <-lk.done:
+		return ErrLockLost
+	default:
+	}
+	defer lk.lost()
+	ok, err := releaseScript.Run(ctx, lk.locker.rdb, []string{lk.key}, lk.value).Int64()
+	if err != nil {
+		return fmt.Errorf("释放锁[%s]: %w", lk.name, err)
+	}
+	if ok == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (lk *Lock) lost() {
+	lk.once.Do(func() {
+		close(lk.done)
+	})
+}
+
+// keepAlive 每ttl/3续期，redis暂时不可用时继续重试，直到锁过期
+func (lk *Lock) keepAlive() {
+	defer lk.wg.Done()
+	ttl := lk.locker.ttl
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	deadline := time.Now().Add(ttl)
+	for {
+		select {
+		case <-lk.stop:
+			return
+		case <-lk.done:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), ttl/3)
+		err := lk.Refresh(ctx)
+		cancel()
+		switch {
+		case err == nil:
+			deadline = time.Now().Add(ttl)
+		case errors.Is(err, ErrLockLost):
+			lk.locker.log.Errorf("锁[%s]已经失去", lk.name)
+			return
+		case time.Now().After(deadline):
+			lk.locker.log.Errorf("锁[%s]续期失败，已经过期: %+v", lk.name, err)
+			lk.lost()
+			return
+		default:
+			lk.locker.log.Warnf("锁[%s]续期失败: %v", lk.name, err)
+		}
+	}
+}
+
+func randomValue() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
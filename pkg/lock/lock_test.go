@@ -0,0 +1,136 @@
+package lock
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocker(t *testing.T, opts ...Option) (*miniredis.Miniredis, *Locker) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return mr, NewLocker(rdb, opts...)
+}
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	mr, l := newTestLocker(t, WithTTL(time.Minute))
+
+	first, err := l.TryLock(ctx, "job")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), first.Token())
+	require.Equal(t, time.Minute, mr.TTL("lock:job"))
+
+	_, err = l.TryLock(ctx, "job")
+	require.ErrorIs(t, err, ErrNotAcquired)
+
+	// 等待期间释放
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		assert.NoError(t, first.Release(ctx))
+	}()
+	second, err := l.Lock(ctx, "job")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), second.Token())
+	require.ErrorIs(t, first.Release(ctx), ErrLockLost)
+
+	// 过期后被其他人持有，原持有者不能续期和释放
+	mr.FastForward(time.Minute)
+	third, err := l.TryLock(ctx, "job")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), third.Token())
+	require.ErrorIs(t, second.Refresh(ctx), ErrLockLost)
+	select {
+	case <-second.Done():
+	default:
+		t.Fatal("lost lock should be done")
+	}
+	require.ErrorIs(t, second.Release(ctx), ErrLockLost)
+	require.True(t, mr.Exists("lock:job"))
+	require.NoError(t, third.Release(ctx))
+	require.False(t, mr.Exists("lock:job"))
+
+	timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = l.TryLock(ctx, "job")
+	require.NoError(t, err)
+	_, err = l.Lock(timeout, "job")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWithLock(t *testing.T) {
+	ctx := context.Background()
+	mr, l := newTestLocker(t, WithTTL(300*time.Millisecond))
+
+	err := l.WithLock(ctx, "job", func(ctx context.Context) error {
+		lk, ok := FromContext(ctx)
+		require.True(t, ok)
+		require.Equal(t, int64(1), lk.Token())
+		// 超过ttl仍然持有，说明自动续期
+		time.Sleep(500 * time.Millisecond)
+		require.NoError(t, ctx.Err())
+		require.True(t, mr.Exists("lock:job"))
+
+		// 锁被删除后续期失败，ctx取消
+		mr.Del("lock:job")
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("ctx should be canceled after lock lost")
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, ErrLockLost)
+}
+
+func TestElection(t *testing.T) {
+	_, l := newTestLocker(t, WithTTL(300*time.Millisecond), WithRetryInterval(20*time.Millisecond))
+
+	var running, overlapped int32
+	fn := func(ctx context.Context) error {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.StoreInt32(&overlapped, 1)
+		}
+		<-ctx.Done()
+		atomic.AddInt32(&running, -1)
+		return nil
+	}
+	a, b := NewElection(l, "worker", fn), NewElection(l, "worker", fn)
+	go func() { _ = a.Start() }()
+	go func() { _ = b.Start() }()
+
+	require.Eventually(t, func() bool { return a.IsLeader() || b.IsLeader() }, time.Second, 10*time.Millisecond)
+	leader, follower := a, b
+	if b.IsLeader() {
+		leader, follower = b, a
+	}
+	time.Sleep(100 * time.Millisecond)
+	require.False(t, follower.IsLeader())
+
+	// 当选的实例停止后另一个接任
+	require.NoError(t, leader.Stop())
+	require.False(t, leader.IsLeader())
+	require.Eventually(t, follower.IsLeader, time.Second, 10*time.Millisecond)
+	require.NoError(t, follower.Stop())
+	require.Equal(t, int32(0), atomic.LoadInt32(&running))
+	require.Equal(t, int32(0), atomic.LoadInt32(&overlapped))
+
+	// 停止后可以再次Start
+	errc := make(chan error, 1)
+	go func() { errc <- leader.Start() }()
+	require.Eventually(t, leader.IsLeader, time.Second, 10*time.Millisecond)
+	require.NoError(t, leader.Stop())
+	require.NoError(t, <-errc)
+
+	// Start之前Stop，Start直接返回
+	c := NewElection(l, "worker", fn)
+	require.NoError(t, c.Stop())
+	require.NoError(t, c.Start())
+}
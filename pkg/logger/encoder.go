@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var _ log.Logger = (*writerLogger)(nil)
+
+// writerLogger 把日志编码后写入io.Writer，一条日志一行
+type writerLogger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	pool   *sync.Pool
+	encode func(buf *bytes.Buffer, level log.Level, keyvals []interface{})
+}
+
+// NewConsoleLogger 输出 LEVEL key=value key=value
+func NewConsoleLogger(w io.Writer) log.Logger {
+	return newWriterLogger(w, encodeConsole)
+}
+
+// NewJSONLogger 输出 {"level":"INFO","key":"value"}
+func NewJSONLogger(w io.Writer) log.Logger {
+	return newWriterLogger(w, encodeJSON)
+}
+
+func newWriterLogger(w io.Writer, encode func(*bytes.Buffer, log.Level, []interface{})) *writerLogger {
+	return &writerLogger{
+		w: w,
+		pool: &sync.Pool{
+			New: func() interface{} {
+				return new(bytes.Buffer)
+			},
+		},
+		encode: encode,
+	}
+}
+
+// Log 编码并输出
+func (l *writerLogger) Log(level log.Level, keyvals ...interface{}) error {
+	if len(keyvals) == 0 {
+		return nil
+	}
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, "")
+	}
+	buf := l.pool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		l.pool.Put(buf)
+	}()
+	l.encode(buf, level, keyvals)
+	buf.WriteByte('\n')
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := l.w.Write(buf.Bytes())
+	return err
+}
+
+func encodeConsole(buf *bytes.Buffer, level log.Level, keyvals []interface{}) {
+	buf.WriteString(level.String())
+	for i := 0; i < len(keyvals); i += 2 {
+		fmt.Fprintf(buf, " %s=%v", keyvals[i], log.Value(keyvals[i+1]))
+	}
+}
+
+func encodeJSON(buf *bytes.Buffer, level log.Level, keyvals []interface{}) {
+	buf.WriteString(`{"level":"`)
+	buf.WriteString(level.String())
+	buf.WriteByte('"')
+	for i := 0; i < len(keyvals); i += 2 {
+		buf.WriteByte(',')
+		key, _ := json.Marshal(fmt.Sprint(keyvals[i]))
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(jsonValue(log.Value(keyvals[i+1])))
+	}
+	buf.WriteByte('}')
+}
+
+// jsonValue 错误和Stringer使用字符串，其他类型直接json编码，编码失败时退化为%v
+func jsonValue(v interface{}) []byte {
+	switch value := v.(type) {
+	case error:
+		v = value.Error()
+	case fmt.Stringer:
+		v = value.String()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("%v", v))
+	}
+	return data
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var _ log.Logger = (*filter)(nil)
+
+// filter 按照级别过滤日志，带有module字段的日志使用模块自己的级别
+type filter struct {
+	logger  log.Logger
+	level   log.Level
+	modules map[string]log.Level
+}
+
+/*NewFilter 创建按级别过滤的日志
+参数:
+*	l      	log.Logger
+*	level  	log.Level			默认级别
+*	modules	map[string]string	模块->级别，模块通过Module设置
+返回值:
+*	log.Logger	log.Logger
+*/
+func NewFilter(l log.Logger, level log.Level, modules map[string]string) log.Logger {
+	f := &filter{
+		logger:  l,
+		level:   level,
+		modules: make(map[string]log.Level, len(modules)),
+	}
+	for module, level := range modules {
+		f.modules[module] = log.ParseLevel(level)
+	}
+	return f
+}
+
+// Log 低于级别的日志直接丢弃
+func (f *filter) Log(level log.Level, keyvals ...interface{}) error {
+	if level < f.levelOf(keyvals) {
+		return nil
+	}
+	return f.logger.Log(level, keyvals...)
+}
+
+func (f *filter) levelOf(keyvals []interface{}) log.Level {
+	if len(f.modules) == 0 {
+		return f.level
+	}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if keyvals[i] != ModuleKey {
+			continue
+		}
+		if level, exist := f.modules[fmt.Sprint(keyvals[i+1])]; exist {
+			return level
+		}
+	}
+	return f.level
+}
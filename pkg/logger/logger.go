@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	// FormatConsole key=value格式
+	FormatConsole = "console"
+	// FormatJSON 每行一个json
+	FormatJSON = "json"
+
+	// ModuleKey 模块字段，按照模块设置日志级别
+	ModuleKey = "module"
+	// MessageKey 日志内容字段，采样时按照级别和内容判断是否重复
+	MessageKey = "msg"
+)
+
+// Options 日志配置
+type Options struct {
+	Level   string            // 默认级别
+	Format  string            // console或json
+	Modules map[string]string // 模块->级别
+	Rotate  *Rotate           // 为空时输出到标准输出
+	Sample  *Sample           // 为空时不采样
+	Trace   bool              // 是否输出WithContext注入的trace_id/span_id
+}
+
+// Rotate 文件滚动配置
+type Rotate struct {
+	Filename   string
+	MaxSize    int // MB
+	MaxBackups int
+	MaxAge     int // 天
+	Compress   bool
+}
+
+// Sample 采样配置，每个Tick内相同级别和内容的日志前Initial条输出，之后每Thereafter条输出一条
+type Sample struct {
+	Tick       time.Duration
+	Initial    int
+	Thereafter int
+}
+
+/*New 根据配置创建日志
+参数:
+*	o	Options
+返回值:
+*	log.Logger	log.Logger
+*	func()    	func()		关闭日志文件
+*/
+func New(o Options) (log.Logger, func()) {
+	var (
+		w       io.Writer = os.Stdout
+		cleanup           = func() {}
+	)
+	if o.Rotate != nil && o.Rotate.Filename != "" {
+		rotate := &lumberjack.Logger{
+			Filename:   o.Rotate.Filename,
+			MaxSize:    o.Rotate.MaxSize,
+			MaxBackups: o.Rotate.MaxBackups,
+			MaxAge:     o.Rotate.MaxAge,
+			Compress:   o.Rotate.Compress,
+			LocalTime:  true,
+		}
+		w = rotate
+		cleanup = func() {
+			_ = rotate.Close()
+		}
+	}
+	var l log.Logger
+	switch o.Format {
+	case FormatJSON:
+		l = NewJSONLogger(w)
+	default:
+		l = NewConsoleLogger(w)
+	}
+	if !o.Trace {
+		l = untrace{logger: l}
+	}
+	if o.Sample != nil && o.Sample.Tick > 0 {
+		l = NewSampler(l, o.Sample.Tick, o.Sample.Initial, o.Sample.Thereafter)
+	}
+	return NewFilter(l, log.ParseLevel(o.Level), o.Modules), cleanup
+}
+
+// Module 给日志加上模块字段，模块的级别可以单独配置
+func Module(l log.Logger, module string) log.Logger {
+	return log.With(l, ModuleKey, module)
+}
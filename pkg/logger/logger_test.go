@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestJSONLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewJSONLogger(buf)
+	require.NoError(t, l.Log(log.LevelWarn, "msg", "hello", "count", 3, "err", errors.New("boom")))
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, map[string]interface{}{
+		"level": "WARN",
+		"msg":   "hello",
+		"count": float64(3),
+		"err":   "boom",
+	}, line)
+}
+
+func TestFilter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewFilter(NewConsoleLogger(buf), log.LevelInfo, map[string]string{"nosql": "error"})
+
+	log.NewHelper(l).Debug("dropped")
+	log.NewHelper(l).Info("kept")
+	nosql := log.NewHelper(Module(l, "nosql"))
+	nosql.Warn("dropped")
+	nosql.Error("kept")
+	log.NewHelper(Module(l, "config")).Info("kept")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Equal(t, []string{
+		"INFO msg=kept",
+		"ERROR module=nosql msg=kept",
+		"INFO module=config msg=kept",
+	}, lines)
+}
+
+func TestSampler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewSampler(NewConsoleLogger(buf), time.Second, 2, 3).(*sampler)
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 8; i++ {
+		log.NewHelper(l).Info("repeat")
+	}
+	log.NewHelper(l).Info("other")
+	// 前2条 + 第5、8条 + other
+	require.Equal(t, 5, strings.Count(buf.String(), "\n"))
+
+	buf.Reset()
+	now = now.Add(time.Second)
+	log.NewHelper(l).Info("repeat")
+	require.Equal(t, "INFO msg=repeat\n", buf.String())
+}
+
+func TestTrace(t *testing.T) {
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	}))
+	dir := t.TempDir()
+	// 两个日志的Trace设置互不影响
+	on, closeOn := New(Options{Rotate: &Rotate{Filename: filepath.Join(dir, "on.log")}, Trace: true})
+	off, closeOff := New(Options{Rotate: &Rotate{Filename: filepath.Join(dir, "off.log")}})
+	NewHelper(ctx, Module(on, "greeter")).Info("hello")
+	NewHelper(ctx, Module(off, "greeter")).Info("hello")
+	NewHelper(context.Background(), on).Info("no span")
+	closeOn()
+	closeOff()
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "on.log"))
+	require.NoError(t, err)
+	require.Equal(t, "INFO trace_id=01000000000000000000000000000000 span_id=0200000000000000 module=greeter msg=hello\n"+
+		"INFO msg=no span\n", string(b))
+	b, err = ioutil.ReadFile(filepath.Join(dir, "off.log"))
+	require.NoError(t, err)
+	require.Equal(t, "INFO module=greeter msg=hello\n", string(b))
+}
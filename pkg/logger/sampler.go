@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var _ log.Logger = (*sampler)(nil)
+
+// sampler 对重复日志采样，相同级别和msg的日志在一个周期内只输出一部分
+type sampler struct {
+	logger     log.Logger
+	tick       time.Duration
+	initial    uint64
+	thereafter uint64
+	now        func() time.Time
+
+	mu     sync.Mutex
+	window time.Time
+	counts map[string]uint64
+}
+
+/*NewSampler 创建采样日志
+参数:
+*	l         	log.Logger
+*	tick      	time.Duration	采样周期
+*	initial   	int				每个周期内前initial条全部输出
+*	thereafter	int				之后每thereafter条输出一条，为0时全部丢弃
+返回值:
+*	log.Logger	log.Logger
+*/
+func NewSampler(l log.Logger, tick time.Duration, initial, thereafter int) log.Logger {
+	return &sampler{
+		logger:     l,
+		tick:       tick,
+		initial:    uint64(initial),
+		thereafter: uint64(thereafter),
+		now:        time.Now,
+		counts:     make(map[string]uint64),
+	}
+}
+
+// Log 没有msg字段的日志不采样
+func (s *sampler) Log(level log.Level, keyvals ...interface{}) error {
+	msg, ok := messageOf(keyvals)
+	if !ok || s.allow(level.String()+"|"+msg) {
+		return s.logger.Log(level, keyvals...)
+	}
+	return nil
+}
+
+func (s *sampler) allow(key string) bool {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.window) >= s.tick {
+		s.window = now.Truncate(s.tick)
+		s.counts = make(map[string]uint64, len(s.counts))
+	}
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.initial {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
+
+func messageOf(keyvals []interface{}) (string, bool) {
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if keyvals[i] == MessageKey {
+			return fmt.Sprint(keyvals[i+1]), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/logging"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TraceIDKey trace id字段
+	TraceIDKey = "trace_id"
+	// SpanIDKey span id字段
+	SpanIDKey = "span_id"
+)
+
+// WithContext 从ctx中取出trace_id/span_id加到日志中，ctx中没有span时原样返回
+// 是否输出由New时的Options.Trace决定，关闭时这两个字段在写入前被去掉
+func WithContext(ctx context.Context, l log.Logger) log.Logger {
+	span := trace.SpanContextFromContext(ctx)
+	if !span.IsValid() {
+		return l
+	}
+	return log.With(l, TraceIDKey, span.TraceID().String(), SpanIDKey, span.SpanID().String())
+}
+
+// NewHelper 带有trace_id/span_id的Helper
+func NewHelper(ctx context.Context, l log.Logger) *log.Helper {
+	return log.NewHelper(WithContext(ctx, l))
+}
+
+// Server 同logging.Server，请求日志带上trace_id/span_id，需要放在tracing.Server之后
+func Server(l log.Logger) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			return logging.Server(WithContext(ctx, l))(handler)(ctx, req)
+		}
+	}
+}
+
+var _ log.Logger = untrace{}
+
+// untrace 去掉trace_id/span_id字段，Options.Trace关闭时使用
+type untrace struct {
+	logger log.Logger
+}
+
+func (u untrace) Log(level log.Level, keyvals ...interface{}) error {
+	kvs := make([]interface{}, 0, len(keyvals))
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if keyvals[i] == TraceIDKey || keyvals[i] == SpanIDKey {
+			continue
+		}
+		kvs = append(kvs, keyvals[i], keyvals[i+1])
+	}
+	if len(keyvals)%2 == 1 {
+		kvs = append(kvs, keyvals[len(keyvals)-1])
+	}
+	return u.logger.Log(level, kvs...)
+}
@@ -0,0 +1,513 @@
+package mongocodec
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/type/decimal"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func init() {
+	Register("proto", func(b *Builder) {
+		b.Type((*decimal.Decimal)(nil), DecimalCodec{})
+		b.Interface((*protoreflect.Enum)(nil), EnumCodec{})
+		b.Interface((*proto.Message)(nil), MessageCodec{})
+	})
+}
+
+// EnumCodec proto枚举按名字保存，例如"CREATED"，枚举值调整顺序不影响已有数据
+// 解码时也接受数字，兼容按数字保存的历史数据
+type EnumCodec struct{}
+
+func (EnumCodec) EncodeValue(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return vw.WriteNull()
+		}
+		val = val.Elem()
+	}
+	e, ok := val.Interface().(protoreflect.Enum)
+	if !ok {
+		return bsoncodec.ValueEncoderError{Name: "EnumEncodeValue", Types: []reflect.Type{tEnum}, Received: val}
+	}
+	return encodeEnum(vw, e.Descriptor(), e.Number())
+}
+
+func (EnumCodec) DecodeValue(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() {
+		return bsoncodec.ValueDecoderError{Name: "EnumDecodeValue", Types: []reflect.Type{tEnum}, Received: val}
+	}
+	if val.Kind() == reflect.Ptr {
+		if vr.Type() == bsontype.Null {
+			val.Set(reflect.Zero(val.Type()))
+			return vr.ReadNull()
+		}
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		val = val.Elem()
+	}
+	e, ok := val.Interface().(protoreflect.Enum)
+	if !ok {
+		return bsoncodec.ValueDecoderError{Name: "EnumDecodeValue", Types: []reflect.Type{tEnum}, Received: val}
+	}
+	number, err := decodeEnum(vr, e.Descriptor())
+	if err != nil {
+		return err
+	}
+	val.SetInt(int64(number))
+	return nil
+}
+
+// MessageCodec proto消息保存为文档，字段名为json名(lowerCamelCase)，和其它模型的bson标签一致
+// 枚举按名字保存，Timestamp保存为DateTime，Decimal保存为Decimal128，只保存有值的字段
+type MessageCodec struct{}
+
+func (MessageCodec) EncodeValue(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if val.Kind() == reflect.Ptr && val.IsNil() {
+		return vw.WriteNull()
+	}
+	m, ok := val.Interface().(proto.Message)
+	if !ok {
+		return bsoncodec.ValueEncoderError{Name: "MessageEncodeValue", Types: []reflect.Type{tMessage}, Received: val}
+	}
+	return encodeMessage(vw, m.ProtoReflect())
+}
+
+func (MessageCodec) DecodeValue(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if val.Kind() != reflect.Ptr {
+		// 非指针的消息由registry取地址后传入
+		if !val.CanAddr() {
+			return bsoncodec.ValueDecoderError{Name: "MessageDecodeValue", Types: []reflect.Type{tMessage}, Received: val}
+		}
+		val = val.Addr()
+	} else if vr.Type() == bsontype.Null {
+		if !val.CanSet() {
+			return bsoncodec.ValueDecoderError{Name: "MessageDecodeValue", Types: []reflect.Type{tMessage}, Received: val}
+		}
+		val.Set(reflect.Zero(val.Type()))
+		return vr.ReadNull()
+	} else if val.IsNil() {
+		if !val.CanSet() {
+			return bsoncodec.ValueDecoderError{Name: "MessageDecodeValue", Types: []reflect.Type{tMessage}, Received: val}
+		}
+		val.Set(reflect.New(val.Type().Elem()))
+	}
+	m, ok := val.Interface().(proto.Message)
+	if !ok {
+		return bsoncodec.ValueDecoderError{Name: "MessageDecodeValue", Types: []reflect.Type{tMessage}, Received: val}
+	}
+	proto.Reset(m)
+	return decodeMessage(vr, m.ProtoReflect())
+}
+
+// DecimalCodec google.type.Decimal保存为Decimal128，可以在查询中比较大小和聚合计算
+// 解码时也接受字符串和数字
+type DecimalCodec struct{}
+
+func (DecimalCodec) EncodeValue(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if val.Type() != tDecimal {
+		return bsoncodec.ValueEncoderError{Name: "DecimalEncodeValue", Types: []reflect.Type{tDecimal}, Received: val}
+	}
+	if val.IsNil() {
+		return vw.WriteNull()
+	}
+	return encodeDecimal(vw, val.Interface().(*decimal.Decimal))
+}
+
+func (DecimalCodec) DecodeValue(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != tDecimal {
+		return bsoncodec.ValueDecoderError{Name: "DecimalDecodeValue", Types: []reflect.Type{tDecimal}, Received: val}
+	}
+	if vr.Type() == bsontype.Null {
+		val.Set(reflect.Zero(tDecimal))
+		return vr.ReadNull()
+	}
+	d := new(decimal.Decimal)
+	if err := decodeDecimal(vr, d); err != nil {
+		return err
+	}
+	val.Set(reflect.ValueOf(d))
+	return nil
+}
+
+var (
+	tEnum    = reflect.TypeOf((*protoreflect.Enum)(nil)).Elem()
+	tMessage = reflect.TypeOf((*proto.Message)(nil)).Elem()
+	tDecimal = reflect.TypeOf((*decimal.Decimal)(nil))
+)
+
+func encodeEnum(vw bsonrw.ValueWriter, ed protoreflect.EnumDescriptor, number protoreflect.EnumNumber) error {
+	if value := ed.Values().ByNumber(number); value != nil {
+		return vw.WriteString(string(value.Name()))
+	}
+	// 未知的枚举值保留数字
+	return vw.WriteInt32(int32(number))
+}
+
+func decodeEnum(vr bsonrw.ValueReader, ed protoreflect.EnumDescriptor) (protoreflect.EnumNumber, error) {
+	if vr.Type() == bsontype.String {
+		s, err := vr.ReadString()
+		if err != nil {
+			return 0, err
+		}
+		value := ed.Values().ByName(protoreflect.Name(s))
+		if value == nil {
+			return 0, fmt.Errorf("枚举[%s]没有值[%s]", ed.FullName(), s)
+		}
+		return value.Number(), nil
+	}
+	n, err := readInt(vr)
+	if err != nil {
+		return 0, fmt.Errorf("枚举[%s]: %w", ed.FullName(), err)
+	}
+	return protoreflect.EnumNumber(n), nil
+}
+
+func encodeDecimal(vw bsonrw.ValueWriter, d *decimal.Decimal) error {
+	value, err := primitive.ParseDecimal128(d.GetValue())
+	if err != nil {
+		return fmt.Errorf("错误的decimal[%s]: %w", d.GetValue(), err)
+	}
+	return vw.WriteDecimal128(value)
+}
+
+func decodeDecimal(vr bsonrw.ValueReader, d *decimal.Decimal) error {
+	switch vr.Type() {
+	case bsontype.Decimal128:
+		value, err := vr.ReadDecimal128()
+		if err != nil {
+			return err
+		}
+		d.Value = value.String()
+	case bsontype.String:
+		s, err := vr.ReadString()
+		if err != nil {
+			return err
+		}
+		if _, err = primitive.ParseDecimal128(s); err != nil {
+			return fmt.Errorf("错误的decimal[%s]: %w", s, err)
+		}
+		d.Value = s
+	case bsontype.Double:
+		f, err := vr.ReadDouble()
+		if err != nil {
+			return err
+		}
+		d.Value = strconv.FormatFloat(f, 'f', -1, 64)
+	default:
+		n, err := readInt(vr)
+		if err != nil {
+			return fmt.Errorf("decimal: %w", err)
+		}
+		d.Value = strconv.FormatInt(n, 10)
+	}
+	return nil
+}
+
+func encodeMessage(vw bsonrw.ValueWriter, m protoreflect.Message) error {
+	switch v := m.Interface().(type) {
+	case *timestamppb.Timestamp:
+		t := v.AsTime()
+		return vw.WriteDateTime(t.Unix()*1e3 + int64(t.Nanosecond()/1e6))
+	case *decimal.Decimal:
+		return encodeDecimal(vw, v)
+	}
+	dw, err := vw.WriteDocument()
+	if err != nil {
+		return err
+	}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		var evw bsonrw.ValueWriter
+		if evw, err = dw.WriteDocumentElement(fd.JSONName()); err != nil {
+			return false
+		}
+		err = encodeField(evw, fd, v)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return dw.WriteDocumentEnd()
+}
+
+func encodeField(vw bsonrw.ValueWriter, fd protoreflect.FieldDescriptor, v protoreflect.Value) error {
+	switch {
+	case fd.IsList():
+		aw, err := vw.WriteArray()
+		if err != nil {
+			return err
+		}
+		list := v.List()
+		for i := 0; i < list.Len(); i++ {
+			evw, err := aw.WriteArrayElement()
+			if err != nil {
+				return err
+			}
+			if err = encodeSingular(evw, fd, list.Get(i)); err != nil {
+				return err
+			}
+		}
+		return aw.WriteArrayEnd()
+	case fd.IsMap():
+		dw, err := vw.WriteDocument()
+		if err != nil {
+			return err
+		}
+		// map的遍历顺序不固定，排序后编码结果稳定
+		keys := make([]protoreflect.MapKey, 0, v.Map().Len())
+		v.Map().Range(func(key protoreflect.MapKey, _ protoreflect.Value) bool {
+			keys = append(keys, key)
+			return true
+		})
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			evw, err := dw.WriteDocumentElement(key.String())
+			if err != nil {
+				return err
+			}
+			if err = encodeSingular(evw, fd.MapValue(), v.Map().Get(key)); err != nil {
+				return err
+			}
+		}
+		return dw.WriteDocumentEnd()
+	default:
+		return encodeSingular(vw, fd, v)
+	}
+}
+
+func encodeSingular(vw bsonrw.ValueWriter, fd protoreflect.FieldDescriptor, v protoreflect.Value) error {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return vw.WriteBoolean(v.Bool())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return vw.WriteInt32(int32(v.Int()))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return vw.WriteInt64(v.Int())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// bson没有无符号整数
+		if v.Uint() > math.MaxInt64 {
+			return fmt.Errorf("字段[%s]的值%d超出int64", fd.FullName(), v.Uint())
+		}
+		return vw.WriteInt64(int64(v.Uint()))
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return vw.WriteDouble(v.Float())
+	case protoreflect.StringKind:
+		return vw.WriteString(v.String())
+	case protoreflect.BytesKind:
+		return vw.WriteBinary(v.Bytes())
+	case protoreflect.EnumKind:
+		return encodeEnum(vw, fd.Enum(), v.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return encodeMessage(vw, v.Message())
+	default:
+		return fmt.Errorf("字段[%s]的类型[%s]不支持", fd.FullName(), fd.Kind())
+	}
+}
+
+func decodeMessage(vr bsonrw.ValueReader, m protoreflect.Message) error {
+	switch v := m.Interface().(type) {
+	case *timestamppb.Timestamp:
+		ms, err := vr.ReadDateTime()
+		if err != nil {
+			return err
+		}
+		t := time.Unix(ms/1e3, ms%1e3*1e6)
+		v.Seconds, v.Nanos = t.Unix(), int32(t.Nanosecond())
+		return nil
+	case *decimal.Decimal:
+		return decodeDecimal(vr, v)
+	}
+	dr, err := vr.ReadDocument()
+	if err != nil {
+		return err
+	}
+	fields := m.Descriptor().Fields()
+	for {
+		name, evr, err := dr.ReadElement()
+		if err == bsonrw.ErrEOD {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fd := fields.ByJSONName(name)
+		if fd == nil {
+			fd = fields.ByName(protoreflect.Name(name))
+		}
+		if fd == nil {
+			// 消息中已经删除的字段
+			if err = evr.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = decodeField(evr, m, fd); err != nil {
+			return fmt.Errorf("字段[%s]: %w", fd.FullName(), err)
+		}
+	}
+}
+
+func decodeField(vr bsonrw.ValueReader, m protoreflect.Message, fd protoreflect.FieldDescriptor) error {
+	if vr.Type() == bsontype.Null {
+		m.Clear(fd)
+		return vr.ReadNull()
+	}
+	switch {
+	case fd.IsList():
+		ar, err := vr.ReadArray()
+		if err != nil {
+			return err
+		}
+		list := m.Mutable(fd).List()
+		for {
+			evr, err := ar.ReadValue()
+			if err == bsonrw.ErrEOA {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			v, err := decodeSingular(evr, fd, list.NewElement())
+			if err != nil {
+				return err
+			}
+			list.Append(v)
+		}
+	case fd.IsMap():
+		dr, err := vr.ReadDocument()
+		if err != nil {
+			return err
+		}
+		mp := m.Mutable(fd).Map()
+		for {
+			name, evr, err := dr.ReadElement()
+			if err == bsonrw.ErrEOD {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			key, err := mapKey(fd.MapKey(), name)
+			if err != nil {
+				return err
+			}
+			v, err := decodeSingular(evr, fd.MapValue(), mp.NewValue())
+			if err != nil {
+				return err
+			}
+			mp.Set(key, v)
+		}
+	default:
+		v, err := decodeSingular(vr, fd, m.NewField(fd))
+		if err != nil {
+			return err
+		}
+		m.Set(fd, v)
+		return nil
+	}
+}
+
+// decodeSingular empty为字段的新值，消息类型时解码到它里面
+func decodeSingular(vr bsonrw.ValueReader, fd protoreflect.FieldDescriptor, empty protoreflect.Value) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		b, err := vr.ReadBoolean()
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := readInt(vr)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := readInt(vr)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := readInt(vr)
+		return protoreflect.ValueOfUint32(uint32(n)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := readInt(vr)
+		return protoreflect.ValueOfUint64(uint64(n)), err
+	case protoreflect.FloatKind:
+		f, err := readFloat(vr)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := readFloat(vr)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.StringKind:
+		s, err := vr.ReadString()
+		return protoreflect.ValueOfString(s), err
+	case protoreflect.BytesKind:
+		b, _, err := vr.ReadBinary()
+		return protoreflect.ValueOfBytes(b), err
+	case protoreflect.EnumKind:
+		n, err := decodeEnum(vr, fd.Enum())
+		return protoreflect.ValueOfEnum(n), err
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return empty, decodeMessage(vr, empty.Message())
+	default:
+		return empty, fmt.Errorf("类型[%s]不支持", fd.Kind())
+	}
+}
+
+func mapKey(fd protoreflect.FieldDescriptor, s string) (protoreflect.MapKey, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s).MapKey(), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(b).MapKey(), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(n)).MapKey(), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(n).MapKey(), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(n)).MapKey(), err
+	default:
+		n, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(n).MapKey(), err
+	}
+}
+
+// readInt 接受bson的int32、int64和整数值的double
+func readInt(vr bsonrw.ValueReader) (int64, error) {
+	switch vr.Type() {
+	case bsontype.Int32:
+		n, err := vr.ReadInt32()
+		return int64(n), err
+	case bsontype.Int64:
+		return vr.ReadInt64()
+	case bsontype.Double:
+		f, err := vr.ReadDouble()
+		if err != nil {
+			return 0, err
+		}
+		if f != math.Trunc(f) {
+			return 0, fmt.Errorf("%v不是整数", f)
+		}
+		return int64(f), nil
+	default:
+		return 0, fmt.Errorf("错误的类型[%s],不是整数", vr.Type())
+	}
+}
+
+// readFloat 接受bson的double、int32和int64
+func readFloat(vr bsonrw.ValueReader) (float64, error) {
+	if vr.Type() == bsontype.Double {
+		return vr.ReadDouble()
+	}
+	n, err := readInt(vr)
+	return float64(n), err
+}
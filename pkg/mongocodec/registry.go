@@ -0,0 +1,138 @@
+package mongocodec
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+)
+
+// Builder 组装mongo客户端使用的registry，在驱动默认编解码的基础上注册自定义codec
+type Builder struct {
+	rb    *bsoncodec.RegistryBuilder
+	owner string                  // 当前正在注册的包，用于冲突时的提示
+	types map[reflect.Type]string // 已经注册的类型和接口 -> 注册它的包
+}
+
+// NewBuilder 包含驱动默认的编解码
+func NewBuilder() *Builder {
+	return &Builder{rb: bson.NewRegistryBuilder(), types: make(map[reflect.Type]string)}
+}
+
+/*Type 为具体类型注册codec，类型必须完全一致，同一个类型只能注册一次
+参数:
+*	sample	interface{}	类型的值，例如tools.Time{}、(*decimal.Decimal)(nil)
+*	codec 	bsoncodec.ValueCodec
+返回值:
+*	*Builder	*Builder
+*/
+func (b *Builder) Type(sample interface{}, codec bsoncodec.ValueCodec) *Builder {
+	t := reflect.TypeOf(sample)
+	b.claim(t)
+	b.rb.RegisterTypeEncoder(t, codec).RegisterTypeDecoder(t, codec)
+	return b
+}
+
+/*Interface 为实现了接口的类型注册codec，例如全部的proto.Message，优先级低于Type
+参数:
+*	iface	interface{}	接口的指针，例如(*proto.Message)(nil)
+*	codec	bsoncodec.ValueCodec
+返回值:
+*	*Builder	*Builder
+*/
+func (b *Builder) Interface(iface interface{}, codec bsoncodec.ValueCodec) *Builder {
+	t := reflect.TypeOf(iface)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("mongocodec: %s注册的%T不是接口的指针", b.owner, iface))
+	}
+	t = t.Elem()
+	b.claim(t)
+	b.rb.RegisterHookEncoder(t, codec).RegisterHookDecoder(t, codec)
+	return b
+}
+
+// claim 同一个类型被两个包注册时后注册的会静默覆盖，这里直接panic
+func (b *Builder) claim(t reflect.Type) {
+	if owner, exist := b.types[t]; exist {
+		panic(fmt.Sprintf("mongocodec: %s已经由%s注册，%s不能重复注册", t, owner, b.owner))
+	}
+	b.types[t] = b.owner
+}
+
+// Build .
+func (b *Builder) Build() *bsoncodec.Registry {
+	return b.rb.Build()
+}
+
+type registrar struct {
+	name string
+	fn   func(b *Builder)
+}
+
+var (
+	mu         sync.Mutex
+	registrars []registrar
+	built      *bsoncodec.Registry
+)
+
+/*Register 包在init中注册自己的codec，NewMongoDB使用Default组装后传给客户端
+参数:
+*	name	string	包名，不能重复
+*	fn  	func(b *Builder)	使用b.Type、b.Interface注册
+*/
+func Register(name string, fn func(b *Builder)) {
+	mu.Lock()
+	defer mu.Unlock()
+	if built != nil {
+		panic(fmt.Sprintf("mongocodec: registry已经组装，%s应该在init中注册", name))
+	}
+	for _, r := range registrars {
+		if r.name == name {
+			panic(fmt.Sprintf("mongocodec: %s重复注册", name))
+		}
+	}
+	registrars = append(registrars, registrar{name: name, fn: fn})
+}
+
+// Names 已经注册的包，按注册顺序
+func Names() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	names := make([]string, 0, len(registrars))
+	for _, r := range registrars {
+		names = append(names, r.name)
+	}
+	return names
+}
+
+// Default 组装全部注册的codec，只组装一次，之后不能再Register
+func Default() *bsoncodec.Registry {
+	mu.Lock()
+	defer mu.Unlock()
+	if built == nil {
+		built = build(registrars, nil)
+	}
+	return built
+}
+
+// Build 每次重新组装，extra在注册的codec之后执行，用于需要额外codec的客户端和测试
+func Build(extra ...func(b *Builder)) *bsoncodec.Registry {
+	mu.Lock()
+	defer mu.Unlock()
+	return build(registrars, extra)
+}
+
+func build(registrars []registrar, extra []func(b *Builder)) *bsoncodec.Registry {
+	b := NewBuilder()
+	for _, r := range registrars {
+		b.owner = r.name
+		r.fn(b)
+	}
+	b.owner = "extra"
+	for _, fn := range extra {
+		fn(b)
+	}
+	return b.Build()
+}
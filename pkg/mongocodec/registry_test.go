@@ -0,0 +1,214 @@
+package mongocodec_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	commonv1 "github.com/go-kratos/kratos-layout/api/common/v1"
+	v1 "github.com/go-kratos/kratos-layout/api/helloworld/v1"
+	"github.com/go-kratos/kratos-layout/pkg/mongocodec"
+	"github.com/go-kratos/kratos-layout/pkg/objectid"
+	"github.com/go-kratos/kratos-layout/pkg/tools"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"google.golang.org/genproto/googleapis/type/decimal"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// roundTrip 编码后检查字段的bson类型，再解码到out
+func roundTrip(t *testing.T, registry *bsoncodec.Registry, in, out interface{}, types map[string]bsontype.Type) bson.Raw {
+	b, err := bson.MarshalWithRegistry(registry, in)
+	require.NoError(t, err)
+	raw := bson.Raw(b)
+	for key, bt := range types {
+		require.Equal(t, bt, raw.Lookup(key).Type, key)
+	}
+	require.NoError(t, bson.UnmarshalWithRegistry(registry, b, out))
+	return raw
+}
+
+func TestRegistered(t *testing.T) {
+	require.Subset(t, mongocodec.Names(), []string{"proto", "tools", "objectid"})
+	require.Same(t, mongocodec.Default(), mongocodec.Default())
+	require.Panics(t, func() { mongocodec.Register("late", func(b *mongocodec.Builder) {}) })
+}
+
+func TestTime(t *testing.T) {
+	type doc struct {
+		T     tools.Time     `bson:"t"`
+		Zero  tools.Time     `bson:"zero"`
+		Null  tools.NullTime `bson:"null"`
+		Valid tools.NullTime `bson:"valid"`
+	}
+	tm := tools.Time(time.Date(2021, 6, 16, 2, 30, 0, 123e6, time.UTC))
+	in := doc{T: tm, Valid: tools.NullTimeOf(tm)}
+	var out doc
+	roundTrip(t, mongocodec.Default(), in, &out, map[string]bsontype.Type{
+		"t": bsontype.DateTime, "zero": bsontype.DateTime, "null": bsontype.Null, "valid": bsontype.DateTime,
+	})
+	require.True(t, time.Time(tm).Equal(time.Time(out.T)))
+	require.True(t, out.Zero.IsZero())
+	require.False(t, out.Null.Valid)
+	require.True(t, out.Valid.Valid)
+	require.True(t, time.Time(tm).Equal(time.Time(out.Valid.Time)))
+
+	// null和历史数据中的字符串
+	b, err := bson.Marshal(bson.M{"t": "2021-06-16 10:30:00.123", "zero": nil})
+	require.NoError(t, err)
+	require.Error(t, bson.UnmarshalWithRegistry(mongocodec.Default(), b, &out))
+	b, err = bson.Marshal(bson.M{"t": "2021-06-16T02:30:00.123Z", "zero": nil})
+	require.NoError(t, err)
+	out = doc{Zero: tools.Now()}
+	require.NoError(t, bson.UnmarshalWithRegistry(mongocodec.Default(), b, &out))
+	require.True(t, time.Time(tm).Equal(time.Time(out.T)))
+	require.True(t, out.Zero.IsZero())
+}
+
+func TestObjectID(t *testing.T) {
+	type doc struct {
+		ID  objectid.ObjectID `bson:"_id,omitempty"`
+		Ref objectid.ObjectID `bson:"ref"`
+	}
+	in := doc{ID: objectid.New(), Ref: objectid.New()}
+	var out doc
+	roundTrip(t, mongocodec.Default(), in, &out, map[string]bsontype.Type{"_id": bsontype.ObjectID, "ref": bsontype.ObjectID})
+	require.Equal(t, in, out)
+	// 空的id是错误
+	_, err := bson.MarshalWithRegistry(mongocodec.Default(), struct {
+		Ref objectid.ObjectID `bson:"ref"`
+	}{})
+	require.ErrorIs(t, err, objectid.ErrEmpty)
+}
+
+func TestEnum(t *testing.T) {
+	type doc struct {
+		Type    v1.GreeterEvent_Type   `bson:"type"`
+		Ptr     *v1.GreeterEvent_Type  `bson:"ptr"`
+		Nil     *v1.GreeterEvent_Type  `bson:"nil"`
+		Types   []v1.GreeterEvent_Type `bson:"types"`
+		Unknown v1.GreeterEvent_Type   `bson:"unknown"`
+	}
+	updated := v1.GreeterEvent_UPDATED
+	in := doc{Type: v1.GreeterEvent_CREATED, Ptr: &updated, Types: []v1.GreeterEvent_Type{v1.GreeterEvent_DELETED}, Unknown: 99}
+	var out doc
+	raw := roundTrip(t, mongocodec.Default(), in, &out, map[string]bsontype.Type{
+		"type": bsontype.String, "ptr": bsontype.String, "nil": bsontype.Null, "unknown": bsontype.Int32,
+	})
+	require.Equal(t, "CREATED", raw.Lookup("type").StringValue())
+	require.Equal(t, "DELETED", raw.Lookup("types", "0").StringValue())
+	require.Equal(t, in, out)
+
+	// 按数字保存的历史数据
+	b, err := bson.Marshal(bson.M{"type": int32(2), "ptr": int64(3)})
+	require.NoError(t, err)
+	out = doc{}
+	require.NoError(t, bson.UnmarshalWithRegistry(mongocodec.Default(), b, &out))
+	require.Equal(t, v1.GreeterEvent_UPDATED, out.Type)
+	require.Equal(t, v1.GreeterEvent_DELETED, *out.Ptr)
+
+	b, err = bson.Marshal(bson.M{"type": "MOVED"})
+	require.NoError(t, err)
+	require.Error(t, bson.UnmarshalWithRegistry(mongocodec.Default(), b, &out))
+}
+
+func TestDecimal(t *testing.T) {
+	type doc struct {
+		Amount *decimal.Decimal `bson:"amount"`
+		Nil    *decimal.Decimal `bson:"nil"`
+	}
+	in := doc{Amount: &decimal.Decimal{Value: "-12345678901234567890.30"}}
+	var out doc
+	roundTrip(t, mongocodec.Default(), in, &out, map[string]bsontype.Type{"amount": bsontype.Decimal128, "nil": bsontype.Null})
+	require.Equal(t, "-12345678901234567890.30", out.Amount.GetValue())
+	require.Nil(t, out.Nil)
+
+	for value, expect := range map[interface{}]string{"1.5": "1.5", 2.25: "2.25", int32(3): "3", int64(4): "4"} {
+		b, err := bson.Marshal(bson.M{"amount": value})
+		require.NoError(t, err)
+		require.NoError(t, bson.UnmarshalWithRegistry(mongocodec.Default(), b, &out))
+		require.Equal(t, expect, out.Amount.GetValue())
+	}
+	_, err := bson.MarshalWithRegistry(mongocodec.Default(), doc{Amount: &decimal.Decimal{Value: "1,5"}})
+	require.Error(t, err)
+}
+
+func TestMessage(t *testing.T) {
+	s, err := structpb.NewStruct(map[string]interface{}{
+		"name": "kratos", "count": 3.0, "ok": true, "none": nil,
+		"tags":   []interface{}{"a", 1.0},
+		"nested": map[string]interface{}{"x": "y"},
+	})
+	require.NoError(t, err)
+	cases := []struct {
+		name  string
+		in    proto.Message
+		out   proto.Message
+		types map[string]bsontype.Type // 字段路径 -> 类型
+	}{
+		{"枚举、嵌套和时间", &v1.GreeterEvent{
+			Type:        v1.GreeterEvent_UPDATED,
+			Greeter:     &v1.GreeterReply{Id: objectid.New().Hex(), Hello: "hi", CreateTime: timestamppb.New(time.Date(2021, 6, 16, 2, 30, 0, 123e6, time.UTC))},
+			ResumeToken: "t1",
+		}, new(v1.GreeterEvent), map[string]bsontype.Type{
+			"type": bsontype.String, "greeter.createTime": bsontype.DateTime, "resumeToken": bsontype.String,
+		}},
+		{"map、repeated和json名", &commonv1.ListRequest{
+			Filter:    map[string]string{"hello": "hi", "createTime": "today"},
+			Sort:      []string{"-createTime", "+hello"},
+			Limit:     20,
+			PageToken: "token",
+			FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"id", "hello"}},
+		}, new(commonv1.ListRequest), map[string]bsontype.Type{
+			"filter.createTime": bsontype.String, "sort": bsontype.Array, "limit": bsontype.Int32, "pageToken": bsontype.String, "fieldMask.paths": bsontype.Array,
+		}},
+		{"repeated枚举", &v1.WatchGreetersRequest{Types: []v1.GreeterEvent_Type{v1.GreeterEvent_CREATED, v1.GreeterEvent_DELETED}},
+			new(v1.WatchGreetersRequest), map[string]bsontype.Type{"types.1": bsontype.String}},
+		{"oneof", s, new(structpb.Struct), map[string]bsontype.Type{
+			"fields.count.numberValue": bsontype.Double, "fields.none.nullValue": bsontype.String, "fields.tags.listValue.values": bsontype.Array,
+		}},
+		{"decimal", &decimal.Decimal{Value: "0.10"}, new(decimal.Decimal), nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b, err := bson.MarshalWithRegistry(mongocodec.Default(), bson.M{"m": c.in})
+			require.NoError(t, err)
+			m := bson.Raw(b).Lookup("m")
+			for path, bt := range c.types {
+				require.Equal(t, bt, m.Document().Lookup(strings.Split(path, ".")...).Type, path)
+			}
+			require.NoError(t, m.UnmarshalWithRegistry(mongocodec.Default(), c.out))
+			require.True(t, proto.Equal(c.in, c.out), "%v\n%v", c.in, c.out)
+		})
+	}
+
+	// 指针字段，null为nil，已经删除的字段忽略
+	type doc struct {
+		Event *v1.GreeterEvent `bson:"event"`
+		Nil   *v1.GreeterEvent `bson:"nil"`
+	}
+	b, err := bson.Marshal(bson.M{"event": bson.M{"type": "CREATED", "removed": 1}, "nil": nil})
+	require.NoError(t, err)
+	out := doc{Nil: &v1.GreeterEvent{}}
+	require.NoError(t, bson.UnmarshalWithRegistry(mongocodec.Default(), b, &out))
+	require.Equal(t, v1.GreeterEvent_CREATED, out.Event.GetType())
+	require.Nil(t, out.Nil)
+}
+
+func TestBuilder(t *testing.T) {
+	require.Panics(t, func() {
+		mongocodec.Build(func(b *mongocodec.Builder) { b.Type(tools.Time{}, tools.TimeCodec{}) })
+	})
+	require.Panics(t, func() {
+		mongocodec.Build(func(b *mongocodec.Builder) { b.Interface(proto.Message(nil), mongocodec.MessageCodec{}) })
+	})
+	// extra可以为单独的客户端加入codec
+	type custom string
+	registry := mongocodec.Build(func(b *mongocodec.Builder) { b.Type(custom(""), objectid.Codec{}) })
+	require.NotNil(t, registry)
+}
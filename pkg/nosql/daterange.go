@@ -0,0 +1,198 @@
+package nosql
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/tools"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// 日期范围的关键字，按tools.Location()的日历计算
+const (
+	RangeToday     = "today"
+	RangeYesterday = "yesterday"
+	RangeThisWeek  = "this_week"
+	RangeLastWeek  = "last_week"
+	RangeThisMonth = "this_month"
+	RangeLastMonth = "last_month"
+	RangeThisYear  = "this_year"
+	RangeLastYear  = "last_year"
+)
+
+// rangeSeparator 日期范围开始和结束的分隔符
+const rangeSeparator = "~"
+
+/*ParseDateRange 解析日期范围，返回[start, end)，start或end为零值时该边界不限制
+支持的格式:
+*	a~b       	a、b为"2006-01"、"2006-01-02"、"2006-01-02 15"、"2006-01-02 15:04"或"2006-01-02 15:04:05"，按精度包含b，例如"2021-06-01~2021-06-30"的end为2021-07-01 00:00，省略a或b时不限制该边界
+*	a         	同a~a，例如"2021-06"为整个6月
+*	关键字    	today、yesterday、this_week、last_week、this_month、last_month、this_year、last_year，一周从周一开始
+*	last_Nd   	包括今天的最近N天
+*	last_Nh   	最近N小时，到now为止
+参数:
+*	data	string
+*	now 	time.Time	计算关键字的当前时间
+返回值:
+*	start	time.Time
+*	end  	time.Time
+*	err  	error
+*/
+func ParseDateRange(data string, now time.Time) (start, end time.Time, err error) {
+	data = strings.TrimSpace(data)
+	if data == "" {
+		return start, end, errors.New("日期范围为空")
+	}
+	if i := strings.Index(data, rangeSeparator); i >= 0 {
+		from, to := strings.TrimSpace(data[:i]), strings.TrimSpace(data[i+len(rangeSeparator):])
+		if from == "" && to == "" {
+			return start, end, errors.Errorf("日期范围[%s]没有边界", data)
+		}
+		if from != "" {
+			if start, _, err = parsePeriod(from); err != nil {
+				return
+			}
+		}
+		if to != "" {
+			if _, end, err = parsePeriod(to); err != nil {
+				return
+			}
+		}
+		if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+			return time.Time{}, time.Time{}, errors.Errorf("日期范围[%s]的开始晚于结束", data)
+		}
+		return
+	}
+	if start, end, ok := keywordRange(strings.ToLower(data), now); ok {
+		return start, end, nil
+	}
+	return parsePeriod(data)
+}
+
+// parsePeriod 按精度解析一段时间，例如"2021-06"为[2021-06-01, 2021-07-01)
+func parsePeriod(data string) (start, end time.Time, err error) {
+	parsed, err := tools.ParseTimeInLength(data)
+	if err != nil {
+		return start, end, errors.Wrapf(err, "解析日期[%s]", data)
+	}
+	start = time.Time(parsed)
+	switch len(data) {
+	case 7:
+		end = start.AddDate(0, 1, 0)
+	case 10:
+		end = start.AddDate(0, 0, 1)
+	case 13:
+		end = start.Add(time.Hour)
+	case 16:
+		end = start.Add(time.Minute)
+	case 19:
+		end = start.Add(time.Second)
+	default:
+		return start, end, errors.Errorf("日期[%s]的格式不支持", data)
+	}
+	return
+}
+
+// keywordRange 关键字对应的范围，天以上的边界用AddDate计算，夏令时的日期也是整天
+func keywordRange(keyword string, now time.Time) (start, end time.Time, ok bool) {
+	now = now.In(tools.Location())
+	today := time.Time(tools.Time(now).DayStart())
+	switch keyword {
+	case RangeToday:
+		return today, today.AddDate(0, 0, 1), true
+	case RangeYesterday:
+		return today.AddDate(0, 0, -1), today, true
+	case RangeThisWeek, RangeLastWeek:
+		start = time.Time(tools.Time(now).WeekStart())
+		if keyword == RangeLastWeek {
+			start = start.AddDate(0, 0, -7)
+		}
+		return start, start.AddDate(0, 0, 7), true
+	case RangeThisMonth, RangeLastMonth:
+		start = time.Time(tools.Time(now).MonthStart())
+		if keyword == RangeLastMonth {
+			start = start.AddDate(0, -1, 0)
+		}
+		return start, start.AddDate(0, 1, 0), true
+	case RangeThisYear, RangeLastYear:
+		start = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, tools.Location())
+		if keyword == RangeLastYear {
+			start = start.AddDate(-1, 0, 0)
+		}
+		return start, start.AddDate(1, 0, 0), true
+	}
+	if !strings.HasPrefix(keyword, "last_") || len(keyword) < len("last_0d") {
+		return
+	}
+	n, err := strconv.Atoi(keyword[len("last_") : len(keyword)-1])
+	if err != nil || n <= 0 {
+		return
+	}
+	switch keyword[len(keyword)-1] {
+	case 'd':
+		return today.AddDate(0, 0, 1-n), today.AddDate(0, 0, 1), true
+	case 'h':
+		return now.Add(-time.Duration(n) * time.Hour), now, true
+	}
+	return
+}
+
+/*DateRangeConvert 日期范围的动态条件，配合DateRangeSpec使用
+参数:
+*	field	string	查询的字段
+返回值:
+*	Convert	Convert	结果为bson.M{field: {$gte: start, $lt: end}}，不限制的边界省略
+*/
+func DateRangeConvert(field string) Convert {
+	return func(data string) (interface{}, error) {
+		start, end, err := ParseDateRange(data, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		condition := bson.M{}
+		if !start.IsZero() {
+			condition[GTE] = start
+		}
+		if !end.IsZero() {
+			condition[LT] = end
+		}
+		return bson.M{field: condition}, nil
+	}
+}
+
+// DateRangeSpec field在日期范围内的查询规则，值的格式见ParseDateRange
+func DateRangeSpec(field string) DbSpec {
+	return DbSpec{Dynamic: true, Convert: DateRangeConvert(field)}
+}
+
+/*DateFromParse 日期范围的开始，配合$gte使用，例如"2021-06-01"和"this_week"
+参数:
+*	data	string
+返回值:
+*	result	interface{}	time.Time
+*	err   	error
+*/
+func DateFromParse(data string) (result interface{}, err error) {
+	start, _, err := ParseDateRange(data, time.Now())
+	if err == nil && start.IsZero() {
+		err = errors.Errorf("日期范围[%s]没有开始", data)
+	}
+	return start, err
+}
+
+/*DateToParse 日期范围的结束，配合$lt使用，"2021-06-30"的结果为2021-07-01 00:00
+参数:
+*	data	string
+返回值:
+*	result	interface{}	time.Time
+*	err   	error
+*/
+func DateToParse(data string) (result interface{}, err error) {
+	_, end, err := ParseDateRange(data, time.Now())
+	if err == nil && end.IsZero() {
+		err = errors.Errorf("日期范围[%s]没有结束", data)
+	}
+	return end, err
+}
@@ -0,0 +1,75 @@
+package nosql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/tools"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseDateRange(t *testing.T) {
+	loc := tools.Location()
+	date := func(y int, m time.Month, d, h int) time.Time {
+		return time.Date(y, m, d, h, 0, 0, 0, loc)
+	}
+	// 2021-06-16 周三 10:30，UTC时间还是前一天时按业务时区计算
+	now := time.Date(2021, 6, 16, 2, 30, 0, 0, time.UTC)
+	cases := []struct {
+		data       string
+		start, end time.Time
+	}{
+		{"2021-06-01~2021-06-30", date(2021, 6, 1, 0), date(2021, 7, 1, 0)},
+		{"2021-06-01 08~2021-06-01 17", date(2021, 6, 1, 8), date(2021, 6, 1, 18)},
+		{"2021-06~", date(2021, 6, 1, 0), time.Time{}},
+		{" ~ 2021-12", time.Time{}, date(2022, 1, 1, 0)},
+		{"2021-02", date(2021, 2, 1, 0), date(2021, 3, 1, 0)},
+		{"2021-06-16", date(2021, 6, 16, 0), date(2021, 6, 17, 0)},
+		{"today", date(2021, 6, 16, 0), date(2021, 6, 17, 0)},
+		{"Yesterday", date(2021, 6, 15, 0), date(2021, 6, 16, 0)},
+		{"this_week", date(2021, 6, 14, 0), date(2021, 6, 21, 0)},
+		{"last_week", date(2021, 6, 7, 0), date(2021, 6, 14, 0)},
+		{"this_month", date(2021, 6, 1, 0), date(2021, 7, 1, 0)},
+		{"last_month", date(2021, 5, 1, 0), date(2021, 6, 1, 0)},
+		{"this_year", date(2021, 1, 1, 0), date(2022, 1, 1, 0)},
+		{"last_year", date(2020, 1, 1, 0), date(2021, 1, 1, 0)},
+		{"last_7d", date(2021, 6, 10, 0), date(2021, 6, 17, 0)},
+		{"last_1d", date(2021, 6, 16, 0), date(2021, 6, 17, 0)},
+		{"last_24h", now.Add(-24 * time.Hour), now},
+	}
+	for _, c := range cases {
+		start, end, err := ParseDateRange(c.data, now)
+		require.NoError(t, err, c.data)
+		require.True(t, c.start.Equal(start), "%s start: %s", c.data, start)
+		require.True(t, c.end.Equal(end), "%s end: %s", c.data, end)
+	}
+
+	for _, data := range []string{"", "~", "2021-06-30~2021-06-01", "2021/06/01", "last_0d", "last_xd", "next_week", "2021-06-01 08:0"} {
+		_, _, err := ParseDateRange(data, now)
+		require.Error(t, err, data)
+	}
+}
+
+func TestDateRangeSpec(t *testing.T) {
+	specs := QuerySpec{
+		"createTime": DateRangeSpec("createTime"),
+		"from":       {Field: "updateTime", Op: GTE, Convert: DateFromParse},
+		"to":         {Field: "updateTime", Op: LT, Convert: DateToParse},
+	}
+	query, err := BuildQuery(map[string]interface{}{
+		"createTime": "2021-06-01~2021-06-30",
+		"from":       "2021-06-01",
+		"to":         "2021-06-30",
+	}, specs, true)
+	require.NoError(t, err)
+	start := time.Date(2021, 6, 1, 0, 0, 0, 0, tools.Location())
+	end := time.Date(2021, 7, 1, 0, 0, 0, 0, tools.Location())
+	require.Equal(t, bson.M{
+		"createTime": map[string]interface{}{GTE: start, LT: end},
+		"updateTime": map[string]interface{}{GTE: start, LT: end},
+	}, query)
+
+	_, err = BuildQuery(map[string]interface{}{"to": "2021-06-01~"}, specs, true)
+	require.Error(t, err)
+}
@@ -3,12 +3,12 @@ package nosql
 import (
 	"context"
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
@@ -30,55 +30,132 @@ type Index struct {
 	Data    mongo.IndexModel // 索引信息
 }
 
-func EnsureIndex(collection *mongo.Collection, indexes []Index) error {
-	bg := context.Background()
-	iterator, err := collection.Indexes().List(bg)
+// IndexAction 索引变更类型
+type IndexAction string
+
+const (
+	// IndexKeep 索引已存在且版本相同
+	IndexKeep IndexAction = "keep"
+	// IndexCreate 索引不存在，需要创建
+	IndexCreate IndexAction = "create"
+	// IndexRecreate 索引版本不同，需要删除旧版本后创建
+	IndexRecreate IndexAction = "recreate"
+)
+
+// IndexChange 单个索引的变更计划
+type IndexChange struct {
+	Collection  string      // 集合名
+	Name        string      // 索引名
+	Action      IndexAction // 变更类型
+	FromVersion int         // 现有版本，不存在时为0
+	ToVersion   int         // 期望版本
+
+	existName string
+	index     Index
+}
+
+func (c IndexChange) String() string {
+	return fmt.Sprintf("%s.%s: %s v%d -> v%d", c.Collection, c.Name, c.Action, c.FromVersion, c.ToVersion)
+}
+
+/*PlanIndex 对比集合现有的索引，生成变更计划，不修改数据库
+参数:
+*	ctx       	context.Context
+*	collection	*mongo.Collection
+*	indexes   	[]Index				期望的索引
+返回值:
+*	[]IndexChange	[]IndexChange
+*	error        	error
+*/
+func PlanIndex(ctx context.Context, collection *mongo.Collection, indexes []Index) ([]IndexChange, error) {
+	iterator, err := collection.Indexes().List(ctx)
 	if err != nil {
-		return errors.Wrap(err, "查询索引")
+		return nil, errors.Wrap(err, "查询索引")
 	}
 	existIndexes := make(map[string]*MongoIndex)
 	defer func() {
-		if err = iterator.Close(bg); err != nil {
-			log.Printf("关闭迭代器失败: %+v", err)
+		if err := iterator.Close(ctx); err != nil {
+			helper.Errorf("关闭迭代器失败: %+v", err)
 		}
 	}()
 
-	for iterator.Next(bg) {
+	for iterator.Next(ctx) {
 		index := &MongoIndex{}
 		if err = iterator.Decode(index); err != nil {
-			return errors.Wrap(err, "解码索引")
+			return nil, errors.Wrap(err, "解码索引")
 		}
 
 		if index.Name != "_id_" {
 			fields := strings.Split(index.Name, IndexVersionDelimiter)
 			if len(fields) != 2 {
-				return fmt.Errorf("索引名称错误，应该为[索引名%s版本],实际为[%s]", IndexVersionDelimiter, index.Name)
+				return nil, fmt.Errorf("索引名称错误，应该为[索引名%s版本],实际为[%s]", IndexVersionDelimiter, index.Name)
 			}
-			log.Printf("查询到索引, 集合名: %s,索引: %v,索引元数据: %s", collection.Name(), index, iterator.Current.String())
+			helper.Infof("查询到索引, 集合名: %s,索引: %v,索引元数据: %s", collection.Name(), index, iterator.Current.String())
 			index.Version, _ = strconv.Atoi(fields[1])
 			existIndexes[fields[0]] = index
 
 		}
 	}
+	changes := make([]IndexChange, 0, len(indexes))
 	for _, index := range indexes {
-		index.Data.Options = index.Data.Options.SetName(fmt.Sprintf("%s%s%d", index.Name, IndexVersionDelimiter, index.Version)).SetVersion(1)
+		change := IndexChange{
+			Collection: collection.Name(),
+			Name:       index.Name,
+			Action:     IndexCreate,
+			ToVersion:  index.Version,
+			index:      index,
+		}
 		if existIndex, exist := existIndexes[index.Name]; exist {
-			log.Printf("索引存在,查看具体版本, 索引: %s,索引版本: %d", index.Name, existIndex.Version)
+			change.FromVersion = existIndex.Version
+			change.existName = existIndex.Name
+			change.Action = IndexKeep
 			if existIndex.Version != index.Version {
-				log.Printf("版本不同")
-				if _, err = collection.Indexes().DropOne(bg, existIndex.Name); err != nil {
-					return errors.Wrapf(err, "更新索引[%s.v%d],删除旧版本[%d]", index.Name, index.Version, existIndex.Version)
-				}
-				if _, err = collection.Indexes().CreateOne(bg, index.Data); err != nil {
-					return errors.Wrapf(err, "创建索引[%s.v%d]失败", index.Name, index.Version)
-				}
+				change.Action = IndexRecreate
 			}
-		} else {
-			log.Printf("索引不存在，创建, 索引: %s,索引版本: %d", index.Name, existIndex.Version)
-			if _, err = collection.Indexes().CreateOne(bg, index.Data); err != nil {
-				return errors.Wrapf(err, "创建索引[%s.v%d]失败", index.Name, index.Version)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+/*ApplyIndex 执行PlanIndex生成的变更计划
+参数:
+*	ctx       	context.Context
+*	collection	*mongo.Collection
+*	changes   	[]IndexChange
+返回值:
+*	error	error
+*/
+func ApplyIndex(ctx context.Context, collection *mongo.Collection, changes []IndexChange) error {
+	for _, change := range changes {
+		index := change.index
+		if index.Data.Options == nil {
+			index.Data.Options = options.Index()
+		}
+		index.Data.Options = index.Data.Options.SetName(fmt.Sprintf("%s%s%d", index.Name, IndexVersionDelimiter, index.Version)).SetVersion(1)
+		switch change.Action {
+		case IndexKeep:
+			continue
+		case IndexRecreate:
+			helper.Infof("索引版本不同, 索引: %s,现有版本: %d,期望版本: %d", index.Name, change.FromVersion, index.Version)
+			if _, err := collection.Indexes().DropOne(ctx, change.existName); err != nil {
+				return errors.Wrapf(err, "更新索引[%s.v%d],删除旧版本[%d]", index.Name, index.Version, change.FromVersion)
 			}
+		case IndexCreate:
+			helper.Infof("索引不存在，创建, 索引: %s,索引版本: %d", index.Name, index.Version)
+		}
+		if _, err := collection.Indexes().CreateOne(ctx, index.Data); err != nil {
+			return errors.Wrapf(err, "创建索引[%s.v%d]失败", index.Name, index.Version)
 		}
 	}
 	return nil
 }
+
+// EnsureIndex 检查并创建/升级索引
+func EnsureIndex(ctx context.Context, collection *mongo.Collection, indexes []Index) error {
+	changes, err := PlanIndex(ctx, collection, indexes)
+	if err != nil {
+		return err
+	}
+	return ApplyIndex(ctx, collection, changes)
+}
@@ -0,0 +1,13 @@
+package nosql
+
+import (
+	"github.com/go-kratos/kratos-layout/pkg/logger"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var helper = log.NewHelper(logger.Module(log.DefaultLogger, "nosql"))
+
+// SetLogger 设置nosql输出日志使用的logger，需要在使用nosql之前调用
+func SetLogger(l log.Logger) {
+	helper = log.NewHelper(logger.Module(l, "nosql"))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/go-kratos/kratos-layout/pkg/mongocodec"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -24,6 +25,6 @@ func NewMongo(c *Config) (client *mongo.Client, err error) {
 		Password:      c.Pass,
 		AuthSource:    c.DB,
 	}
-	client, err = mongo.Connect(context.Background(), options.Client().ApplyURI(fmt.Sprintf("mongodb://%s", c.Uri)).SetAuth(auth).SetConnectTimeout(time.Second*10))
+	client, err = mongo.Connect(context.Background(), options.Client().ApplyURI(fmt.Sprintf("mongodb://%s", c.Uri)).SetAuth(auth).SetConnectTimeout(time.Second*10).SetRegistry(mongocodec.Default()))
 	return
 }
@@ -10,3 +10,8 @@ type DBComponent interface {
 	Init() error
 	Collections() map[string]*mongo.Collection
 }
+
+// IndexComponent 需要维护索引的模块，key为集合名
+type IndexComponent interface {
+	Indexes() map[string][]Index
+}
@@ -21,6 +21,14 @@ func MakeSelect(include, exclude []string) (selection bson.M, err error) {
 	return
 }
 
+// Projection 只返回fields中的字段，fields为空时返回nil
+func Projection(fields []string) bson.M {
+	if len(fields) == 0 {
+		return nil
+	}
+	return makeSelect(fields, nil)
+}
+
 func validateSelect(include, exclude []string) (err error) {
 	if len(include) != 0 && len(exclude) != 0 {
 		err = errors.New("两个参数必须至少有一个为空")
@@ -39,16 +47,24 @@ func makeSelect(include, exclude []string) (fields bson.M) {
 	}
 	return
 }
+// convertSort -降序，+升序，没有-/+的字段不参与排序
 func convertSort(sorts []string) (result bson.D) {
 	result = make([]bson.E, 0, len(sorts))
-	value := 1
 	for _, sort := range sorts {
-		if sort[:1] == "-" {
-			sort = sort[1:]
+		if sort == "" {
+			continue
+		}
+		var value int
+		switch sort[:1] {
+		case "-":
 			value = -1
+		case "+":
+			value = 1
+		default:
+			continue
 		}
 		result = append(result, bson.E{
-			Key:   sort,
+			Key:   sort[1:],
 			Value: value,
 		})
 	}
@@ -185,7 +201,7 @@ func BuildQuery(data map[string]interface{}, specs QuerySpec, strict bool) (bson
 									result[key].(map[string]interface{})[k] = v
 								}
 							} else {
-								spew.Dump(result[key], result, key)
+								helper.Debugf("动态字段[%s]合并条件: %s", key, spew.Sdump(result[key], result))
 								switch t := result[key].(type) {
 								case primitive.M:
 									t["$eq"] = condition
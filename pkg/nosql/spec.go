@@ -3,7 +3,6 @@ package nosql
 import (
 	"context"
 	"fmt"
-	"log"
 	"reflect"
 
 	"github.com/pkg/errors"
@@ -60,14 +59,14 @@ func (s *Spec) Update(key string) error {
 	if s == nil {
 		return nil
 	}
-	log.Printf("初始化升级数据,库名: %s", key)
+	helper.Infof("初始化升级数据,库名: %s", key)
 	if hasHigher, err := s.hasHigherVersion(s.collection, s.version); err != nil {
 		return errors.Wrap(err, "判断有无更高版本数据")
 	} else {
 		if hasHigher {
 			return fmt.Errorf("数据库[%s]有高于版本的数据[%d]", key, s.version)
 		}
-		log.Printf("没有超过设计版本的数据,库名: %s", key)
+		helper.Infof("没有超过设计版本的数据,库名: %s", key)
 		if err = s.updateLowerVersion(); err != nil {
 			return errors.Wrap(err, "升级低版本数据")
 		}
@@ -1,16 +1,24 @@
 package nosql
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
 
 /* 定义了表格查询的一些方法，详情查看README.md#表格查询
 
  */
 //TableRequest 表格查询参数
 type TableRequest struct {
-	Query map[string]interface{} `json:"query"` //查询条件
-	Sort  []string               `json:"sort"`  //排序
-	Start int                    `json:"start"` //开始位置，从0开始
-	Limit int                    `json:"limit"` //本次最多数量
+	Query  map[string]interface{} `json:"query"`  //查询条件
+	Sort   []string               `json:"sort"`   //排序
+	Start  int                    `json:"start"`  //开始位置，从0开始
+	Limit  int                    `json:"limit"`  //本次最多数量
+	Fields []string               `json:"fields"` //返回的字段，为空时返回全部
 }
 
 //Validate 验证
@@ -43,3 +51,35 @@ func NewTableResult(count int, result interface{}) TableResult {
 		Result: result,
 	}
 }
+
+/*TableQuery 按照表格查询参数查询
+参数:
+*	ctx       	context.Context
+*	collection	*mongo.Collection
+*	req       	TableRequest
+*	specs     	QuerySpec			查询规则，严格模式，未定义的查询字段忽略
+*	data      	interface{}			结果的类型，非指针
+返回值:
+*	result	[]interface{}		结果，元素类型和data相同
+*	count 	int64				符合条件的总数量
+*	err   	error
+*/
+func TableQuery(ctx context.Context, collection *mongo.Collection, req TableRequest, specs QuerySpec, data interface{}) (result []interface{}, count int64, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+	query, err := BuildQuery(req.Query, specs, true)
+	if err != nil {
+		err = fmt.Errorf("构建查询条件: %w", err)
+		return
+	}
+	if query == nil {
+		query = bson.M{}
+	}
+	if count, err = collection.CountDocuments(ctx, query); err != nil {
+		err = fmt.Errorf("统计数量: %w", err)
+		return
+	}
+	result, _, err = BaseQuery(collection, ctx, query, req.Sort, int64(req.Start), int64(req.Limit), req.Fields, nil, data)
+	return
+}
@@ -0,0 +1,175 @@
+package nosql
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 需要重试的事务错误标签
+const (
+	TransientTransactionError      = "TransientTransactionError"      // 整个事务可以重试
+	UnknownTransactionCommitResult = "UnknownTransactionCommitResult" // 提交结果未知，可以重试提交
+)
+
+// DefaultTransactionRetries 事务和提交各自默认的最大重试次数
+const DefaultTransactionRetries = 5
+
+// TransactionOption WithTransaction的选项
+type TransactionOption func(o *transactionOptions)
+
+type transactionOptions struct {
+	retries int
+	opts    []*options.TransactionOptions
+}
+
+// TransactionRetries 事务和提交各自的最大重试次数，默认DefaultTransactionRetries，0表示不重试
+func TransactionRetries(retries int) TransactionOption {
+	return func(o *transactionOptions) {
+		o.retries = retries
+	}
+}
+
+// TransactionOptions mongo事务的读写关注等选项
+func TransactionOptions(opts ...*options.TransactionOptions) TransactionOption {
+	return func(o *transactionOptions) {
+		o.opts = append(o.opts, opts...)
+	}
+}
+
+type transactionKey struct{}
+
+// transaction 一次事务尝试，重试时重新创建
+type transaction struct {
+	hooks []func(ctx context.Context, committed bool) error
+}
+
+// InTransaction ctx中是否已经有WithTransaction开始的事务
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(transactionKey{}).(*transaction)
+	return ok
+}
+
+/*OnTransactionEnd 注册事务结束时的回调，用于提交或回滚事务中使用的其他资源，例如mysql事务
+每次尝试结束后按注册顺序调用，提交成功时committed为true，提交成功后回调返回的错误由WithTransaction返回，fn panic时也会以false调用
+参数:
+*	ctx 	context.Context		WithTransaction传给fn的ctx
+*	hook	func(ctx context.Context, committed bool) error
+返回值:
+*	bool	bool	ctx不在事务中时返回false，不注册
+*/
+func OnTransactionEnd(ctx context.Context, hook func(ctx context.Context, committed bool) error) bool {
+	tx, ok := ctx.Value(transactionKey{}).(*transaction)
+	if ok {
+		tx.hooks = append(tx.hooks, hook)
+	}
+	return ok
+}
+
+// end 调用回调，返回第一个错误
+func (tx *transaction) end(ctx context.Context, committed bool) error {
+	var first error
+	for _, hook := range tx.hooks {
+		if err := hook(ctx, committed); err != nil {
+			if first == nil {
+				first = err
+			}
+			helper.Errorf("事务结束回调失败, committed: %t: %+v", committed, err)
+		}
+	}
+	return first
+}
+
+// HasErrorLabel mongo返回的错误是否带有label，fn中用%w包装的错误也可以判断
+func HasErrorLabel(err error, label string) bool {
+	var se mongo.ServerError
+	return errors.As(err, &se) && se.HasErrorLabel(label)
+}
+
+/*WithTransaction 在mongo事务中执行fn，fn中使用参数ctx的操作都在这个事务中
+ctx中已经有事务时直接加入，由最外层提交或回滚，ctx结束后不再重试
+参数:
+*	ctx   	context.Context
+*	client	*mongo.Client
+*	fn    	func(ctx context.Context) error		返回错误时回滚，可能被重试多次
+*	opts  	...TransactionOption
+返回值:
+*	error	error
+*/
+func WithTransaction(ctx context.Context, client *mongo.Client, fn func(ctx context.Context) error, opts ...TransactionOption) error {
+	if InTransaction(ctx) {
+		return fn(ctx)
+	}
+	o := transactionOptions{retries: DefaultTransactionRetries}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	sess, err := client.StartSession()
+	if err != nil {
+		return errors.Wrap(err, "开始会话")
+	}
+	defer sess.EndSession(context.Background())
+
+	for retry := 0; ; retry++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = sess.StartTransaction(o.opts...); err != nil {
+			return errors.Wrap(err, "开始事务")
+		}
+		// 通过context.WithValue包装后mongo依然能从ctx中取到会话
+		tx := &transaction{}
+		sc := context.WithValue(mongo.NewSessionContext(ctx, sess), transactionKey{}, tx)
+		if err = attempt(sc, sess, tx, fn); err != nil {
+			if abortErr := sess.AbortTransaction(context.Background()); abortErr != nil {
+				helper.Errorf("回滚事务失败: %+v", abortErr)
+			}
+			_ = tx.end(ctx, false)
+			if HasErrorLabel(err, TransientTransactionError) && retry < o.retries {
+				helper.Warnf("事务第%d次重试: %v", retry+1, err)
+				continue
+			}
+			return err
+		}
+		if err = commit(sc, sess, o.retries); err != nil {
+			_ = tx.end(ctx, false)
+			if HasErrorLabel(err, TransientTransactionError) && retry < o.retries {
+				helper.Warnf("事务第%d次重试: %v", retry+1, err)
+				continue
+			}
+			return err
+		}
+		return tx.end(ctx, true)
+	}
+}
+
+// attempt 执行fn，fn panic时回滚事务并调用回调后继续panic
+func attempt(ctx context.Context, sess mongo.Session, tx *transaction, fn func(ctx context.Context) error) error {
+	defer func() {
+		if r := recover(); r != nil {
+			if err := sess.AbortTransaction(context.Background()); err != nil {
+				helper.Errorf("回滚事务失败: %+v", err)
+			}
+			_ = tx.end(ctx, false)
+			panic(r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// commit 提交结果未知时重试提交
+func commit(ctx context.Context, sess mongo.Session, retries int) error {
+	for retry := 0; ; retry++ {
+		err := sess.CommitTransaction(ctx)
+		if err == nil {
+			return nil
+		}
+		if HasErrorLabel(err, UnknownTransactionCommitResult) && retry < retries && ctx.Err() == nil {
+			helper.Warnf("提交事务第%d次重试: %v", retry+1, err)
+			continue
+		}
+		return errors.Wrap(err, "提交事务")
+	}
+}
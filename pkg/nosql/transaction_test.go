@@ -0,0 +1,102 @@
+package nosql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestHasErrorLabel(t *testing.T) {
+	err := mongo.CommandError{Code: 112, Labels: []string{TransientTransactionError}}
+	require.True(t, HasErrorLabel(err, TransientTransactionError))
+	require.False(t, HasErrorLabel(err, UnknownTransactionCommitResult))
+	// repo包装后的错误
+	require.True(t, HasErrorLabel(fmt.Errorf("更新greeter: %w", err), TransientTransactionError))
+	require.True(t, HasErrorLabel(errors.Wrap(err, "提交事务"), TransientTransactionError))
+	require.False(t, HasErrorLabel(errors.New("other"), TransientTransactionError))
+	require.False(t, HasErrorLabel(nil, TransientTransactionError))
+}
+
+func TestWithTransactionJoin(t *testing.T) {
+	// 已经在事务中时直接执行，不使用client
+	require.False(t, InTransaction(context.Background()))
+	require.False(t, OnTransactionEnd(context.Background(), nil))
+	tx := &transaction{}
+	ctx := context.WithValue(context.Background(), transactionKey{}, tx)
+	require.True(t, InTransaction(ctx))
+	var ended []bool
+	require.NoError(t, WithTransaction(ctx, nil, func(ctx context.Context) error {
+		require.True(t, InTransaction(ctx))
+		require.True(t, OnTransactionEnd(ctx, func(_ context.Context, committed bool) error {
+			ended = append(ended, committed)
+			return nil
+		}))
+		return nil
+	}))
+	// 加入的事务由外层结束
+	require.Empty(t, ended)
+	require.NoError(t, tx.end(ctx, true))
+	require.Equal(t, []bool{true}, ended)
+
+	tx.hooks = append(tx.hooks, func(context.Context, bool) error { return errors.New("mysql commit") })
+	require.EqualError(t, tx.end(ctx, true), "mysql commit")
+}
+
+func TestWithTransactionRetry(t *testing.T) {
+	// 开始会话和事务不需要连接服务器，fn没有操作时回滚也不需要
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	require.NoError(t, err)
+	defer func() { _ = client.Disconnect(context.Background()) }()
+	transient := mongo.CommandError{Code: 112, Labels: []string{TransientTransactionError}}
+
+	calls := 0
+	err = WithTransaction(context.Background(), client, func(ctx context.Context) error {
+		calls++
+		return transient
+	})
+	require.True(t, HasErrorLabel(err, TransientTransactionError))
+	require.Equal(t, DefaultTransactionRetries+1, calls)
+
+	calls = 0
+	err = WithTransaction(context.Background(), client, func(ctx context.Context) error {
+		calls++
+		return transient
+	}, TransactionRetries(1))
+	require.Error(t, err)
+	require.Equal(t, 2, calls)
+
+	// ctx结束后不再重试
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = WithTransaction(ctx, client, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return transient
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, calls)
+}
+
+func TestWithTransactionPanic(t *testing.T) {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	require.NoError(t, err)
+	defer func() { _ = client.Disconnect(context.Background()) }()
+
+	// panic时回调以未提交结束，例如回滚mysql事务
+	var ended []bool
+	require.PanicsWithValue(t, "boom", func() {
+		_ = WithTransaction(context.Background(), client, func(ctx context.Context) error {
+			OnTransactionEnd(ctx, func(_ context.Context, committed bool) error {
+				ended = append(ended, committed)
+				return nil
+			})
+			panic("boom")
+		})
+	})
+	require.Equal(t, []bool{false}, ended)
+}
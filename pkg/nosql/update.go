@@ -0,0 +1,108 @@
+package nosql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+/*UpdateDocument 根据字段生成$set/$unset更新文档
+参数:
+*	doc   	interface{}	结构体或结构体指针，字段按照bson名匹配
+*	fields	[]string	需要更新的字段，支持a.b，值为nil(指针、接口、map、切片)时$unset，否则$set，零值也会保存
+返回值:
+*	bson.M	bson.M
+*	error 	error
+*/
+func UpdateDocument(doc interface{}, fields []string) (bson.M, error) {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("bson编码: %w", err)
+	}
+	var values bson.M
+	if err = bson.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("bson解码: %w", err)
+	}
+	set, unset := bson.M{}, bson.M{}
+	for _, field := range fields {
+		f, isNil, err := fieldValue(reflect.ValueOf(doc), field)
+		if err != nil {
+			return nil, err
+		}
+		if isNil {
+			unset[field] = ""
+			continue
+		}
+		value, exist := lookup(values, field)
+		if !exist { // omitempty省略的零值
+			value = f.Interface()
+		}
+		set[field] = value
+	}
+	update := make(bson.M, 2)
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return update, nil
+}
+
+// fieldValue 按照bson名找到结构体字段，路径上或者字段本身为nil时isNil为true
+func fieldValue(v reflect.Value, field string) (f reflect.Value, isNil bool, err error) {
+	for _, name := range strings.Split(field, ".") {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return v, true, nil
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return v, false, fmt.Errorf("字段[%s]不是结构体", field)
+		}
+		var ok bool
+		if v, ok = fieldByBsonName(v, name); !ok {
+			return v, false, fmt.Errorf("没有字段[%s]", field)
+		}
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v, v.IsNil(), nil
+	}
+	return v, false, nil
+}
+
+func fieldByBsonName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := strings.Split(sf.Tag.Get("bson"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = strings.ToLower(sf.Name)
+		}
+		if tag == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func lookup(values bson.M, field string) (interface{}, bool) {
+	var value interface{} = values
+	for _, name := range strings.Split(field, ".") {
+		m, ok := value.(bson.M)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[name]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,37 @@
+package nosql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestUpdateDocument(t *testing.T) {
+	type meta struct {
+		Version int `bson:"version"`
+	}
+	doc := &struct {
+		Hello string `bson:"hello"`
+		Count int    `bson:"count,omitempty"`
+		Meta  *meta  `bson:"meta"`
+		Tags  []string
+		Owner *meta `bson:"owner"`
+	}{Hello: "kratos", Meta: &meta{Version: 2}}
+
+	// 零值保存，nil删除
+	update, err := UpdateDocument(doc, []string{"hello", "count", "meta.version", "tags", "owner.version"})
+	require.NoError(t, err)
+	require.Equal(t, bson.M{
+		"$set":   bson.M{"hello": "kratos", "count": 0, "meta.version": int32(2)},
+		"$unset": bson.M{"tags": "", "owner.version": ""},
+	}, update)
+
+	doc.Hello = ""
+	update, err = UpdateDocument(doc, []string{"hello"})
+	require.NoError(t, err)
+	require.Equal(t, bson.M{"$set": bson.M{"hello": ""}}, update)
+
+	_, err = UpdateDocument(doc, []string{"unknown"})
+	require.Error(t, err)
+}
@@ -0,0 +1,452 @@
+package nosql
+
+import (
+	"context"
+	"encoding/base64"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// change stream的操作类型
+const (
+	OperationInsert     = "insert"
+	OperationUpdate     = "update"
+	OperationReplace    = "replace"
+	OperationDelete     = "delete"
+	OperationDrop       = "drop"
+	OperationRename     = "rename"
+	OperationInvalidate = "invalidate" // 之后变更流会关闭
+)
+
+// resume token无法继续时mongodb返回的错误码
+const (
+	changeStreamFatalError  = 280
+	changeStreamHistoryLost = 286
+)
+
+// ChangeNamespace 变更所在的库和集合
+type ChangeNamespace struct {
+	DB   string `bson:"db"`
+	Coll string `bson:"coll"`
+}
+
+// UpdateDescription update操作修改和删除的字段
+type UpdateDescription struct {
+	UpdatedFields bson.Raw `bson:"updatedFields"`
+	RemovedFields []string `bson:"removedFields"`
+}
+
+// ChangeEvent change stream的事件
+type ChangeEvent struct {
+	ResumeToken       bson.Raw            `bson:"_id"`
+	OperationType     string              `bson:"operationType"`
+	Namespace         ChangeNamespace     `bson:"ns"`
+	DocumentKey       bson.Raw            `bson:"documentKey"`
+	UpdateDescription *UpdateDescription  `bson:"updateDescription"`
+	ClusterTime       primitive.Timestamp `bson:"clusterTime"`
+	// FullDocument 由WatchDocument指定的类型解码，没有完整文档时为nil
+	FullDocument interface{} `bson:"-"`
+}
+
+// DocumentID 变更文档的_id
+func (e *ChangeEvent) DocumentID() bson.RawValue {
+	if len(e.DocumentKey) == 0 {
+		return bson.RawValue{}
+	}
+	return e.DocumentKey.Lookup("_id")
+}
+
+/*DecodeChange 解码change stream的事件
+参数:
+*	raw     	bson.Raw				事件的原始文档
+*	document	func() interface{}		fullDocument的类型，为nil时解码为bson.M
+返回值:
+*	*ChangeEvent	*ChangeEvent
+*	error       	error
+*/
+func DecodeChange(raw bson.Raw, document func() interface{}) (*ChangeEvent, error) {
+	event := &ChangeEvent{}
+	if err := bson.Unmarshal(raw, event); err != nil {
+		return nil, errors.Wrap(err, "解码变更事件")
+	}
+	value, err := raw.LookupErr("fullDocument")
+	if err != nil || value.Type == bson.TypeNull {
+		return event, nil
+	}
+	var full interface{} = &bson.M{}
+	if document != nil {
+		full = document()
+	}
+	if err = value.Unmarshal(full); err != nil {
+		return nil, errors.Wrap(err, "解码变更文档")
+	}
+	event.FullDocument = full
+	return event, nil
+}
+
+// EncodeResumeToken resume token是bson文档，编码后对客户端不透明
+func EncodeResumeToken(token bson.Raw) string {
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+// DecodeResumeToken EncodeResumeToken的逆操作
+func DecodeResumeToken(s string) (bson.Raw, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	token := bson.Raw(b)
+	if err = token.Validate(); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// TokenStore 保存每个Watcher处理到的位置，重启后从这个位置继续
+type TokenStore interface {
+	// Load 没有保存过时返回nil
+	Load(ctx context.Context, name string) (bson.Raw, error)
+	// Save token为nil时删除
+	Save(ctx context.Context, name string, token bson.Raw) error
+}
+
+type collectionTokenStore struct {
+	collection *mongo.Collection
+}
+
+// NewCollectionTokenStore token保存在collection中，_id为Watcher的名字
+func NewCollectionTokenStore(collection *mongo.Collection) TokenStore {
+	return &collectionTokenStore{collection: collection}
+}
+
+func (s *collectionTokenStore) Load(ctx context.Context, name string) (bson.Raw, error) {
+	var doc struct {
+		Token bson.Raw `bson:"token"`
+	}
+	if err := s.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "查询resume token[%s]", name)
+	}
+	return doc.Token, nil
+}
+
+func (s *collectionTokenStore) Save(ctx context.Context, name string, token bson.Raw) error {
+	var err error
+	if token == nil {
+		_, err = s.collection.DeleteOne(ctx, bson.M{"_id": name})
+	} else {
+		_, err = s.collection.UpdateOne(ctx, bson.M{"_id": name},
+			bson.M{"$set": bson.M{"token": token, "updateTime": time.Now()}}, options.Update().SetUpsert(true))
+	}
+	return errors.Wrapf(err, "保存resume token[%s]", name)
+}
+
+type redisTokenStore struct {
+	rdb    redis.Cmdable
+	prefix string
+}
+
+// NewRedisTokenStore token保存在redis的prefix+name中
+func NewRedisTokenStore(rdb redis.Cmdable, prefix string) TokenStore {
+	return &redisTokenStore{rdb: rdb, prefix: prefix}
+}
+
+func (s *redisTokenStore) Load(ctx context.Context, name string) (bson.Raw, error) {
+	b, err := s.rdb.Get(ctx, s.prefix+name).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "查询resume token[%s]", name)
+	}
+	return b, nil
+}
+
+func (s *redisTokenStore) Save(ctx context.Context, name string, token bson.Raw) error {
+	var err error
+	if token == nil {
+		err = s.rdb.Del(ctx, s.prefix+name).Err()
+	} else {
+		err = s.rdb.Set(ctx, s.prefix+name, []byte(token), 0).Err()
+	}
+	return errors.Wrapf(err, "保存resume token[%s]", name)
+}
+
+// ChangeHandler 处理变更事件，返回错误时不保存token，等待WatchRetry后从上次保存的位置重新处理
+type ChangeHandler func(ctx context.Context, event *ChangeEvent) error
+
+// WatchOption Watcher的选项
+type WatchOption func(w *Watcher)
+
+// WatchCollection 订阅集合的变更
+func WatchCollection(collection *mongo.Collection) WatchOption {
+	return func(w *Watcher) {
+		w.namespace = collection.Database().Name() + "." + collection.Name()
+		w.watch = openChangeStream(collection.Watch)
+	}
+}
+
+// WatchDatabase 订阅数据库中所有集合的变更
+func WatchDatabase(db *mongo.Database) WatchOption {
+	return func(w *Watcher) {
+		w.namespace = db.Name()
+		w.watch = openChangeStream(db.Watch)
+	}
+}
+
+// changeStream mongo.ChangeStream中Watcher用到的方法，测试时替换
+type changeStream interface {
+	Next(ctx context.Context) bool
+	Raw() bson.Raw
+	Err() error
+	Close(ctx context.Context) error
+}
+
+type watchFunc func(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (changeStream, error)
+
+type mongoChangeStream struct {
+	*mongo.ChangeStream
+}
+
+func (cs mongoChangeStream) Raw() bson.Raw {
+	return cs.Current
+}
+
+func openChangeStream(watch func(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error)) watchFunc {
+	return func(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (changeStream, error) {
+		cs, err := watch(ctx, pipeline, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return mongoChangeStream{cs}, nil
+	}
+}
+
+// WatchPipeline 过滤事件的聚合管道，例如$match operationType
+func WatchPipeline(pipeline mongo.Pipeline) WatchOption {
+	return func(w *Watcher) {
+		w.pipeline = pipeline
+	}
+}
+
+// WatchTokenStore 保存处理位置，没有设置时每次启动从当前开始
+func WatchTokenStore(store TokenStore) WatchOption {
+	return func(w *Watcher) {
+		w.store = store
+	}
+}
+
+// WatchDocument fullDocument解码的类型，返回指针
+func WatchDocument(document func() interface{}) WatchOption {
+	return func(w *Watcher) {
+		w.document = document
+	}
+}
+
+// WatchFullDocument update事件是否查询完整文档，默认options.UpdateLookup
+func WatchFullDocument(fullDocument options.FullDocument) WatchOption {
+	return func(w *Watcher) {
+		w.fullDocument = fullDocument
+	}
+}
+
+// WatchRetry 变更流出错或者处理失败后重试的间隔，默认5s
+func WatchRetry(retry time.Duration) WatchOption {
+	return func(w *Watcher) {
+		w.retry = retry
+	}
+}
+
+// Watcher 订阅change stream并依次处理事件，实现kratos的transport.Server，Stop之后可以再次Start
+type Watcher struct {
+	name         string
+	namespace    string
+	watch        watchFunc
+	pipeline     mongo.Pipeline
+	store        TokenStore
+	document     func() interface{}
+	fullDocument options.FullDocument
+	retry        time.Duration
+	handler      ChangeHandler
+
+	token   bson.Raw // 最后处理成功的位置
+	mu      sync.Mutex
+	cancel  context.CancelFunc // 运行中时不为nil
+	done    chan struct{}
+	stopped bool // Start之前调用了Stop，下一次Start直接返回
+}
+
+/*NewWatcher 创建Watcher，必须指定WatchCollection或者WatchDatabase
+参数:
+*	name   	string			名字，保存token的key
+*	handler	ChangeHandler
+*	opts   	...WatchOption
+返回值:
+*	*Watcher	*Watcher
+*/
+func NewWatcher(name string, handler ChangeHandler, opts ...WatchOption) *Watcher {
+	w := &Watcher{
+		name:         name,
+		handler:      handler,
+		fullDocument: options.UpdateLookup,
+		retry:        5 * time.Second,
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	return w
+}
+
+// Endpoint 订阅的库或集合
+func (w *Watcher) Endpoint() (string, error) {
+	return "mongodb://" + w.namespace, nil
+}
+
+// Start 阻塞到Stop，出错后等待重试间隔再从上次的位置继续，invalidate之后立即从当前重新订阅
+// Stop先于Start调用时(例如kratos应用启动中退出)，Start直接返回
+func (w *Watcher) Start() error {
+	if w.watch == nil {
+		return errors.Errorf("watcher[%s]没有指定集合或数据库", w.name)
+	}
+	w.mu.Lock()
+	if w.stopped {
+		w.stopped = false
+		w.mu.Unlock()
+		return nil
+	}
+	if w.cancel != nil {
+		w.mu.Unlock()
+		return errors.Errorf("watcher[%s]已经在运行", w.name)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	w.cancel, w.done = cancel, done
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.cancel, w.done = nil, nil
+		w.mu.Unlock()
+		cancel()
+		close(done)
+	}()
+
+	if w.store != nil {
+		token, err := w.store.Load(ctx, w.name)
+		if err != nil {
+			return err
+		}
+		w.token = token
+	}
+	helper.Infof("watcher[%s]开始订阅%s", w.name, w.namespace)
+	for {
+		err := w.run(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err == nil {
+			helper.Infof("watcher[%s]变更流失效，从当前重新订阅", w.name)
+			continue
+		}
+		helper.Errorf("watcher[%s]订阅失败, %s后重试: %+v", w.name, w.retry, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(w.retry):
+		}
+	}
+}
+
+// Stop 停止订阅，等待正在处理的事件完成，没有运行时记录停止，之后的Start直接返回
+func (w *Watcher) Stop() error {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	if cancel == nil {
+		w.stopped = true
+	}
+	w.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	<-done
+	helper.Infof("watcher[%s]停止订阅", w.name)
+	return nil
+}
+
+func (w *Watcher) options() *options.ChangeStreamOptions {
+	opts := options.ChangeStream().SetFullDocument(w.fullDocument)
+	if w.token != nil {
+		opts.SetResumeAfter(w.token)
+	}
+	return opts
+}
+
+// run 处理到变更流出错，invalidate时返回nil
+func (w *Watcher) run(ctx context.Context) error {
+	pipeline := w.pipeline
+	if pipeline == nil {
+		pipeline = mongo.Pipeline{}
+	}
+	cs, err := w.watch(ctx, pipeline, w.options())
+	if err != nil && w.token != nil && isResumeTokenLost(err) {
+		// oplog已经不包含token的位置，只能从当前开始
+		helper.Errorf("watcher[%s]无法从上次的位置继续，从当前开始: %+v", w.name, err)
+		if err = w.save(ctx, nil); err != nil {
+			return err
+		}
+		cs, err = w.watch(ctx, pipeline, w.options())
+	}
+	if err != nil {
+		return errors.Wrap(err, "打开变更流")
+	}
+	defer func() {
+		if err := cs.Close(context.Background()); err != nil {
+			helper.Errorf("watcher[%s]关闭变更流失败: %+v", w.name, err)
+		}
+	}()
+	for cs.Next(ctx) {
+		event, err := DecodeChange(cs.Raw(), w.document)
+		if err != nil {
+			return err
+		}
+		if err = w.handler(ctx, event); err != nil {
+			return errors.Wrapf(err, "处理变更[%s]", event.OperationType)
+		}
+		if event.OperationType == OperationInvalidate {
+			// invalidate之后不能resumeAfter，重新从当前开始
+			return w.save(ctx, nil)
+		}
+		if err = w.save(ctx, event.ResumeToken); err != nil {
+			return err
+		}
+	}
+	if err = cs.Err(); err != nil {
+		return err
+	}
+	return errors.New("变更流已关闭")
+}
+
+func (w *Watcher) save(ctx context.Context, token bson.Raw) error {
+	w.token = token
+	if w.store == nil {
+		return nil
+	}
+	return w.store.Save(ctx, w.name, token)
+}
+
+func isResumeTokenLost(err error) bool {
+	var se mongo.ServerError
+	if errors.As(err, &se) {
+		return se.HasErrorCode(changeStreamHistoryLost) || se.HasErrorCode(changeStreamFatalError)
+	}
+	return false
+}
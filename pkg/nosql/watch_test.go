@@ -0,0 +1,230 @@
+package nosql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type watchDoc struct {
+	ID    primitive.ObjectID `bson:"_id"`
+	Hello string             `bson:"hello"`
+}
+
+func TestDecodeChange(t *testing.T) {
+	id := primitive.NewObjectID()
+	raw, err := bson.Marshal(bson.D{
+		{Key: "_id", Value: bson.D{{Key: "_data", Value: "826"}}},
+		{Key: "operationType", Value: OperationUpdate},
+		{Key: "ns", Value: bson.D{{Key: "db", Value: "test"}, {Key: "coll", Value: "greeter"}}},
+		{Key: "documentKey", Value: bson.D{{Key: "_id", Value: id}}},
+		{Key: "updateDescription", Value: bson.D{
+			{Key: "updatedFields", Value: bson.D{{Key: "hello", Value: "hi"}}},
+			{Key: "removedFields", Value: bson.A{"old"}},
+		}},
+		{Key: "fullDocument", Value: bson.D{{Key: "_id", Value: id}, {Key: "hello", Value: "hi"}}},
+	})
+	require.NoError(t, err)
+
+	event, err := DecodeChange(raw, func() interface{} { return &watchDoc{} })
+	require.NoError(t, err)
+	require.Equal(t, OperationUpdate, event.OperationType)
+	require.Equal(t, ChangeNamespace{DB: "test", Coll: "greeter"}, event.Namespace)
+	require.Equal(t, id, event.DocumentID().ObjectID())
+	require.Equal(t, []string{"old"}, event.UpdateDescription.RemovedFields)
+	require.Equal(t, &watchDoc{ID: id, Hello: "hi"}, event.FullDocument)
+
+	// 没有指定类型时解码为bson.M
+	event, err = DecodeChange(raw, nil)
+	require.NoError(t, err)
+	require.Equal(t, "hi", (*event.FullDocument.(*bson.M))["hello"])
+
+	// 删除事件没有fullDocument
+	raw, err = bson.Marshal(bson.D{
+		{Key: "_id", Value: bson.D{{Key: "_data", Value: "827"}}},
+		{Key: "operationType", Value: OperationDelete},
+		{Key: "documentKey", Value: bson.D{{Key: "_id", Value: id}}},
+		{Key: "fullDocument", Value: nil},
+	})
+	require.NoError(t, err)
+	event, err = DecodeChange(raw, func() interface{} { return &watchDoc{} })
+	require.NoError(t, err)
+	require.Nil(t, event.FullDocument)
+	require.Equal(t, "827", event.ResumeToken.Lookup("_data").StringValue())
+}
+
+func TestResumeToken(t *testing.T) {
+	token, err := bson.Marshal(bson.D{{Key: "_data", Value: "8260D5EC49"}})
+	require.NoError(t, err)
+	s := EncodeResumeToken(token)
+	decoded, err := DecodeResumeToken(s)
+	require.NoError(t, err)
+	require.Equal(t, bson.Raw(token), decoded)
+
+	_, err = DecodeResumeToken("not a token")
+	require.Error(t, err)
+	_, err = DecodeResumeToken(EncodeResumeToken([]byte{1, 2, 3}))
+	require.Error(t, err)
+}
+
+func TestWatcherWithoutTarget(t *testing.T) {
+	w := NewWatcher("empty", nil)
+	require.Error(t, w.Start())
+	require.NoError(t, w.Stop())
+}
+
+// fakeStream 依次返回events，之后返回err，err为nil时阻塞到ctx结束
+type fakeStream struct {
+	events []bson.Raw
+	err    error
+	cur    bson.Raw
+}
+
+func (s *fakeStream) Next(ctx context.Context) bool {
+	if len(s.events) == 0 {
+		if s.err == nil {
+			<-ctx.Done()
+		}
+		return false
+	}
+	s.cur, s.events = s.events[0], s.events[1:]
+	return true
+}
+
+func (s *fakeStream) Raw() bson.Raw                   { return s.cur }
+func (s *fakeStream) Err() error                      { return s.err }
+func (s *fakeStream) Close(ctx context.Context) error { return nil }
+
+type memTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]bson.Raw
+	saved  chan bson.Raw
+}
+
+func (s *memTokenStore) Load(_ context.Context, name string) (bson.Raw, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[name], nil
+}
+
+func (s *memTokenStore) Save(_ context.Context, name string, token bson.Raw) error {
+	s.mu.Lock()
+	s.tokens[name] = token
+	s.mu.Unlock()
+	s.saved <- token
+	return nil
+}
+
+func changeToken(t *testing.T, data string) bson.Raw {
+	token, err := bson.Marshal(bson.D{{Key: "_data", Value: data}})
+	require.NoError(t, err)
+	return token
+}
+
+func changeEvent(t *testing.T, data string) bson.Raw {
+	raw, err := bson.Marshal(bson.D{{Key: "_id", Value: changeToken(t, data)}, {Key: "operationType", Value: OperationInsert}})
+	require.NoError(t, err)
+	return raw
+}
+
+func TestWatcherRetry(t *testing.T) {
+	store := &memTokenStore{tokens: map[string]bson.Raw{"test": changeToken(t, "0")}, saved: make(chan bson.Raw, 10)}
+	streams := []*fakeStream{
+		{events: []bson.Raw{changeEvent(t, "1")}, err: errors.New("network")}, // 变更流出错
+		{events: []bson.Raw{changeEvent(t, "2")}},                             // 处理失败
+		{events: []bson.Raw{changeEvent(t, "2")}},
+	}
+	var (
+		mu      sync.Mutex
+		resumes []string
+		handled []string
+	)
+	failed := false
+	w := NewWatcher("test", func(ctx context.Context, event *ChangeEvent) error {
+		data := event.ResumeToken.Lookup("_data").StringValue()
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, data)
+		if data == "2" && !failed {
+			failed = true
+			return errors.New("handler")
+		}
+		return nil
+	}, WatchTokenStore(store), WatchRetry(time.Millisecond))
+	w.watch = func(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (changeStream, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		resumes = append(resumes, bson.Raw(opts[0].ResumeAfter.(bson.Raw)).Lookup("_data").StringValue())
+		if len(resumes) > len(streams) {
+			return &fakeStream{}, nil
+		}
+		return streams[len(resumes)-1], nil
+	}
+
+	// Start之前Stop，Start直接返回
+	require.NoError(t, w.Stop())
+	require.NoError(t, w.Start())
+	errc := make(chan error, 1)
+	go func() { errc <- w.Start() }()
+	require.Equal(t, changeToken(t, "1"), <-store.saved)
+	require.Equal(t, changeToken(t, "2"), <-store.saved)
+	require.NoError(t, w.Stop())
+	require.NoError(t, <-errc)
+
+	mu.Lock()
+	require.Equal(t, []string{"0", "1", "1"}, resumes)
+	require.Equal(t, []string{"1", "2", "2"}, handled)
+	mu.Unlock()
+	token, err := store.Load(context.Background(), "test")
+	require.NoError(t, err)
+	require.Equal(t, changeToken(t, "2"), token)
+
+	// 停止后可以重新开始，从保存的位置继续
+	go func() { errc <- w.Start() }()
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(resumes) == 4
+	}, time.Second, time.Millisecond)
+	require.NoError(t, w.Stop())
+	require.NoError(t, <-errc)
+	require.Equal(t, "2", resumes[3])
+}
+
+func TestWatcherInvalidate(t *testing.T) {
+	invalidate, err := bson.Marshal(bson.D{{Key: "_id", Value: changeToken(t, "2")}, {Key: "operationType", Value: OperationInvalidate}})
+	require.NoError(t, err)
+	store := &memTokenStore{tokens: map[string]bson.Raw{}, saved: make(chan bson.Raw, 10)}
+	reopened := make(chan bool, 2)
+	w := NewWatcher("test", func(ctx context.Context, event *ChangeEvent) error { return nil },
+		WatchTokenStore(store), WatchRetry(time.Hour))
+	opened := 0
+	w.watch = func(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (changeStream, error) {
+		opened++
+		if opened == 1 {
+			return &fakeStream{events: []bson.Raw{changeEvent(t, "1"), invalidate}}, nil
+		}
+		reopened <- opts[0].ResumeAfter == nil
+		return &fakeStream{}, nil
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- w.Start() }()
+	// invalidate之后不等待重试间隔，从当前重新订阅
+	select {
+	case fromNow := <-reopened:
+		require.True(t, fromNow)
+	case <-time.After(time.Second):
+		t.Fatal("没有重新订阅")
+	}
+	require.Equal(t, changeToken(t, "1"), <-store.saved)
+	require.Nil(t, <-store.saved)
+	require.NoError(t, w.Stop())
+	require.NoError(t, <-errc)
+}
@@ -0,0 +1,76 @@
+package objectid
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-kratos/kratos-layout/pkg/mongocodec"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Type ObjectID的反射类型
+var Type = reflect.TypeOf(ObjectID(""))
+
+// Codec ObjectID的bson编解码，通过mongocodec注册到mongo客户端的registry，不再依赖MarshalBSONValue，
+// 编码和MarshalBSONValue一致：空值返回ErrEmpty，其它为ObjectID；解码还接受历史数据中的字符串
+type Codec struct{}
+
+var _ bsoncodec.ValueCodec = Codec{}
+
+func init() {
+	mongocodec.Register("objectid", func(b *mongocodec.Builder) {
+		b.Type(ObjectID(""), Codec{})
+	})
+}
+
+func (Codec) EncodeValue(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || val.Type() != Type {
+		return bsoncodec.ValueEncoderError{Name: "ObjectIDEncodeValue", Types: []reflect.Type{Type}, Received: val}
+	}
+	o := val.Interface().(ObjectID)
+	if o.IsZero() {
+		return ErrEmpty
+	}
+	id, err := o.Primitive()
+	if err != nil {
+		return err
+	}
+	return vw.WriteObjectID(id)
+}
+
+func (Codec) DecodeValue(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != Type {
+		return bsoncodec.ValueDecoderError{Name: "ObjectIDDecodeValue", Types: []reflect.Type{Type}, Received: val}
+	}
+	var o ObjectID
+	switch vr.Type() {
+	case bsontype.ObjectID:
+		id, err := vr.ReadObjectID()
+		if err != nil {
+			return err
+		}
+		o = FromPrimitive(id)
+	case bsontype.String:
+		s, err := vr.ReadString()
+		if err != nil {
+			return err
+		}
+		if err = o.UnmarshalText([]byte(s)); err != nil {
+			return err
+		}
+	case bsontype.Null:
+		if err := vr.ReadNull(); err != nil {
+			return err
+		}
+	case bsontype.Undefined:
+		if err := vr.ReadUndefined(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("错误的类型[%s],不是ObjectID", vr.Type())
+	}
+	val.Set(reflect.ValueOf(o))
+	return nil
+}
@@ -1,29 +1,162 @@
 package objectid
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 )
 
+var (
+	// ErrInvalid 不是24位十六进制的ObjectID
+	ErrInvalid = errors.New("invalid ObjectID")
+	// ErrEmpty 空的ObjectID不能写入bson，可选的字段使用omitempty或者*ObjectID
+	ErrEmpty = errors.New("empty ObjectID")
+)
+
+// ObjectID 24位小写十六进制的mongo ObjectID，空字符串表示没有值
+// 外部输入使用Parse校验，直接类型转换的值在编码时才会发现错误
 type ObjectID string
 
+// New 生成一个新的ObjectID
+func New() ObjectID {
+	return FromPrimitive(primitive.NewObjectID())
+}
+
+/*Parse 校验并转为小写，外部传入的id都应该经过它
+参数:
+*	s	string	24位十六进制，大小写都可以
+返回值:
+*	ObjectID	ObjectID
+*	error   	error	格式错误时为ErrInvalid
+*/
+func Parse(s string) (ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	return FromPrimitive(id), nil
+}
+
+// MustParse 用于常量和测试，格式错误时panic
+func MustParse(s string) ObjectID {
+	id, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// FromPrimitive .
+func FromPrimitive(id primitive.ObjectID) ObjectID {
+	if id.IsZero() {
+		return ""
+	}
+	return ObjectID(id.Hex())
+}
+
+// Primitive 转为驱动的ObjectID，用于查询条件，空值和格式错误都返回ErrInvalid
+func (o ObjectID) Primitive() (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(string(o))
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("%w: %q", ErrInvalid, string(o))
+	}
+	return id, nil
+}
+
+// IsZero 没有值
+func (o ObjectID) IsZero() bool {
+	return o == ""
+}
+
+// IsValid 是24位十六进制
+func (o ObjectID) IsValid() bool {
+	_, err := o.Primitive()
+	return err == nil
+}
+
+// Hex .
+func (o ObjectID) Hex() string {
+	return string(o)
+}
+
+// Timestamp ObjectID中的创建时间，精确到秒，无效时为零值
+func (o ObjectID) Timestamp() time.Time {
+	id, err := o.Primitive()
+	if err != nil {
+		return time.Time{}
+	}
+	return id.Timestamp()
+}
+
+// MarshalText 空值为空字符串，JSON同样使用它
+func (o ObjectID) MarshalText() ([]byte, error) {
+	if o.IsZero() {
+		return []byte{}, nil
+	}
+	if !o.IsValid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalid, string(o))
+	}
+	return []byte(o), nil
+}
+
+// UnmarshalText 空字符串为空值，其它值必须有效
+func (o *ObjectID) UnmarshalText(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "" {
+		*o = ""
+		return nil
+	}
+	id, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*o = id
+	return nil
+}
+
+// UnmarshalJSON null为空值
+func (o *ObjectID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*o = ""
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("%w: %s", ErrInvalid, data)
+	}
+	return o.UnmarshalText(data[1 : len(data)-1])
+}
+
 func (o *ObjectID) UnmarshalBSONValue(bt bsontype.Type, v []byte) error {
 	value := bsoncore.Value{
 		Type: bt,
 		Data: v,
 	}
-	if value, ok := value.ObjectIDOK(); !ok {
-		return fmt.Errorf("错误的类型[%s],不是ObjectID", v)
-	} else {
-		*o = ObjectID(value.Hex())
+	switch bt {
+	case bsontype.ObjectID:
+		*o = FromPrimitive(value.ObjectID())
+		return nil
+	case bsontype.Null, bsontype.Undefined:
+		*o = ""
 		return nil
+	case bsontype.String:
+		// 历史数据中以字符串保存的id
+		return o.UnmarshalText([]byte(value.StringValue()))
+	default:
+		return fmt.Errorf("错误的类型[%s],不是ObjectID", bt)
 	}
 }
+
+// MarshalBSONValue 空值返回ErrEmpty，避免写入_id: null或者空的引用
 func (o ObjectID) MarshalBSONValue() (bsontype.Type, []byte, error) {
-	objID, err := primitive.ObjectIDFromHex(string(o))
+	if o.IsZero() {
+		return bsontype.Null, nil, ErrEmpty
+	}
+	objID, err := o.Primitive()
 	if err != nil {
 		return bsontype.Null, nil, err
 	}
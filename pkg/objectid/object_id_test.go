@@ -0,0 +1,151 @@
+package objectid
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/mongocodec"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const hexID = "60d5ec49f1a2c8b1f8e4b1a1"
+
+func TestParse(t *testing.T) {
+	id, err := Parse("60D5EC49F1A2C8B1F8E4B1A1")
+	require.NoError(t, err)
+	require.Equal(t, ObjectID(hexID), id)
+	require.True(t, id.IsValid())
+	require.Equal(t, time.Unix(0x60d5ec49, 0).UTC(), id.Timestamp().UTC())
+
+	for _, s := range []string{"", "60d5ec49", hexID + "00", "zzd5ec49f1a2c8b1f8e4b1a1"} {
+		_, err = Parse(s)
+		require.True(t, errors.Is(err, ErrInvalid), s)
+		require.False(t, ObjectID(s).IsValid())
+		require.True(t, ObjectID(s).Timestamp().IsZero())
+	}
+	require.Panics(t, func() { MustParse("bad") })
+	require.True(t, New().IsValid())
+	require.Equal(t, ObjectID(""), FromPrimitive(primitive.NilObjectID))
+}
+
+func TestJSON(t *testing.T) {
+	type doc struct {
+		ID ObjectID `json:"id"`
+	}
+	b, err := json.Marshal(doc{ID: hexID})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":"`+hexID+`"}`, string(b))
+	b, err = json.Marshal(doc{})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":""}`, string(b))
+	_, err = json.Marshal(doc{ID: "bad"})
+	require.Error(t, err)
+
+	var v doc
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"60D5EC49F1A2C8B1F8E4B1A1"}`), &v))
+	require.Equal(t, ObjectID(hexID), v.ID)
+	require.NoError(t, json.Unmarshal([]byte(`{"id":null}`), &v))
+	require.True(t, v.ID.IsZero())
+	require.Error(t, json.Unmarshal([]byte(`{"id":"bad"}`), &v))
+	require.Error(t, json.Unmarshal([]byte(`{"id":1}`), &v))
+}
+
+func TestSQL(t *testing.T) {
+	value, err := ObjectID(hexID).Value()
+	require.NoError(t, err)
+	require.Equal(t, hexID, value)
+	value, err = ObjectID("").Value()
+	require.NoError(t, err)
+	require.Nil(t, value)
+	_, err = ObjectID("bad").Value()
+	require.Error(t, err)
+
+	raw := MustParse(hexID)
+	p, err := raw.Primitive()
+	require.NoError(t, err)
+	for _, src := range []interface{}{hexID, []byte(hexID), p[:]} {
+		var o ObjectID
+		require.NoError(t, o.Scan(src))
+		require.Equal(t, raw, o)
+	}
+	o := raw
+	require.NoError(t, o.Scan(nil))
+	require.True(t, o.IsZero())
+	require.Error(t, o.Scan("bad"))
+	require.Error(t, o.Scan(1))
+}
+
+func TestOpaque(t *testing.T) {
+	id := MustParse(hexID)
+	s := id.Opaque("Greeter")
+	require.NotContains(t, s, hexID)
+	parsed, err := ParseOpaque("Greeter", s)
+	require.NoError(t, err)
+	require.Equal(t, id, parsed)
+
+	_, err = ParseOpaque("Article", s)
+	require.True(t, errors.Is(err, ErrInvalid))
+	_, err = ParseOpaque("Greeter", hexID)
+	require.True(t, errors.Is(err, ErrInvalid))
+	require.Empty(t, ObjectID("").Opaque("Greeter"))
+}
+
+func TestCodec(t *testing.T) {
+	registry := mongocodec.Default()
+
+	type doc struct {
+		ID    ObjectID  `bson:"_id,omitempty"`
+		Ref   ObjectID  `bson:"ref,omitempty"`
+		Ptr   *ObjectID `bson:"ptr"`
+		Other ObjectID  `bson:"other,omitempty"`
+	}
+	id := MustParse(hexID)
+	in := doc{ID: id, Ptr: &id}
+	b, err := bson.MarshalWithRegistry(registry, in)
+	require.NoError(t, err)
+	raw := bson.Raw(b)
+	require.Equal(t, bsontype.ObjectID, raw.Lookup("_id").Type)
+	require.Equal(t, bsontype.ObjectID, raw.Lookup("ptr").Type)
+	_, err = raw.LookupErr("ref")
+	require.Error(t, err)
+
+	var out doc
+	require.NoError(t, bson.UnmarshalWithRegistry(registry, b, &out))
+	require.Equal(t, in, out)
+
+	// 没有omitempty的空值不写入null
+	_, err = bson.MarshalWithRegistry(registry, struct {
+		ID ObjectID `bson:"_id"`
+	}{})
+	require.ErrorIs(t, err, ErrEmpty)
+	_, err = bson.Marshal(bson.M{"ref": ObjectID("")})
+	require.ErrorIs(t, err, ErrEmpty)
+	b, err = bson.MarshalWithRegistry(registry, doc{ID: id})
+	require.NoError(t, err)
+	require.Equal(t, bsontype.Null, bson.Raw(b).Lookup("ptr").Type)
+
+	// 历史数据中的字符串id
+	b, err = bson.Marshal(bson.M{"_id": hexID, "ref": nil, "other": "60D5EC49F1A2C8B1F8E4B1A1"})
+	require.NoError(t, err)
+	out = doc{}
+	require.NoError(t, bson.UnmarshalWithRegistry(registry, b, &out))
+	require.Equal(t, doc{ID: id, Other: id}, out)
+
+	b, err = bson.Marshal(bson.M{"ref": 1})
+	require.NoError(t, err)
+	require.Error(t, bson.UnmarshalWithRegistry(registry, b, &out))
+	_, err = bson.MarshalWithRegistry(registry, doc{Ref: "bad"})
+	require.Error(t, err)
+
+	// 没有注册时使用MarshalBSONValue，结果相同
+	b2, err := bson.Marshal(in)
+	require.NoError(t, err)
+	b, err = bson.MarshalWithRegistry(registry, in)
+	require.NoError(t, err)
+	require.Equal(t, b, b2)
+}
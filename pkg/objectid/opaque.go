@@ -0,0 +1,40 @@
+package objectid
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+/*Opaque GraphQL(Relay)风格的全局id，base64url("kind:hex")，对外隐藏id的类型和格式
+参数:
+*	kind	string	资源类型，例如Greeter，不能包含":"
+返回值:
+*	string	string	空值为空字符串
+*/
+func (o ObjectID) Opaque(kind string) string {
+	if o.IsZero() {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(kind + ":" + string(o)))
+}
+
+/*ParseOpaque 解析Opaque生成的id，并检查资源类型
+参数:
+*	kind	string	期望的资源类型
+*	s   	string
+返回值:
+*	ObjectID	ObjectID
+*	error   	error	格式错误或者类型不一致时为ErrInvalid
+*/
+func ParseOpaque(kind, s string) (ObjectID, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	i := strings.LastIndex(string(data), ":")
+	if i < 0 || string(data[:i]) != kind {
+		return "", fmt.Errorf("%w: %q不是%s的id", ErrInvalid, s, kind)
+	}
+	return Parse(string(data[i+1:]))
+}
@@ -0,0 +1,42 @@
+package objectid
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+)
+
+// Scan 读取CHAR(24)或BINARY(12)列，NULL为空值
+func (o *ObjectID) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*o = ""
+		return nil
+	case string:
+		return o.UnmarshalText([]byte(v))
+	case []byte:
+		if len(v) == 12 {
+			*o = ObjectID(hex.EncodeToString(v))
+			return nil
+		}
+		return o.UnmarshalText(v)
+	default:
+		return fmt.Errorf("不能把%T转为ObjectID", value)
+	}
+}
+
+// Value 保存为24位十六进制字符串，空值为NULL，mysql中镜像mongo的id时使用
+func (o ObjectID) Value() (driver.Value, error) {
+	if o.IsZero() {
+		return nil, nil
+	}
+	if !o.IsValid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalid, string(o))
+	}
+	return string(o), nil
+}
+
+// GormDataType gorm建表时的列类型
+func (ObjectID) GormDataType() string {
+	return "char(24)"
+}
@@ -0,0 +1,92 @@
+// Package otelmetric 把kratos的metrics.Counter和metrics.Observer适配到opentelemetry，
+// 应用通过global.SetMeterProvider安装exporter，没有安装时不记录
+package otelmetric
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+)
+
+// InstrumentationName 全局Meter的名字
+const InstrumentationName = "github.com/go-kratos/kratos-layout"
+
+// Meter 全局MeterProvider的Meter
+func Meter() metric.Meter {
+	return global.Meter(InstrumentationName)
+}
+
+// labeled With的值按名字的顺序转为标签
+type labeled struct {
+	names  []string
+	labels []attribute.KeyValue
+}
+
+func (l labeled) with(lvs []string) labeled {
+	labels := make([]attribute.KeyValue, len(l.labels), len(l.names))
+	copy(labels, l.labels)
+	for _, v := range lvs {
+		if len(labels) == len(l.names) { // 多余的值忽略
+			break
+		}
+		labels = append(labels, attribute.String(l.names[len(labels)], v))
+	}
+	return labeled{names: l.names, labels: labels}
+}
+
+type counter struct {
+	labeled
+	c metric.Float64Counter
+}
+
+/*NewCounter 创建计数器，名字冲突时panic
+参数:
+*	meter      	metric.Meter	一般为Meter()
+*	name       	string
+*	description	string
+*	labels     	...string		With的值依次对应的标签名
+返回值:
+*	metrics.Counter	metrics.Counter
+*/
+func NewCounter(meter metric.Meter, name, description string, labels ...string) metrics.Counter {
+	return &counter{
+		labeled: labeled{names: labels},
+		c:       metric.Must(meter).NewFloat64Counter(name, metric.WithDescription(description)),
+	}
+}
+
+func (c *counter) With(lvs ...string) metrics.Counter {
+	return &counter{labeled: c.with(lvs), c: c.c}
+}
+
+func (c *counter) Inc() {
+	c.Add(1)
+}
+
+func (c *counter) Add(delta float64) {
+	c.c.Add(context.Background(), delta, c.labels...)
+}
+
+type observer struct {
+	labeled
+	r metric.Float64ValueRecorder
+}
+
+// NewObserver 创建分布统计，例如耗时，参数和NewCounter相同
+func NewObserver(meter metric.Meter, name, description string, labels ...string) metrics.Observer {
+	return &observer{
+		labeled: labeled{names: labels},
+		r:       metric.Must(meter).NewFloat64ValueRecorder(name, metric.WithDescription(description)),
+	}
+}
+
+func (o *observer) With(lvs ...string) metrics.Observer {
+	return &observer{labeled: o.with(lvs), r: o.r}
+}
+
+func (o *observer) Observe(value float64) {
+	o.r.Record(context.Background(), value, o.labels...)
+}
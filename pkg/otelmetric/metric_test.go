@@ -0,0 +1,26 @@
+package otelmetric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestCounter(t *testing.T) {
+	impl, meter := oteltest.NewMeter()
+	c := NewCounter(meter, "runs", "执行次数", "job", "result")
+	c.With("a", "success").Inc()
+	c.With("a").With("failure", "ignored").Add(2)
+	NewObserver(meter, "duration", "耗时", "job").With("a").Observe(0.5)
+
+	measured := oteltest.AsStructs(impl.MeasurementBatches)
+	require.Len(t, measured, 3)
+	require.Equal(t, "runs", measured[0].Name)
+	require.Equal(t, map[attribute.Key]attribute.Value{"job": attribute.StringValue("a"), "result": attribute.StringValue("success")}, measured[0].Labels)
+	require.Equal(t, map[attribute.Key]attribute.Value{"job": attribute.StringValue("a"), "result": attribute.StringValue("failure")}, measured[1].Labels)
+	require.Equal(t, float64(2), measured[1].Number.AsFloat64())
+	require.Equal(t, "duration", measured[2].Name)
+	require.Equal(t, float64(0.5), measured[2].Number.AsFloat64())
+}
@@ -0,0 +1,28 @@
+// Package oteltrace opentelemetry trace的公共工具，任务队列、outbox等在消息中传递trace时使用
+package oteltrace
+
+import "go.opentelemetry.io/otel/propagation"
+
+var _ propagation.TextMapCarrier = MapCarrier(nil)
+
+// MapCarrier 用map[string]string传递trace，可以直接序列化到消息中
+type MapCarrier map[string]string
+
+// Get 实现propagation.TextMapCarrier
+func (c MapCarrier) Get(key string) string {
+	return c[key]
+}
+
+// Set 实现propagation.TextMapCarrier
+func (c MapCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+// Keys 实现propagation.TextMapCarrier
+func (c MapCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,303 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/logger"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+)
+
+// Broker 消息的投递目标，返回nil表示全部消息已经被broker确认
+// 返回错误时整批消息会重新投递，broker和消费者需要接受重复的消息
+type Broker interface {
+	Publish(ctx context.Context, msgs []*Message) error
+}
+
+// Handler 消费消息，返回错误时消息稍后重新投递
+type Handler func(ctx context.Context, msg *Message) error
+
+// RedisBroker 投递到redis stream，每个topic一个stream
+type RedisBroker struct {
+	rdb    redis.UniversalClient
+	prefix string
+	maxLen int64
+	retry  time.Duration
+	log    *log.Helper
+}
+
+/*NewRedisBroker 内置的broker
+参数:
+*	rdb   	redis.UniversalClient
+*	prefix	string	stream的前缀，例如"event:"
+*	maxLen	int64	stream的近似最大长度，0为不限制
+*	l     	log.Logger
+返回值:
+*	*RedisBroker	*RedisBroker
+*/
+func NewRedisBroker(rdb redis.UniversalClient, prefix string, maxLen int64, l log.Logger) *RedisBroker {
+	return &RedisBroker{
+		rdb:    rdb,
+		prefix: prefix,
+		maxLen: maxLen,
+		retry:  time.Second,
+		log:    log.NewHelper(logger.Module(l, "outbox")),
+	}
+}
+
+// Stream topic对应的stream
+func (b *RedisBroker) Stream(topic string) string {
+	return b.prefix + topic
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, msgs []*Message) error {
+	pipe := b.rdb.Pipeline()
+	for _, m := range msgs {
+		headers, _ := json.Marshal(m.Headers)
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream:       b.Stream(m.Topic),
+			MaxLenApprox: b.maxLen,
+			Values: []interface{}{
+				"id", m.ID,
+				"key", m.Key,
+				"payload", string(m.Payload),
+				"headers", string(headers),
+				"created", strconv.FormatInt(m.CreatedAt.UnixNano()/int64(time.Millisecond), 10),
+			},
+		})
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+/*Subscribe 在消费组中消费topic，阻塞到ctx取消
+handler成功后确认消息，失败的消息留在pending中，稍后由同一个consumer重新处理
+参数:
+*	ctx     	context.Context
+*	topic   	string
+*	group   	string	消费组，不存在时从stream开头开始消费
+*	consumer	string	同一个消费组中唯一，重启后使用相同的名字才能继续处理自己pending的消息
+*	h       	Handler
+返回值:
+*	error	error
+*/
+func (b *RedisBroker) Subscribe(ctx context.Context, topic, group, consumer string, h Handler) error {
+	stream := b.Stream(topic)
+	err := b.rdb.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("创建消费组[%s]: %w", stream, err)
+	}
+	// 启动时先处理上次没有确认的消息，之后每隔retry重新处理失败的消息
+	pending, retried := true, time.Time{}
+	for ctx.Err() == nil {
+		args := &redis.XReadGroupArgs{Group: group, Consumer: consumer, Streams: []string{stream, ">"}, Count: 10, Block: b.retry}
+		if pending && time.Since(retried) >= b.retry {
+			args.Streams[1], args.Block, retried = "0", -1, time.Now()
+		}
+		result, err := b.rdb.XReadGroup(ctx, args).Result()
+		if err != nil && err != redis.Nil {
+			if ctx.Err() == nil {
+				b.log.Errorf("读取消息[%s]失败: %+v", stream, err)
+				b.sleep(ctx)
+			}
+			continue
+		}
+		var msgs []redis.XMessage
+		if len(result) > 0 {
+			msgs = result[0].Messages
+		}
+		if args.Streams[1] == "0" {
+			pending = int64(len(msgs)) == args.Count
+		}
+		for _, xm := range msgs {
+			if !b.handle(ctx, stream, group, xm, h) {
+				pending = true
+			}
+		}
+	}
+	return nil
+}
+
+// handle 处理一条消息，返回是否已经确认
+func (b *RedisBroker) handle(ctx context.Context, stream, group string, xm redis.XMessage, h Handler) bool {
+	m, err := ParseRedis(xm.Values)
+	if err == nil {
+		m.Topic = strings.TrimPrefix(stream, b.prefix)
+		err = h(m.Context(ctx), m)
+	} else {
+		// 格式错误的消息重试也不会成功
+		b.log.Errorf("%+v", err)
+		err = nil
+	}
+	if err != nil {
+		b.log.Warnf("处理消息[%s:%s]失败，稍后重试: %v", stream, m.ID, err)
+		return false
+	}
+	if err = b.rdb.XAck(ctx, stream, group, xm.ID).Err(); err != nil {
+		b.log.Errorf("确认消息[%s:%s]失败: %+v", stream, xm.ID, err)
+		return false
+	}
+	return true
+}
+
+func (b *RedisBroker) sleep(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(b.retry):
+	}
+}
+
+// ParseRedis 解析RedisBroker写入stream的消息，Topic由调用方根据stream设置
+func ParseRedis(values map[string]interface{}) (*Message, error) {
+	get := func(key string) string {
+		s, _ := values[key].(string)
+		return s
+	}
+	m := &Message{
+		ID:      get("id"),
+		Key:     get("key"),
+		Payload: []byte(get("payload")),
+	}
+	if m.ID == "" {
+		return nil, fmt.Errorf("消息格式错误: %v", values)
+	}
+	if headers := get("headers"); headers != "" {
+		_ = json.Unmarshal([]byte(headers), &m.Headers)
+	}
+	created, _ := strconv.ParseInt(get("created"), 10, 64)
+	m.CreatedAt = time.Unix(0, created*int64(time.Millisecond))
+	return m, nil
+}
+
+// kafka的header中保存消息的id和创建时间
+const (
+	HeaderID      = "outbox-id"
+	HeaderCreated = "outbox-created"
+)
+
+// KafkaRecord 写入kafka的记录
+type KafkaRecord struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// KafkaWriter kafka客户端的适配，例如包装kafka-go的Writer.WriteMessages或sarama的SyncProducer
+// 返回nil之前需要得到kafka的确认，RequiredAcks建议为all
+type KafkaWriter interface {
+	WriteRecords(ctx context.Context, records ...KafkaRecord) error
+}
+
+// KafkaWriterFunc 函数形式的KafkaWriter
+type KafkaWriterFunc func(ctx context.Context, records ...KafkaRecord) error
+
+func (f KafkaWriterFunc) WriteRecords(ctx context.Context, records ...KafkaRecord) error {
+	return f(ctx, records...)
+}
+
+// KafkaBroker 投递到kafka，topic加上前缀作为kafka的topic，Key决定分区，同一个Key的消息保持顺序
+type KafkaBroker struct {
+	w      KafkaWriter
+	prefix string
+}
+
+// NewKafkaBroker .
+func NewKafkaBroker(w KafkaWriter, prefix string) *KafkaBroker {
+	return &KafkaBroker{w: w, prefix: prefix}
+}
+
+func (b *KafkaBroker) Publish(ctx context.Context, msgs []*Message) error {
+	records := make([]KafkaRecord, 0, len(msgs))
+	for _, m := range msgs {
+		headers := make(map[string]string, len(m.Headers)+2)
+		for k, v := range m.Headers {
+			headers[k] = v
+		}
+		headers[HeaderID] = m.ID
+		headers[HeaderCreated] = strconv.FormatInt(m.CreatedAt.UnixNano()/int64(time.Millisecond), 10)
+		records = append(records, KafkaRecord{
+			Topic:   b.prefix + m.Topic,
+			Key:     []byte(m.Key),
+			Value:   m.Payload,
+			Headers: headers,
+		})
+	}
+	return b.w.WriteRecords(ctx, records...)
+}
+
+// ParseKafka 解析KafkaBroker写入的记录
+func (b *KafkaBroker) ParseKafka(r KafkaRecord) (*Message, error) {
+	m := &Message{
+		ID:      r.Headers[HeaderID],
+		Topic:   strings.TrimPrefix(r.Topic, b.prefix),
+		Key:     string(r.Key),
+		Payload: r.Value,
+		Headers: make(map[string]string, len(r.Headers)),
+	}
+	if m.ID == "" {
+		return nil, fmt.Errorf("kafka记录没有%s header", HeaderID)
+	}
+	for k, v := range r.Headers {
+		if k != HeaderID && k != HeaderCreated {
+			m.Headers[k] = v
+		}
+	}
+	created, _ := strconv.ParseInt(r.Headers[HeaderCreated], 10, 64)
+	m.CreatedAt = time.Unix(0, created*int64(time.Millisecond))
+	return m, nil
+}
+
+// NATSPublisher nats的连接，*nats.Conn实现了这个接口
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// natsFlusher *nats.Conn的FlushWithContext，等待服务端收到已发布的消息
+type natsFlusher interface {
+	FlushWithContext(ctx context.Context) error
+}
+
+// NATSBroker 投递到nats，subject为前缀加topic，消息体为Message的json
+type NATSBroker struct {
+	nc     NATSPublisher
+	prefix string
+}
+
+// NewNATSBroker .
+func NewNATSBroker(nc NATSPublisher, prefix string) *NATSBroker {
+	return &NATSBroker{nc: nc, prefix: prefix}
+}
+
+func (b *NATSBroker) Publish(ctx context.Context, msgs []*Message) error {
+	for _, m := range msgs {
+		data, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		if err = b.nc.Publish(b.prefix+m.Topic, data); err != nil {
+			return err
+		}
+	}
+	if f, ok := b.nc.(natsFlusher); ok {
+		return f.FlushWithContext(ctx)
+	}
+	return nil
+}
+
+// ParseNATS 解析NATSBroker发布的消息体
+func ParseNATS(data []byte) (*Message, error) {
+	m := &Message{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("解析nats消息: %w", err)
+	}
+	if m.ID == "" {
+		return nil, fmt.Errorf("nats消息没有id")
+	}
+	return m, nil
+}
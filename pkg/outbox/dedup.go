@@ -0,0 +1,119 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrInProgress 同一条消息正在被其他consumer处理
+var ErrInProgress = errors.New("outbox: message is being processed")
+
+const (
+	stateProcessing = "processing"
+	stateDone       = "done"
+)
+
+// Deduper 消费端基于redis的去重，同一个consumer对同一条消息的处理成功后不再重复执行
+// 处理成功但标记失败时仍然可能重复，需要严格一次时在业务事务中使用MarkProcessed
+type Deduper struct {
+	rdb    redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+/*NewDeduper .
+参数:
+*	rdb   	redis.UniversalClient
+*	prefix	string		key的前缀，例如"dedup:"
+*	ttl   	time.Duration	处理成功的标记保留的时间，应该大于broker可能重复投递的时间窗口
+返回值:
+*	*Deduper	*Deduper
+*/
+func NewDeduper(rdb redis.UniversalClient, prefix string, ttl time.Duration) *Deduper {
+	return &Deduper{rdb: rdb, prefix: prefix, ttl: ttl}
+}
+
+func (d *Deduper) key(consumer string, m *Message) string {
+	return d.prefix + consumer + ":" + m.ID
+}
+
+/*Once 消息没有被consumer处理过时执行fn
+处理中的标记在ctx的deadline过期，没有deadline时为1分钟，处理中的进程退出后消息可以重新处理
+参数:
+*	ctx     	context.Context
+*	consumer	string
+*	m       	*Message
+*	fn      	func(ctx context.Context) error		返回错误时删除标记，消息可以重试
+返回值:
+*	bool 	bool	是否执行了fn
+*	error	error	其他consumer正在处理时返回ErrInProgress
+*/
+func (d *Deduper) Once(ctx context.Context, consumer string, m *Message, fn func(ctx context.Context) error) (bool, error) {
+	lease := time.Minute
+	if deadline, ok := ctx.Deadline(); ok {
+		lease = time.Until(deadline)
+	}
+	key := d.key(consumer, m)
+	ok, err := d.rdb.SetNX(ctx, key, stateProcessing, lease).Result()
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		state, err := d.rdb.Get(ctx, key).Result()
+		switch {
+		case err == redis.Nil:
+			// 标记刚刚过期，交给重新投递处理
+			return false, ErrInProgress
+		case err != nil:
+			return false, err
+		case state == stateDone:
+			return false, nil
+		default:
+			return false, ErrInProgress
+		}
+	}
+	if err = fn(ctx); err != nil {
+		d.rdb.Del(context.Background(), key)
+		return true, err
+	}
+	return true, d.rdb.Set(ctx, key, stateDone, d.ttl).Err()
+}
+
+// Handler 包装h，重复的消息直接确认，处理中的消息返回ErrInProgress稍后重试
+func (d *Deduper) Handler(consumer string, h Handler) Handler {
+	return func(ctx context.Context, m *Message) error {
+		_, err := d.Once(ctx, consumer, m, func(ctx context.Context) error {
+			return h(ctx, m)
+		})
+		return err
+	}
+}
+
+/*MarkProcessed 在mongo中记录consumer已经处理了消息
+和业务修改在同一个事务中调用时，重复的消息不会产生第二次修改，collection的_id由consumer和消息id组成
+参数:
+*	ctx       	context.Context		业务事务的ctx
+*	collection	*mongo.Collection	保存处理记录的集合，可以在processedAt上建TTL索引清理
+*	consumer  	string
+*	m         	*Message
+返回值:
+*	bool 	bool	第一次处理时返回true，false时应该跳过业务修改
+*	error	error
+*/
+func MarkProcessed(ctx context.Context, collection *mongo.Collection, consumer string, m *Message) (bool, error) {
+	// 事务中的写错误会中止事务，所以用upsert而不是insert判断重复，并发处理同一条消息时由事务冲突重试
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": consumer + ":" + m.ID},
+		bson.M{"$setOnInsert": bson.M{"processedAt": time.Now()}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount == 1, nil
+}
@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/oteltrace"
+	"github.com/go-kratos/kratos/v2/encoding"
+	_ "github.com/go-kratos/kratos/v2/encoding/json" // payload的默认codec
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// Message outbox中的消息，ID在写入outbox时生成，投递到broker后不变，消费者用它去重
+type Message struct {
+	ID        string            `bson:"_id" json:"id"`
+	Topic     string            `bson:"topic" json:"topic"`
+	Key       string            `bson:"key,omitempty" json:"key,omitempty"` // 分区和排序的key，例如聚合的id
+	Payload   []byte            `bson:"payload" json:"payload"`
+	Headers   map[string]string `bson:"headers,omitempty" json:"headers,omitempty"`
+	CreatedAt time.Time         `bson:"createdAt" json:"createdAt"`
+}
+
+/*NewMessage 创建消息，ctx中的trace写入headers，消费者通过Context继续同一个trace
+参数:
+*	ctx    	context.Context
+*	topic  	string
+*	key    	string
+*	payload	interface{}	使用kratos的json codec编码，[]byte原样保存
+返回值:
+*	*Message	*Message
+*	error   	error
+*/
+func NewMessage(ctx context.Context, topic, key string, payload interface{}) (*Message, error) {
+	m := &Message{
+		// ObjectID按时间递增，同一毫秒内的消息也能按写入顺序排序
+		ID:        primitive.NewObjectID().Hex(),
+		Topic:     topic,
+		Key:       key,
+		Headers:   make(map[string]string),
+		CreatedAt: time.Now().Truncate(time.Millisecond),
+	}
+	if b, ok := payload.([]byte); ok {
+		m.Payload = b
+	} else {
+		b, err := encoding.GetCodec("json").Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("编码消息[%s]: %w", topic, err)
+		}
+		m.Payload = b
+	}
+	otel.GetTextMapPropagator().Inject(ctx, oteltrace.MapCarrier(m.Headers))
+	return m, nil
+}
+
+// Decode 使用kratos的json codec解码payload，支持proto message
+func (m *Message) Decode(v interface{}) error {
+	return encoding.GetCodec("json").Unmarshal(m.Payload, v)
+}
+
+// Context 从headers中取出发布时的trace
+func (m *Message) Context(ctx context.Context) context.Context {
+	if m.Headers == nil {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, oteltrace.MapCarrier(m.Headers))
+}
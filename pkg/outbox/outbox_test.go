@@ -0,0 +1,222 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore 测试用的outbox
+type memoryStore struct {
+	mu   sync.Mutex
+	msgs []*Message
+	sent map[string]bool
+}
+
+func (s *memoryStore) Save(_ context.Context, msgs ...*Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs = append(s.msgs, msgs...)
+	return nil
+}
+
+func (s *memoryStore) Pending(_ context.Context, limit int) ([]*Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pending []*Message
+	for _, m := range s.msgs {
+		if !s.sent[m.ID] && len(pending) < limit {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+func (s *memoryStore) MarkSent(_ context.Context, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.sent[id] = true
+	}
+	return nil
+}
+
+// flakyBroker 前failures次投递失败
+type flakyBroker struct {
+	Broker
+	failures int
+}
+
+func (b *flakyBroker) Publish(ctx context.Context, msgs []*Message) error {
+	if b.failures > 0 {
+		b.failures--
+		return errors.New("broker unavailable")
+	}
+	return b.Broker.Publish(ctx, msgs)
+}
+
+type event struct {
+	N int `json:"n"`
+}
+
+func save(t *testing.T, store Store, topic string, ns ...int) []*Message {
+	var msgs []*Message
+	for _, n := range ns {
+		m, err := NewMessage(context.Background(), topic, "key", event{N: n})
+		require.NoError(t, err)
+		msgs = append(msgs, m)
+	}
+	require.NoError(t, store.Save(context.Background(), msgs...))
+	return msgs
+}
+
+func TestRelay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	broker := NewRedisBroker(rdb, "event:", 0, log.DefaultLogger)
+	broker.retry = 20 * time.Millisecond
+	store := &memoryStore{sent: make(map[string]bool)}
+	relay := NewRelay(store, &flakyBroker{Broker: broker, failures: 1}, WithBatchSize(2), WithPollInterval(20*time.Millisecond))
+	save(t, store, "greeter.created", 1, 2, 3)
+	go func() { _ = relay.Run(ctx) }()
+
+	// 第一次处理失败的消息重新投递，重复投递的消息只处理一次
+	deduper := NewDeduper(rdb, "dedup:", time.Hour)
+	var mu sync.Mutex
+	var got []int
+	failed := false
+	handler := deduper.Handler("test", func(ctx context.Context, m *Message) error {
+		var e event
+		if err := m.Decode(&e); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if e.N == 2 && !failed {
+			failed = true
+			return errors.New("failed")
+		}
+		got = append(got, e.N)
+		return nil
+	})
+	go func() { _ = broker.Subscribe(ctx, "greeter.created", "test", "c1", handler) }()
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got) == 3
+	}, 3*time.Second, 20*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return rdb.XPending(ctx, broker.Stream("greeter.created"), "test").Val().Count == 0
+	}, time.Second, 20*time.Millisecond)
+
+	// broker重复投递
+	msgs, err := store.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, msgs)
+	require.NoError(t, broker.Publish(ctx, store.msgs[:1]))
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	sort.Ints(got)
+	require.Equal(t, []int{1, 2, 3}, got)
+	mu.Unlock()
+}
+
+func TestDeduper(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	deduper := NewDeduper(rdb, "dedup:", time.Hour)
+	m, err := NewMessage(ctx, "topic", "", []byte("{}"))
+	require.NoError(t, err)
+
+	// 处理中时其他consumer实例返回ErrInProgress
+	ran, err := deduper.Once(ctx, "c", m, func(ctx context.Context) error {
+		_, err := deduper.Once(ctx, "c", m, func(ctx context.Context) error { return nil })
+		require.ErrorIs(t, err, ErrInProgress)
+		return errors.New("failed")
+	})
+	require.True(t, ran)
+	require.Error(t, err)
+
+	ran, err = deduper.Once(ctx, "c", m, func(ctx context.Context) error { return nil })
+	require.True(t, ran)
+	require.NoError(t, err)
+	ran, err = deduper.Once(ctx, "c", m, func(ctx context.Context) error { return nil })
+	require.False(t, ran)
+	require.NoError(t, err)
+	// 不同consumer分别去重
+	ran, err = deduper.Once(ctx, "other", m, func(ctx context.Context) error { return nil })
+	require.True(t, ran)
+	require.NoError(t, err)
+}
+
+// natsConn 本地代替*nats.Conn
+type natsConn struct {
+	subjects []string
+	data     [][]byte
+	flushed  int
+}
+
+func (c *natsConn) Publish(subject string, data []byte) error {
+	c.subjects = append(c.subjects, subject)
+	c.data = append(c.data, data)
+	return nil
+}
+
+func (c *natsConn) FlushWithContext(ctx context.Context) error {
+	c.flushed++
+	return nil
+}
+
+func TestAdapters(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{sent: make(map[string]bool)}
+	msgs := save(t, store, "greeter.updated", 1, 2)
+	msgs[0].Headers["traceparent"] = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+	var records []KafkaRecord
+	kafka := NewKafkaBroker(KafkaWriterFunc(func(ctx context.Context, rs ...KafkaRecord) error {
+		records = append(records, rs...)
+		return nil
+	}), "app.")
+	n, err := NewRelay(store, kafka).Relay(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Len(t, records, 2)
+	require.Equal(t, "app.greeter.updated", records[0].Topic)
+	require.Equal(t, []byte("key"), records[0].Key)
+	parsed, err := kafka.ParseKafka(records[0])
+	require.NoError(t, err)
+	require.Equal(t, msgs[0].ID, parsed.ID)
+	require.Equal(t, "greeter.updated", parsed.Topic)
+	require.Equal(t, msgs[0].Headers, parsed.Headers)
+	require.True(t, msgs[0].CreatedAt.Equal(parsed.CreatedAt))
+
+	nc := &natsConn{}
+	store.sent = make(map[string]bool)
+	n, err = NewRelay(store, NewNATSBroker(nc, "app.")).Relay(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []string{"app.greeter.updated", "app.greeter.updated"}, nc.subjects)
+	require.Equal(t, 1, nc.flushed)
+	parsed, err = ParseNATS(nc.data[1])
+	require.NoError(t, err)
+	var e event
+	require.NoError(t, parsed.Decode(&e))
+	require.Equal(t, 2, e.N)
+	require.Equal(t, msgs[1].ID, parsed.ID)
+}
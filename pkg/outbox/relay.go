@@ -0,0 +1,102 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/logger"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Option Relay的选项
+type Option func(r *Relay)
+
+// WithBatchSize 每次投递的最大消息数，默认100
+func WithBatchSize(n int) Option {
+	return func(r *Relay) {
+		if n > 0 {
+			r.batch = n
+		}
+	}
+}
+
+// WithPollInterval 没有消息或者投递失败时的等待时间，默认1秒
+func WithPollInterval(d time.Duration) Option {
+	return func(r *Relay) {
+		r.poll = d
+	}
+}
+
+// WithLogger 日志
+func WithLogger(l log.Logger) Option {
+	return func(r *Relay) {
+		r.log = log.NewHelper(logger.Module(l, "outbox"))
+	}
+}
+
+// Relay 把outbox中的消息按写入顺序投递到broker，投递成功后标记已投递
+// 投递后标记失败时消息会再次投递，同一个store只应该有一个Relay运行，例如在lock.Election中运行
+type Relay struct {
+	store  Store
+	broker Broker
+	batch  int
+	poll   time.Duration
+	log    *log.Helper
+}
+
+// NewRelay .
+func NewRelay(store Store, broker Broker, opts ...Option) *Relay {
+	r := &Relay{
+		store:  store,
+		broker: broker,
+		batch:  100,
+		poll:   time.Second,
+		log:    log.NewHelper(logger.Module(log.DefaultLogger, "outbox")),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Run 持续投递到ctx取消，签名和lock.Election的fn相同
+func (r *Relay) Run(ctx context.Context) error {
+	for ctx.Err() == nil {
+		n, err := r.Relay(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.log.Errorf("%+v", err)
+		}
+		// 一批没有投递完时立即继续
+		if err == nil && n == r.batch {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(r.poll):
+		}
+	}
+	return nil
+}
+
+// Relay 投递一批消息，返回投递的数量
+func (r *Relay) Relay(ctx context.Context) (int, error) {
+	msgs, err := r.store.Pending(ctx, r.batch)
+	if err != nil {
+		return 0, fmt.Errorf("读取outbox: %w", err)
+	}
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+	if err = r.broker.Publish(ctx, msgs); err != nil {
+		return 0, fmt.Errorf("投递%d条消息: %w", len(msgs), err)
+	}
+	ids := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		ids = append(ids, m.ID)
+	}
+	if err = r.store.MarkSent(ctx, ids...); err != nil {
+		return 0, fmt.Errorf("标记%d条消息已投递，消息会重复投递: %w", len(ids), err)
+	}
+	return len(msgs), nil
+}
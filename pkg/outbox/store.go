@@ -0,0 +1,166 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/nosql"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+)
+
+// Store outbox的存储，Save使用调用方的ctx，和业务修改在同一个事务中写入
+type Store interface {
+	// Save 写入消息，ctx在事务中时随事务提交
+	Save(ctx context.Context, msgs ...*Message) error
+	// Pending 按写入顺序返回没有投递的消息
+	Pending(ctx context.Context, limit int) ([]*Message, error)
+	// MarkSent 标记消息已投递
+	MarkSent(ctx context.Context, ids ...string) error
+}
+
+// mongoMessage mongo中保存的消息，sentAt为null时没有投递
+type mongoMessage struct {
+	Message `bson:",inline"`
+	SentAt  *time.Time `bson:"sentAt"`
+}
+
+// MongoStore mongo的outbox，ctx中有nosql.WithTransaction的事务时在事务中写入
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoStore .
+func NewMongoStore(collection *mongo.Collection) *MongoStore {
+	return &MongoStore{collection: collection}
+}
+
+func (s *MongoStore) Save(ctx context.Context, msgs ...*Message) error {
+	docs := make([]interface{}, 0, len(msgs))
+	for _, m := range msgs {
+		docs = append(docs, &mongoMessage{Message: *m})
+	}
+	_, err := s.collection.InsertMany(ctx, docs)
+	return err
+}
+
+func (s *MongoStore) Pending(ctx context.Context, limit int) ([]*Message, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := s.collection.Find(ctx, bson.M{"sentAt": nil}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var docs []*mongoMessage
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	msgs := make([]*Message, 0, len(docs))
+	for _, doc := range docs {
+		msgs = append(msgs, &doc.Message)
+	}
+	return msgs, nil
+}
+
+func (s *MongoStore) MarkSent(ctx context.Context, ids ...string) error {
+	_, err := s.collection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{"$set": bson.M{"sentAt": time.Now()}})
+	return err
+}
+
+/*Indexes outbox集合需要的索引，交给Migrator维护
+参数:
+*	retention	time.Duration	已投递的消息保留的时间，由TTL索引删除
+返回值:
+*	[]nosql.Index	[]nosql.Index
+*/
+func (s *MongoStore) Indexes(retention time.Duration) []nosql.Index {
+	return []nosql.Index{
+		{
+			Name: "pending",
+			Data: mongo.IndexModel{
+				Keys:    bson.D{{Key: "sentAt", Value: 1}, {Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}},
+				Options: options.Index(),
+			},
+			Version: 1,
+		},
+		{
+			Name: "sent_ttl",
+			Data: mongo.IndexModel{
+				Keys:    bson.D{{Key: "sentAt", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(int32(retention / time.Second)),
+			},
+			Version: 1,
+		},
+	}
+}
+
+// OutboxMessage mysql的outbox表，使用GormStore时需要加入AutoMigrate
+type OutboxMessage struct {
+	ID        string `gorm:"primaryKey;size:24"`
+	Topic     string `gorm:"size:128"`
+	MsgKey    string `gorm:"size:128"`
+	Payload   []byte
+	Headers   string     `gorm:"type:text"`
+	CreatedAt time.Time  `gorm:"index:idx_outbox_pending,priority:2"`
+	SentAt    *time.Time `gorm:"index:idx_outbox_pending,priority:1"`
+}
+
+// GormStore mysql的outbox，db返回ctx中的事务时在事务中写入
+type GormStore struct {
+	db func(ctx context.Context) *gorm.DB
+}
+
+// NewGormStore db一般为Data.DB
+func NewGormStore(db func(ctx context.Context) *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) Save(ctx context.Context, msgs ...*Message) error {
+	rows := make([]OutboxMessage, 0, len(msgs))
+	for _, m := range msgs {
+		headers, err := json.Marshal(m.Headers)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, OutboxMessage{
+			ID:        m.ID,
+			Topic:     m.Topic,
+			MsgKey:    m.Key,
+			Payload:   m.Payload,
+			Headers:   string(headers),
+			CreatedAt: m.CreatedAt,
+		})
+	}
+	return s.db(ctx).Create(&rows).Error
+}
+
+func (s *GormStore) Pending(ctx context.Context, limit int) ([]*Message, error) {
+	var rows []OutboxMessage
+	if err := s.db(ctx).Where("sent_at IS NULL").Order("created_at, id").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	msgs := make([]*Message, 0, len(rows))
+	for _, row := range rows {
+		m := &Message{
+			ID:        row.ID,
+			Topic:     row.Topic,
+			Key:       row.MsgKey,
+			Payload:   row.Payload,
+			CreatedAt: row.CreatedAt,
+		}
+		_ = json.Unmarshal([]byte(row.Headers), &m.Headers)
+		msgs = append(msgs, m)
+	}
+	return msgs, nil
+}
+
+func (s *GormStore) MarkSent(ctx context.Context, ids ...string) error {
+	return s.db(ctx).Model(&OutboxMessage{}).Where("id IN ?", ids).Update("sent_at", time.Now()).Error
+}
+
+// Purge 删除before之前投递的消息，可以由定时任务调用
+func (s *GormStore) Purge(ctx context.Context, before time.Time) error {
+	return s.db(ctx).Where("sent_at < ?", before).Delete(&OutboxMessage{}).Error
+}
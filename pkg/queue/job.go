@@ -0,0 +1,119 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/oteltrace"
+	"github.com/go-kratos/kratos/v2/encoding"
+	_ "github.com/go-kratos/kratos/v2/encoding/json" // payload的默认codec
+)
+
+// Priority 任务优先级，worker先处理高优先级的stream
+type Priority int
+
+const (
+	PriorityHigh Priority = iota
+	PriorityDefault
+	PriorityLow
+)
+
+var priorities = []Priority{PriorityHigh, PriorityDefault, PriorityLow}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityLow:
+		return "low"
+	default:
+		return "default"
+	}
+}
+
+// Job 交给Handler处理的任务
+type Job struct {
+	ID         string
+	Type       string
+	Payload    []byte
+	Attempt    int // 第几次执行，从1开始
+	Priority   Priority
+	EnqueuedAt time.Time
+}
+
+// Decode 使用kratos的json codec解码payload，支持proto message
+func (j *Job) Decode(v interface{}) error {
+	return encoding.GetCodec("json").Unmarshal(j.Payload, v)
+}
+
+// message stream中保存的任务
+type message struct {
+	id       string
+	jobType  string
+	payload  string
+	attempt  int
+	priority Priority
+	enqueued int64 // 毫秒
+	trace    oteltrace.MapCarrier
+	err      string // 进入死信时的错误
+}
+
+func (m *message) values() []interface{} {
+	trace, _ := json.Marshal(m.trace)
+	values := []interface{}{
+		"id", m.id,
+		"type", m.jobType,
+		"payload", m.payload,
+		"attempt", strconv.Itoa(m.attempt),
+		"priority", strconv.Itoa(int(m.priority)),
+		"enqueued", strconv.FormatInt(m.enqueued, 10),
+		"trace", string(trace),
+	}
+	if m.err != "" {
+		values = append(values, "error", m.err)
+	}
+	return values
+}
+
+// delayed 延时队列的成员，到期后由脚本原样XADD到stream
+func (m *message) delayed(stream string) string {
+	b, _ := json.Marshal(m.values())
+	return stream + "\n" + string(b)
+}
+
+func parseMessage(values map[string]interface{}) (*message, error) {
+	get := func(key string) string {
+		s, _ := values[key].(string)
+		return s
+	}
+	m := &message{
+		id:      get("id"),
+		jobType: get("type"),
+		payload: get("payload"),
+		err:     get("error"),
+	}
+	if m.id == "" || m.jobType == "" {
+		return nil, fmt.Errorf("任务格式错误: %v", values)
+	}
+	m.attempt, _ = strconv.Atoi(get("attempt"))
+	priority, _ := strconv.Atoi(get("priority"))
+	m.priority = Priority(priority)
+	m.enqueued, _ = strconv.ParseInt(get("enqueued"), 10, 64)
+	if trace := get("trace"); trace != "" {
+		_ = json.Unmarshal([]byte(trace), &m.trace)
+	}
+	return m, nil
+}
+
+func (m *message) job() *Job {
+	return &Job{
+		ID:         m.id,
+		Type:       m.jobType,
+		Payload:    []byte(m.payload),
+		Attempt:    m.attempt + 1,
+		Priority:   m.priority,
+		EnqueuedAt: time.Unix(0, m.enqueued*int64(time.Millisecond)),
+	}
+}
@@ -0,0 +1,335 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/logger"
+	"github.com/go-kratos/kratos-layout/pkg/oteltrace"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrUnknownJob 任务类型没有注册
+var ErrUnknownJob = errors.New("queue: unknown job type")
+
+// Handler 处理任务，返回错误时按退避时间重试，超过最大重试次数后进入死信
+type Handler func(ctx context.Context, job *Job) error
+
+// Option Queue的选项
+type Option func(q *Queue)
+
+// WithPrefix redis key的前缀，默认"queue:"，redis cluster时需要包含hash tag，例如"{queue}:"
+func WithPrefix(prefix string) Option {
+	return func(q *Queue) {
+		q.prefix = prefix
+	}
+}
+
+// WithGroup 消费组，默认"workers"
+func WithGroup(group string) Option {
+	return func(q *Queue) {
+		q.group = group
+	}
+}
+
+// WithConcurrency 同时处理的任务数，默认10
+func WithConcurrency(n int) Option {
+	return func(q *Queue) {
+		if n > 0 {
+			q.concurrency = n
+		}
+	}
+}
+
+// WithVisibilityTimeout 任务被取出后超过这个时间没有完成时由其他worker通过XCLAIM接手，默认5分钟
+// 必须大于所有任务的超时时间，否则Start返回错误
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(q *Queue) {
+		q.visibility = d
+	}
+}
+
+// WithDrainTimeout Stop时等待正在处理的任务完成的最长时间，默认30秒，超时后取消任务，由其他worker接手
+func WithDrainTimeout(d time.Duration) Option {
+	return func(q *Queue) {
+		q.drain = d
+	}
+}
+
+// WithBackoff 重试的退避时间为base*2^(attempt-1)，最大max，默认1秒和10分钟
+func WithBackoff(base, max time.Duration) Option {
+	return func(q *Queue) {
+		q.backoffBase = base
+		q.backoffMax = max
+	}
+}
+
+// WithMaxRetries 默认的最大重试次数，默认3
+func WithMaxRetries(n int) Option {
+	return func(q *Queue) {
+		q.maxRetries = n
+	}
+}
+
+// WithTimeout 默认的任务超时时间，默认1分钟
+func WithTimeout(d time.Duration) Option {
+	return func(q *Queue) {
+		q.timeout = d
+	}
+}
+
+// WithPollInterval 没有任务时阻塞读取的时间和检查延时任务的间隔，默认1秒
+func WithPollInterval(d time.Duration) Option {
+	return func(q *Queue) {
+		q.poll = d
+	}
+}
+
+// WithTracerProvider trace，任务的trace延续入队时的trace
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(q *Queue) {
+		q.tp = tp
+	}
+}
+
+// WithLogger 日志
+func WithLogger(l log.Logger) Option {
+	return func(q *Queue) {
+		q.logger = logger.Module(l, "queue")
+		q.log = log.NewHelper(q.logger)
+	}
+}
+
+// HandlerOption 任务类型的选项
+type HandlerOption func(h *handler)
+
+// MaxRetries 代替默认的最大重试次数，0为不重试
+func MaxRetries(n int) HandlerOption {
+	return func(h *handler) {
+		h.maxRetries = n
+	}
+}
+
+// Timeout 代替默认的任务超时时间，必须小于Queue的visibility timeout
+func Timeout(d time.Duration) HandlerOption {
+	return func(h *handler) {
+		h.timeout = d
+	}
+}
+
+type handler struct {
+	fn         Handler
+	maxRetries int
+	timeout    time.Duration
+}
+
+// EnqueueOption Enqueue的选项
+type EnqueueOption func(m *message, at *time.Time)
+
+// Delay 延时执行
+func Delay(d time.Duration) EnqueueOption {
+	return func(m *message, at *time.Time) {
+		*at = time.Now().Add(d)
+	}
+}
+
+// At 在指定时间执行
+func At(t time.Time) EnqueueOption {
+	return func(m *message, at *time.Time) {
+		*at = t
+	}
+}
+
+// WithPriority 优先级，默认PriorityDefault
+func WithPriority(p Priority) EnqueueOption {
+	return func(m *message, at *time.Time) {
+		m.priority = p
+	}
+}
+
+/*Queue 基于redis stream消费组的任务队列，实现transport.Server
+redis的key:
+*	{prefix}{priority}	各优先级的stream
+*	{prefix}delayed   	延时和等待重试的任务，sorted set，score为执行时间
+*	{prefix}dead      	超过重试次数的任务
+*/
+type Queue struct {
+	rdb         redis.UniversalClient
+	prefix      string
+	group       string
+	consumer    string
+	concurrency int
+	visibility  time.Duration
+	drain       time.Duration
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	maxRetries  int
+	timeout     time.Duration
+	poll        time.Duration
+	tp          trace.TracerProvider
+	producer    *tracing.Tracer
+	worker      *tracing.Tracer
+	logger      log.Logger // 处理任务时按ctx带上trace_id/span_id
+	log         *log.Helper
+
+	mu       sync.RWMutex
+	handlers map[string]*handler
+	claims   chan claim // reclaim接手的任务，交给worker处理
+
+	ctx    context.Context // 取任务，Stop时取消
+	cancel context.CancelFunc
+	jobs   context.Context // 执行中的任务，drain超时时取消
+	abort  context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+/*New 创建任务队列，只入队时不需要Start
+参数:
+*	rdb 	redis.UniversalClient
+*	opts	...Option
+返回值:
+*	*Queue	*Queue
+*/
+func New(rdb redis.UniversalClient, opts ...Option) *Queue {
+	q := &Queue{
+		rdb:         rdb,
+		prefix:      "queue:",
+		group:       "workers",
+		concurrency: 10,
+		visibility:  5 * time.Minute,
+		drain:       30 * time.Second,
+		backoffBase: time.Second,
+		backoffMax:  10 * time.Minute,
+		maxRetries:  3,
+		timeout:     time.Minute,
+		poll:        time.Second,
+		handlers:    make(map[string]*handler),
+		claims:      make(chan claim),
+	}
+	q.logger = logger.Module(log.DefaultLogger, "queue")
+	q.log = log.NewHelper(q.logger)
+	for _, o := range opts {
+		o(q)
+	}
+	var tracingOpts []tracing.Option
+	if q.tp != nil {
+		tracingOpts = append(tracingOpts, tracing.WithTracerProvider(q.tp))
+	}
+	q.producer = tracing.NewTracer(trace.SpanKindClient, tracingOpts...)
+	q.worker = tracing.NewTracer(trace.SpanKindServer, tracingOpts...)
+	hostname, _ := os.Hostname()
+	q.consumer = fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), randomID()[:8])
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	q.jobs, q.abort = context.WithCancel(context.Background())
+	return q
+}
+
+/*Register 注册任务类型的处理函数，需要在Start之前注册
+参数:
+*	jobType	string
+*	fn     	Handler
+*	opts   	...HandlerOption
+*/
+func (q *Queue) Register(jobType string, fn Handler, opts ...HandlerOption) {
+	h := &handler{fn: fn, maxRetries: q.maxRetries, timeout: q.timeout}
+	for _, o := range opts {
+		o(h)
+	}
+	q.mu.Lock()
+	q.handlers[jobType] = h
+	q.mu.Unlock()
+}
+
+func (q *Queue) handler(jobType string) (*handler, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	h, ok := q.handlers[jobType]
+	return h, ok
+}
+
+func (q *Queue) stream(p Priority) string {
+	return q.prefix + p.String()
+}
+
+func (q *Queue) delayedKey() string {
+	return q.prefix + "delayed"
+}
+
+// DeadKey 死信stream的key，可以用XRANGE查看失败的任务
+func (q *Queue) DeadKey() string {
+	return q.prefix + "dead"
+}
+
+/*Enqueue 任务入队，ctx中的trace传递给处理任务的worker
+参数:
+*	ctx    	context.Context
+*	jobType	string
+*	payload	interface{}		使用kratos的json codec编码，[]byte原样保存
+*	opts   	...EnqueueOption
+返回值:
+*	string	string	任务id
+*	error 	error
+*/
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...EnqueueOption) (id string, err error) {
+	m := &message{
+		id:       randomID(),
+		jobType:  jobType,
+		priority: PriorityDefault,
+		enqueued: time.Now().UnixNano() / int64(time.Millisecond),
+		trace:    oteltrace.MapCarrier{},
+	}
+	var at time.Time
+	for _, o := range opts {
+		o(m, &at)
+	}
+	if b, ok := payload.([]byte); ok {
+		m.payload = string(b)
+	} else {
+		b, err := encoding.GetCodec("json").Marshal(payload)
+		if err != nil {
+			return "", fmt.Errorf("编码任务[%s]: %w", jobType, err)
+		}
+		m.payload = string(b)
+	}
+	ctx, span := q.producer.Start(ctx, "queue", "enqueue "+jobType, m.trace)
+	defer func() { q.producer.End(ctx, span, err) }()
+
+	if !at.IsZero() && at.After(time.Now()) {
+		err = q.rdb.ZAdd(ctx, q.delayedKey(), &redis.Z{Score: float64(at.UnixNano() / int64(time.Millisecond)), Member: m.delayed(q.stream(m.priority))}).Err()
+	} else {
+		err = q.rdb.XAdd(ctx, &redis.XAddArgs{Stream: q.stream(m.priority), Values: m.values()}).Err()
+	}
+	if err != nil {
+		return "", fmt.Errorf("任务[%s]入队: %w", jobType, err)
+	}
+	return m.id, nil
+}
+
+// backoff 第attempt次执行失败后的等待时间
+func (q *Queue) backoff(attempt int) time.Duration {
+	d := q.backoffBase
+	for i := 1; i < attempt && d < q.backoffMax; i++ {
+		d *= 2
+	}
+	if d > q.backoffMax {
+		d = q.backoffMax
+	}
+	return d
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,211 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type payload struct {
+	N int `json:"n"`
+}
+
+func newTestQueue(t *testing.T, opts ...Option) (*redis.Client, *Queue) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	opts = append([]Option{WithPollInterval(20 * time.Millisecond), WithBackoff(10*time.Millisecond, time.Second)}, opts...)
+	return rdb, New(rdb, opts...)
+}
+
+func start(t *testing.T, q *Queue) {
+	go func() { _ = q.Start() }()
+	t.Cleanup(func() { _ = q.Stop() })
+}
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+	sr := new(oteltest.SpanRecorder)
+	_, q := newTestQueue(t, WithConcurrency(1), WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))))
+
+	var mu sync.Mutex
+	var got []int
+	traces := make(map[int]trace.TraceID)
+	done := make(chan struct{}, 10)
+	q.Register("echo", func(ctx context.Context, job *Job) error {
+		var p payload
+		if err := job.Decode(&p); err != nil {
+			return err
+		}
+		mu.Lock()
+		got = append(got, p.N)
+		traces[p.N] = trace.SpanContextFromContext(ctx).TraceID()
+		mu.Unlock()
+		done <- struct{}{}
+		return nil
+	})
+
+	// 启动前入队，高优先级先处理
+	_, err := q.Enqueue(ctx, "echo", payload{N: 1}, WithPriority(PriorityLow))
+	require.NoError(t, err)
+	_, err = q.Enqueue(ctx, "echo", payload{N: 2})
+	require.NoError(t, err)
+	_, err = q.Enqueue(ctx, "echo", payload{N: 3}, WithPriority(PriorityHigh))
+	require.NoError(t, err)
+	_, err = q.Enqueue(ctx, "echo", payload{N: 4}, Delay(200*time.Millisecond))
+	require.NoError(t, err)
+	enqueued := time.Now()
+	start(t, q)
+
+	for i := 0; i < 4; i++ {
+		select {
+		case <-done:
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	require.True(t, time.Since(enqueued) >= 200*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []int{3, 2, 1, 4}, got)
+
+	// 处理任务的span和入队的span在同一个trace中
+	spans := sr.Completed()
+	enqueueTraces := make(map[trace.TraceID]bool)
+	for _, span := range spans {
+		if span.Name() == "enqueue echo" {
+			enqueueTraces[span.SpanContext().TraceID()] = true
+		}
+	}
+	require.Len(t, enqueueTraces, 4)
+	for _, tid := range traces {
+		require.True(t, enqueueTraces[tid])
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	rdb, q := newTestQueue(t)
+	var mu sync.Mutex
+	var attempts []int
+	q.Register("fail", func(ctx context.Context, job *Job) error {
+		mu.Lock()
+		attempts = append(attempts, job.Attempt)
+		mu.Unlock()
+		if job.Attempt == 2 {
+			panic("boom")
+		}
+		return errors.New("failed")
+	}, MaxRetries(2))
+	start(t, q)
+
+	id, err := q.Enqueue(ctx, "fail", []byte(`{}`))
+	require.NoError(t, err)
+	_, err = q.Enqueue(ctx, "unknown", nil)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return rdb.XLen(ctx, q.DeadKey()).Val() == 2
+	}, 3*time.Second, 20*time.Millisecond)
+	mu.Lock()
+	require.Equal(t, []int{1, 2, 3}, attempts)
+	mu.Unlock()
+
+	dead, err := rdb.XRange(ctx, q.DeadKey(), "-", "+").Result()
+	require.NoError(t, err)
+	errs := make(map[string]string)
+	for _, msg := range dead {
+		m, err := parseMessage(msg.Values)
+		require.NoError(t, err)
+		errs[m.jobType] = m.err
+		if m.jobType == "fail" {
+			require.Equal(t, id, m.id)
+			require.Equal(t, 3, m.attempt)
+		}
+	}
+	require.Equal(t, map[string]string{"fail": "failed", "unknown": ErrUnknownJob.Error()}, errs)
+	// 处理完的任务从stream中删除
+	require.Zero(t, rdb.XLen(ctx, q.stream(PriorityDefault)).Val())
+}
+
+func TestReclaim(t *testing.T) {
+	ctx := context.Background()
+	rdb, q := newTestQueue(t, WithVisibilityTimeout(200*time.Millisecond), WithTimeout(time.Second), WithConcurrency(2))
+	q.Register("job", func(ctx context.Context, job *Job) error { return nil })
+	// 任务的超时时间不小于visibility timeout时不启动
+	require.Error(t, q.Start())
+
+	rdb, q = newTestQueue(t, WithVisibilityTimeout(200*time.Millisecond), WithTimeout(150*time.Millisecond), WithConcurrency(2))
+	// 两个任务都开始后才返回，接手的任务在reclaim中顺序执行时超时
+	var started int32
+	all := make(chan struct{})
+	done := make(chan int, 2)
+	q.Register("job", func(ctx context.Context, job *Job) error {
+		if atomic.AddInt32(&started, 1) == 2 {
+			close(all)
+		}
+		select {
+		case <-all:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		done <- job.Attempt
+		return nil
+	})
+	require.NoError(t, rdb.XGroupCreateMkStream(ctx, q.stream(PriorityDefault), q.group, "0").Err())
+	for i := 0; i < 2; i++ {
+		_, err := q.Enqueue(ctx, "job", nil)
+		require.NoError(t, err)
+	}
+
+	// 其他worker取出后没有确认
+	msgs, err := rdb.XReadGroup(ctx, &redis.XReadGroupArgs{Group: q.group, Consumer: "crashed", Streams: []string{q.stream(PriorityDefault), ">"}, Count: 2}).Result()
+	require.NoError(t, err)
+	require.Len(t, msgs[0].Messages, 2)
+
+	start(t, q)
+	for i := 0; i < 2; i++ {
+		select {
+		case attempt := <-done:
+			require.Equal(t, 2, attempt)
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	require.Eventually(t, func() bool {
+		return rdb.XPending(ctx, q.stream(PriorityDefault), q.group).Val().Count == 0
+	}, time.Second, 20*time.Millisecond)
+}
+
+func TestDrain(t *testing.T) {
+	ctx := context.Background()
+	rdb, q := newTestQueue(t)
+	started := make(chan struct{})
+	finished := make(chan error, 1)
+	q.Register("slow", func(ctx context.Context, job *Job) error {
+		close(started)
+		select {
+		case <-time.After(300 * time.Millisecond):
+			finished <- nil
+		case <-ctx.Done():
+			finished <- ctx.Err()
+		}
+		return nil
+	})
+	go func() { _ = q.Start() }()
+	_, err := q.Enqueue(ctx, "slow", nil)
+	require.NoError(t, err)
+	<-started
+	require.NoError(t, q.Stop())
+	require.NoError(t, <-finished)
+	require.Zero(t, rdb.XLen(ctx, q.stream(PriorityDefault)).Val())
+}
@@ -0,0 +1,317 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/logger"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-redis/redis/v8"
+)
+
+var _ transport.Server = (*Queue)(nil)
+
+// promoteScript 把到期的延时任务移到对应的stream，成员格式见message.delayed
+// KEYS[1] 延时队列; ARGV[1] 当前毫秒, ARGV[2] 每次最多移动的数量
+var promoteScript = redis.NewScript(`
+local due = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+for _, member in ipairs(due) do
+	local sep = string.find(member, "\n", 1, true)
+	local values = cjson.decode(string.sub(member, sep + 1))
+	redis.call("XADD", string.sub(member, 1, sep - 1), "*", unpack(values))
+	redis.call("ZREM", KEYS[1], member)
+end
+return #due
+`)
+
+func (q *Queue) Endpoint() (string, error) {
+	return "redis://" + q.prefix, nil
+}
+
+// claim reclaim接手的任务
+type claim struct {
+	stream     string
+	msg        redis.XMessage
+	deliveries int64
+}
+
+// Start 创建消费组并启动worker，阻塞到Stop
+// 任务的超时时间不小于visibility timeout时返回错误，否则任务还在执行时会被其他worker接手，重复执行
+func (q *Queue) Start() error {
+	if err := q.validate(); err != nil {
+		return err
+	}
+	for _, p := range priorities {
+		err := q.rdb.XGroupCreateMkStream(q.ctx, q.stream(p), q.group, "0").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return fmt.Errorf("创建消费组[%s]: %w", q.stream(p), err)
+		}
+	}
+	q.log.Infof("任务队列[%s]启动, consumer: %s, 并发: %d", q.prefix, q.consumer, q.concurrency)
+	for i := 0; i < q.concurrency; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	q.wg.Add(2)
+	go q.promote()
+	go q.reclaim()
+	<-q.ctx.Done()
+	return nil
+}
+
+// Stop 停止取新的任务，等待正在处理的任务完成，超过drain时间后取消任务
+func (q *Queue) Stop() error {
+	q.once.Do(q.cancel)
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(q.drain):
+		q.log.Warnf("任务队列[%s]等待任务完成超时，取消正在处理的任务", q.prefix)
+		q.abort()
+		<-done
+	}
+	q.abort()
+	q.log.Infof("任务队列[%s]停止", q.prefix)
+	return nil
+}
+
+func (q *Queue) validate() error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	for jobType, h := range q.handlers {
+		if h.timeout >= q.visibility {
+			return fmt.Errorf("任务[%s]的超时时间%s不小于visibility timeout %s", jobType, h.timeout, q.visibility)
+		}
+	}
+	return nil
+}
+
+// work 先处理reclaim接手的任务，再按优先级取任务并处理
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for q.ctx.Err() == nil {
+		select {
+		case c := <-q.claims:
+			q.process(c.stream, c.msg, c.deliveries)
+			continue
+		default:
+		}
+		stream, msg, err := q.next()
+		if err != nil {
+			if q.ctx.Err() == nil {
+				q.log.Errorf("读取任务失败: %+v", err)
+				q.sleep(q.poll)
+			}
+			continue
+		}
+		if msg != nil {
+			q.process(stream, *msg, 1)
+		}
+	}
+}
+
+// next 先依次不阻塞地读取各优先级，都没有时阻塞读取全部stream
+func (q *Queue) next() (string, *redis.XMessage, error) {
+	for _, p := range priorities {
+		stream, msg, err := q.read([]string{q.stream(p), ">"}, -1)
+		if err != nil || msg != nil {
+			return stream, msg, err
+		}
+	}
+	streams := make([]string, 0, len(priorities)*2)
+	for _, p := range priorities {
+		streams = append(streams, q.stream(p))
+	}
+	for range priorities {
+		streams = append(streams, ">")
+	}
+	return q.read(streams, q.poll)
+}
+
+func (q *Queue) read(streams []string, block time.Duration) (string, *redis.XMessage, error) {
+	result, err := q.rdb.XReadGroup(q.ctx, &redis.XReadGroupArgs{
+		Group:    q.group,
+		Consumer: q.consumer,
+		Streams:  streams,
+		Count:    1,
+		Block:    block,
+	}).Result()
+	if err == redis.Nil {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	for _, s := range result {
+		if len(s.Messages) > 0 {
+			return s.Stream, &s.Messages[0], nil
+		}
+	}
+	return "", nil, nil
+}
+
+/*process 处理一个任务，成功时确认并删除，失败时放入延时队列重试或者进入死信
+参数:
+*	stream    	string
+*	msg       	redis.XMessage
+*	deliveries	int64	被取出的次数，大于1说明之前的worker没有完成
+*/
+func (q *Queue) process(stream string, msg redis.XMessage, deliveries int64) {
+	m, err := parseMessage(msg.Values)
+	if err != nil {
+		q.log.Errorf("%+v", err)
+		q.finish(stream, msg.ID, nil, 0)
+		return
+	}
+	// 之前的worker没有完成，算作失败的执行
+	m.attempt += int(deliveries - 1)
+	h, ok := q.handler(m.jobType)
+	if !ok {
+		m.err = ErrUnknownJob.Error()
+		q.finish(stream, msg.ID, m, -1)
+		return
+	}
+	if m.attempt > h.maxRetries {
+		m.err = "处理超时"
+		q.finish(stream, msg.ID, m, -1)
+		return
+	}
+	job := m.job()
+	ctx, cancel := context.WithTimeout(q.jobs, h.timeout)
+	ctx, span := q.worker.Start(ctx, "queue", "process "+m.jobType, m.trace)
+	err = q.run(ctx, h, job)
+	q.worker.End(ctx, span, err)
+	cancel()
+	if err == nil {
+		q.finish(stream, msg.ID, nil, 0)
+		return
+	}
+	m.attempt++
+	m.err = err.Error()
+	helper := logger.NewHelper(ctx, q.logger)
+	if m.attempt > h.maxRetries {
+		helper.Errorf("任务[%s:%s]第%d次执行失败，进入死信: %+v", m.jobType, m.id, job.Attempt, err)
+		q.finish(stream, msg.ID, m, -1)
+		return
+	}
+	backoff := q.backoff(m.attempt)
+	helper.Warnf("任务[%s:%s]第%d次执行失败，%s后重试: %v", m.jobType, m.id, job.Attempt, backoff, err)
+	m.err = ""
+	q.finish(stream, msg.ID, m, backoff)
+}
+
+// run 执行Handler，panic作为错误
+func (q *Queue) run(ctx context.Context, h *handler, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h.fn(ctx, job)
+}
+
+/*finish 确认并删除stream中的任务
+参数:
+*	stream	string
+*	id    	string		stream中的id
+*	m     	*message	不为nil时重新放入延时队列或者死信
+*	retry 	time.Duration	小于0时m进入死信，否则在retry后重试
+*/
+func (q *Queue) finish(stream, id string, m *message, retry time.Duration) {
+	// 任务已经完成，Stop时也要确认
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pipe := q.rdb.TxPipeline()
+	switch {
+	case m != nil && retry < 0:
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: q.DeadKey(), Values: m.values()})
+	case m != nil:
+		at := time.Now().Add(retry).UnixNano() / int64(time.Millisecond)
+		pipe.ZAdd(ctx, q.delayedKey(), &redis.Z{Score: float64(at), Member: m.delayed(stream)})
+	}
+	pipe.XAck(ctx, stream, q.group, id)
+	pipe.XDel(ctx, stream, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		q.log.Errorf("确认任务[%s:%s]失败: %+v", stream, id, err)
+	}
+}
+
+// promote 定期把到期的延时任务移到stream
+func (q *Queue) promote() {
+	defer q.wg.Done()
+	for q.ctx.Err() == nil {
+		now := time.Now().UnixNano() / int64(time.Millisecond)
+		n, err := promoteScript.Run(q.ctx, q.rdb, []string{q.delayedKey()}, now, 100).Int()
+		if err != nil && q.ctx.Err() == nil {
+			q.log.Errorf("移动延时任务失败: %+v", err)
+		}
+		// 还有到期的任务时立即继续
+		if n < 100 {
+			q.sleep(q.poll)
+		}
+	}
+}
+
+// reclaim 定期接手超过visibility没有确认的任务，原来的worker可能已经退出，接手的任务交给worker处理
+func (q *Queue) reclaim() {
+	defer q.wg.Done()
+	for q.ctx.Err() == nil {
+		q.sleep(q.visibility / 2)
+		for _, p := range priorities {
+			if err := q.reclaimStream(q.stream(p)); err != nil && q.ctx.Err() == nil {
+				q.log.Errorf("接手超时任务失败: %+v", err)
+			}
+		}
+	}
+}
+
+func (q *Queue) reclaimStream(stream string) error {
+	pending, err := q.rdb.XPendingExt(q.ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  q.group,
+		Start:  "-",
+		End:    "+",
+		Count:  100,
+	}).Result()
+	if err != nil {
+		return err
+	}
+	for _, p := range pending {
+		if p.Idle < q.visibility || q.ctx.Err() != nil {
+			continue
+		}
+		msgs, err := q.rdb.XClaim(q.ctx, &redis.XClaimArgs{
+			Stream:   stream,
+			Group:    q.group,
+			Consumer: q.consumer,
+			MinIdle:  q.visibility,
+			Messages: []string{p.ID},
+		}).Result()
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			q.log.Warnf("接手任务[%s:%s], 原consumer: %s", stream, msg.ID, p.Consumer)
+			// Stop时没有交出的任务仍然属于这个consumer，之后由其他worker接手
+			select {
+			case q.claims <- claim{stream: stream, msg: msg, deliveries: p.RetryCount + 1}:
+			case <-q.ctx.Done():
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+func (q *Queue) sleep(d time.Duration) {
+	select {
+	case <-q.ctx.Done():
+	case <-time.After(d):
+	}
+}
@@ -0,0 +1,453 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/lock"
+	"github.com/go-kratos/kratos-layout/pkg/logger"
+	"github.com/go-kratos/kratos-layout/pkg/tools"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-redis/redis/v8"
+	"github.com/robfig/cron/v3"
+)
+
+// 执行结果，metrics的result标签
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultSkipped = "skipped" // 上一次还没有结束
+	ResultMissed  = "missed"  // 停机或者上一次执行时间过长错过的执行
+)
+
+// parser 支持可选的秒和@every、@daily等描述
+var parser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// MissedPolicy 错过执行时间时的处理
+type MissedPolicy int
+
+const (
+	MissedSkip    MissedPolicy = iota // 不补执行，等下一次
+	MissedRunOnce                     // 立即补执行一次，错过多次也只执行一次
+)
+
+// Option Scheduler的选项
+type Option func(s *Scheduler)
+
+// WithPrefix redis key的前缀，默认"cron:"
+func WithPrefix(prefix string) Option {
+	return func(s *Scheduler) {
+		s.prefix = prefix
+	}
+}
+
+// WithLocation 解析cron表达式的时区，默认tools.Location()
+func WithLocation(loc *time.Location) Option {
+	return func(s *Scheduler) {
+		s.location = loc
+	}
+}
+
+// WithRunCounter 执行次数，标签为job和result
+func WithRunCounter(c metrics.Counter) Option {
+	return func(s *Scheduler) {
+		s.runs = c
+	}
+}
+
+// WithDurationObserver 执行耗时(秒)，标签为job
+func WithDurationObserver(o metrics.Observer) Option {
+	return func(s *Scheduler) {
+		s.durations = o
+	}
+}
+
+// WithLogger 日志
+func WithLogger(l log.Logger) Option {
+	return func(s *Scheduler) {
+		s.log = log.NewHelper(logger.Module(l, "scheduler"))
+	}
+}
+
+// JobOption 任务的选项
+type JobOption func(j *job)
+
+// Timeout 每次执行的超时时间，默认不限制
+func Timeout(d time.Duration) JobOption {
+	return func(j *job) {
+		j.timeout = d
+	}
+}
+
+// Jitter 执行前随机等待[0, d)，避免大量任务同时开始
+func Jitter(d time.Duration) JobOption {
+	return func(j *job) {
+		j.jitter = d
+	}
+}
+
+// Missed 错过执行时间时的处理，默认MissedSkip
+func Missed(policy MissedPolicy) JobOption {
+	return func(j *job) {
+		j.missed = policy
+	}
+}
+
+// Local 每个实例都执行，不使用redis锁
+func Local() JobOption {
+	return func(j *job) {
+		j.local = true
+	}
+}
+
+// Stats 任务的执行统计
+type Stats struct {
+	Name         string
+	Spec         string
+	Runs         uint64
+	Failures     uint64
+	Skipped      uint64
+	Missed       uint64
+	LastRun      time.Time // 最近一次在本实例执行的计划时间
+	LastDuration time.Duration
+	LastError    string
+	Next         time.Time
+}
+
+type job struct {
+	name     string
+	spec     string
+	schedule cron.Schedule
+	fn       func(ctx context.Context) error
+	timeout  time.Duration
+	jitter   time.Duration
+	missed   MissedPolicy
+	local    bool
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+/*Scheduler 定时任务，实现transport.Server
+同一个计划时间在集群中只执行一次: 先SETNX计划时间抢占执行权，再持有锁执行，上一次还没有结束时跳过
+redis的key:
+*	{prefix}run:{name}:{unix}	计划时间的执行权
+*	{prefix}last:{name}     	最近一次执行的计划时间，用于启动时判断错过的执行
+*	{prefix}lock:{name}     	执行中的锁
+*/
+type Scheduler struct {
+	rdb       redis.UniversalClient
+	locker    *lock.Locker
+	prefix    string
+	location  *time.Location
+	runs      metrics.Counter
+	durations metrics.Observer
+	log       *log.Helper
+
+	jobs   map[string]*job
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+var _ transport.Server = (*Scheduler)(nil)
+
+/*New 创建定时任务
+参数:
+*	rdb 	redis.UniversalClient
+*	opts	...Option
+返回值:
+*	*Scheduler	*Scheduler
+*/
+func New(rdb redis.UniversalClient, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		rdb:      rdb,
+		prefix:   "cron:",
+		location: tools.Location(),
+		log:      log.NewHelper(logger.Module(log.DefaultLogger, "scheduler")),
+		jobs:     make(map[string]*job),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	s.locker = lock.NewLocker(rdb, lock.WithPrefix(s.prefix+"lock:"))
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	return s
+}
+
+/*Add 添加任务，需要在Start之前添加
+参数:
+*	name	string		任务名，集群中唯一
+*	spec	string		cron表达式，支持可选的秒字段和@every 1h、@daily等
+*	fn  	func(ctx context.Context) error		Stop、超时或者失去锁时ctx取消
+*	opts	...JobOption
+返回值:
+*	error	error	表达式无效或者任务名重复
+*/
+func (s *Scheduler) Add(name, spec string, fn func(ctx context.Context) error, opts ...JobOption) error {
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("定时任务[%s]重复", name)
+	}
+	schedule, err := parser.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("定时任务[%s]的表达式[%s]无效: %w", name, spec, err)
+	}
+	j := &job{name: name, spec: spec, schedule: schedule, fn: fn}
+	for _, o := range opts {
+		o(j)
+	}
+	j.stats = Stats{Name: name, Spec: spec}
+	s.jobs[name] = j
+	return nil
+}
+
+// Stats 所有任务的执行统计，按任务名排序
+func (s *Scheduler) Stats() []Stats {
+	stats := make([]Stats, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		j.mu.Lock()
+		stats = append(stats, j.stats)
+		j.mu.Unlock()
+	}
+	sort.Slice(stats, func(i, k int) bool { return stats[i].Name < stats[k].Name })
+	return stats
+}
+
+func (s *Scheduler) Endpoint() (string, error) {
+	return "cron://" + s.prefix, nil
+}
+
+// Start 启动所有任务，阻塞到Stop
+func (s *Scheduler) Start() error {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+	s.log.Infof("定时任务启动, 任务数: %d, 时区: %s", len(s.jobs), s.location)
+	<-s.ctx.Done()
+	return nil
+}
+
+// Stop 取消正在执行的任务并等待结束
+func (s *Scheduler) Stop() error {
+	s.once.Do(s.cancel)
+	s.wg.Wait()
+	s.log.Info("定时任务停止")
+	return nil
+}
+
+// loop 按计划执行一个任务
+func (s *Scheduler) loop(j *job) {
+	defer s.wg.Done()
+	now := time.Now().In(s.location)
+	if fired, ok := s.missedSinceLast(j, now); ok {
+		s.run(j, fired)
+	}
+	next := j.schedule.Next(time.Now().In(s.location))
+	for {
+		j.setNext(next)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.run(j, next)
+		now = time.Now().In(s.location)
+		fired := next
+		next = j.schedule.Next(fired)
+		if !next.After(now) {
+			// 执行时间超过了间隔
+			latest, count := latestBefore(j.schedule, fired, now)
+			s.observeMissed(j, count)
+			if j.missed == MissedRunOnce {
+				s.run(j, latest)
+			}
+			next = j.schedule.Next(time.Now().In(s.location))
+		}
+	}
+}
+
+// advanceScript 最近执行时间还是ARGV[1]时改为ARGV[2]，多个实例同时启动时只有一个统计错过的执行
+var advanceScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// missedSinceLast 启动时根据最近一次执行的计划时间判断停机期间错过的执行，
+// 把最近执行时间推进到最后错过的计划时间，错过的执行只由推进成功的实例统计一次
+func (s *Scheduler) missedSinceLast(j *job, now time.Time) (time.Time, bool) {
+	if j.local {
+		return time.Time{}, false
+	}
+	last, err := s.rdb.Get(s.ctx, s.key("last", j.name)).Int64()
+	if err != nil {
+		if err != redis.Nil {
+			s.log.Errorf("查询定时任务[%s]最近执行时间失败: %+v", j.name, err)
+		}
+		return time.Time{}, false
+	}
+	latest, count := latestBefore(j.schedule, time.Unix(last, 0).In(s.location), now)
+	if count == 0 {
+		return time.Time{}, false
+	}
+	advanced, err := advanceScript.Run(s.ctx, s.rdb, []string{s.key("last", j.name)}, last, latest.Unix()).Int()
+	if err != nil {
+		s.log.Errorf("定时任务[%s]更新最近执行时间失败: %+v", j.name, err)
+		return time.Time{}, false
+	}
+	if advanced == 0 { // 其他实例已经处理
+		return time.Time{}, false
+	}
+	s.log.Warnf("定时任务[%s]错过%d次执行，最近一次: %s", j.name, count, latest)
+	s.observeMissed(j, count)
+	return latest, j.missed == MissedRunOnce
+}
+
+// latestBefore from之后到now之间最后一个计划时间和计划时间的数量，数量最多统计10000
+func latestBefore(schedule cron.Schedule, from, now time.Time) (time.Time, int) {
+	var latest time.Time
+	count := 0
+	for t := schedule.Next(from); !t.After(now) && count < 10000; t = schedule.Next(t) {
+		latest = t
+		count++
+	}
+	return latest, count
+}
+
+func (s *Scheduler) key(kind, name string) string {
+	return s.prefix + kind + ":" + name
+}
+
+// run 抢占计划时间的执行权并执行
+func (s *Scheduler) run(j *job, fired time.Time) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	ctx := s.ctx
+	if !j.local {
+		// 执行权保留到下一次计划时间之后，晚到的实例不会重复执行
+		ttl := 2 * j.schedule.Next(fired).Sub(fired)
+		if ttl < time.Minute {
+			ttl = time.Minute
+		}
+		claimed, err := s.rdb.SetNX(ctx, s.key("run", j.name)+":"+strconv.FormatInt(fired.Unix(), 10), 1, ttl).Result()
+		if err != nil {
+			s.log.Errorf("定时任务[%s]抢占执行失败: %+v", j.name, err)
+			return
+		}
+		if !claimed {
+			return
+		}
+		lk, err := s.locker.TryLock(ctx, j.name)
+		if errors.Is(err, lock.ErrNotAcquired) {
+			s.log.Warnf("定时任务[%s]上一次还没有结束，跳过%s的执行", j.name, fired)
+			s.observe(j, ResultSkipped, 0, nil)
+			return
+		}
+		if err != nil {
+			s.log.Errorf("定时任务[%s]获取锁失败: %+v", j.name, err)
+			return
+		}
+		defer func() {
+			if err := lk.Release(context.Background()); err != nil {
+				s.log.Errorf("定时任务[%s]释放锁失败: %+v", j.name, err)
+			}
+		}()
+		var cancel context.CancelFunc
+		ctx, cancel = lk.Context(ctx)
+		defer cancel()
+		if err = s.rdb.Set(ctx, s.key("last", j.name), fired.Unix(), 0).Err(); err != nil {
+			s.log.Errorf("定时任务[%s]保存执行时间失败: %+v", j.name, err)
+		}
+	}
+	if j.jitter > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(rand.Int63n(int64(j.jitter)))):
+		}
+	}
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	err := s.call(ctx, j)
+	duration := time.Since(start)
+	j.mu.Lock()
+	j.stats.LastRun = fired
+	j.mu.Unlock()
+	if err != nil {
+		s.log.Errorf("定时任务[%s]执行失败, 计划时间: %s, 耗时: %s: %+v", j.name, fired, duration, err)
+		s.observe(j, ResultFailure, duration, err)
+		return
+	}
+	s.log.Infof("定时任务[%s]执行完成, 计划时间: %s, 耗时: %s", j.name, fired, duration)
+	s.observe(j, ResultSuccess, duration, nil)
+}
+
+// call 执行任务，panic作为错误
+func (s *Scheduler) call(ctx context.Context, j *job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.fn(ctx)
+}
+
+func (s *Scheduler) observe(j *job, result string, duration time.Duration, err error) {
+	if s.runs != nil {
+		s.runs.With(j.name, result).Inc()
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	switch result {
+	case ResultSkipped:
+		j.stats.Skipped++
+		return
+	case ResultFailure:
+		j.stats.Failures++
+		j.stats.LastError = err.Error()
+	default:
+		j.stats.LastError = ""
+	}
+	j.stats.Runs++
+	j.stats.LastDuration = duration
+	if s.durations != nil {
+		s.durations.With(j.name).Observe(duration.Seconds())
+	}
+}
+
+func (s *Scheduler) observeMissed(j *job, count int) {
+	if count <= 0 {
+		return
+	}
+	if s.runs != nil {
+		s.runs.With(j.name, ResultMissed).Add(float64(count))
+	}
+	j.mu.Lock()
+	j.stats.Missed += uint64(count)
+	j.mu.Unlock()
+}
+
+func (j *job) setNext(next time.Time) {
+	j.mu.Lock()
+	j.stats.Next = next
+	j.mu.Unlock()
+}
@@ -0,0 +1,104 @@
+package scheduler
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-kratos/kratos-layout/pkg/tools"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) *redis.Client {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return rdb
+}
+
+func start(t *testing.T, s *Scheduler) {
+	go func() { _ = s.Start() }()
+	t.Cleanup(func() { _ = s.Stop() })
+}
+
+func TestLocation(t *testing.T) {
+	s := New(nil)
+	require.NoError(t, s.Add("daily", "0 8 * * *", func(ctx context.Context) error { return nil }))
+	// 北京时间8点为UTC 0点
+	from := time.Date(2021, 6, 1, 1, 0, 0, 0, time.UTC)
+	next := s.jobs["daily"].schedule.Next(from.In(tools.Location()))
+	require.True(t, next.Equal(time.Date(2021, 6, 2, 0, 0, 0, 0, time.UTC)), next)
+
+	require.Error(t, s.Add("daily", "@daily", nil))
+	require.Error(t, s.Add("invalid", "* * *", nil))
+}
+
+func TestCluster(t *testing.T) {
+	rdb := newTestRedis(t)
+	var runs int32
+	fn := func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}
+	a, b := New(rdb), New(rdb)
+	require.NoError(t, a.Add("tick", "@every 1s", fn))
+	require.NoError(t, b.Add("tick", "@every 1s", fn))
+	start(t, a)
+	start(t, b)
+
+	time.Sleep(2500 * time.Millisecond)
+	require.NoError(t, a.Stop())
+	require.NoError(t, b.Stop())
+	// 同一个计划时间只执行一次
+	total := atomic.LoadInt32(&runs)
+	require.True(t, total >= 2 && total <= 3, total)
+	require.EqualValues(t, total, a.Stats()[0].Runs+b.Stats()[0].Runs)
+}
+
+func TestMissed(t *testing.T) {
+	ctx := context.Background()
+	rdb := newTestRedis(t)
+	last := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	require.NoError(t, rdb.Set(ctx, "cron:last:once", last, 0).Err())
+	require.NoError(t, rdb.Set(ctx, "cron:last:skip", last, 0).Err())
+
+	done := make(chan error, 1)
+	var skipped int32
+	s := New(rdb)
+	require.NoError(t, s.Add("once", "*/10 * * * *", func(ctx context.Context) error {
+		<-ctx.Done()
+		done <- ctx.Err()
+		return ctx.Err()
+	}, Missed(MissedRunOnce), Timeout(100*time.Millisecond)))
+	require.NoError(t, s.Add("skip", "*/10 * * * *", func(ctx context.Context) error {
+		atomic.AddInt32(&skipped, 1)
+		return nil
+	}))
+	start(t, s)
+
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(2 * time.Second):
+		t.Fatal("missed run should run once at start")
+	}
+	require.Eventually(t, func() bool { return s.Stats()[0].Failures == 1 }, time.Second, 10*time.Millisecond)
+	stats := s.Stats()
+	require.Equal(t, "once", stats[0].Name)
+	require.EqualValues(t, 6, stats[0].Missed)
+	require.Contains(t, stats[0].LastError, "deadline exceeded")
+	require.EqualValues(t, 6, stats[1].Missed)
+	require.Zero(t, atomic.LoadInt32(&skipped))
+	require.Zero(t, stats[1].Runs)
+
+	// 之后启动的实例不再统计同一段时间错过的执行
+	s2 := New(rdb)
+	require.NoError(t, s2.Add("skip", "*/10 * * * *", func(ctx context.Context) error { return nil }))
+	start(t, s2)
+	require.Eventually(t, func() bool { return !s2.Stats()[0].Next.IsZero() }, time.Second, 10*time.Millisecond)
+	require.Zero(t, s2.Stats()[0].Missed)
+}
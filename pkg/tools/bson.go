@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/mongocodec"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+func init() {
+	mongocodec.Register("tools", func(b *mongocodec.Builder) {
+		b.Type(Time{}, TimeCodec{})
+		b.Type(NullTime{}, NullTimeCodec{})
+	})
+}
+
+var (
+	tTime     = reflect.TypeOf(Time{})
+	tNullTime = reflect.TypeOf(NullTime{})
+)
+
+// TimeCodec Time保存为DateTime，和MarshalBSONValue一致；解码时null为零值，字符串按ParseTime解析
+type TimeCodec struct{}
+
+func (TimeCodec) EncodeValue(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || val.Type() != tTime {
+		return bsoncodec.ValueEncoderError{Name: "TimeEncodeValue", Types: []reflect.Type{tTime}, Received: val}
+	}
+	return writeTime(vw, val.Interface().(Time))
+}
+
+func (TimeCodec) DecodeValue(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != tTime {
+		return bsoncodec.ValueDecoderError{Name: "TimeDecodeValue", Types: []reflect.Type{tTime}, Received: val}
+	}
+	var t Time
+	if vr.Type() == bsontype.Null {
+		if err := vr.ReadNull(); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if t, err = readTime(vr); err != nil {
+			return err
+		}
+	}
+	val.Set(reflect.ValueOf(t))
+	return nil
+}
+
+// NullTimeCodec 无效或者Time为零值时为null
+type NullTimeCodec struct{}
+
+func (NullTimeCodec) EncodeValue(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || val.Type() != tNullTime {
+		return bsoncodec.ValueEncoderError{Name: "NullTimeEncodeValue", Types: []reflect.Type{tNullTime}, Received: val}
+	}
+	n := val.Interface().(NullTime)
+	if !n.valid() {
+		return vw.WriteNull()
+	}
+	return writeTime(vw, n.Time)
+}
+
+func (NullTimeCodec) DecodeValue(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != tNullTime {
+		return bsoncodec.ValueDecoderError{Name: "NullTimeDecodeValue", Types: []reflect.Type{tNullTime}, Received: val}
+	}
+	var n NullTime
+	switch vr.Type() {
+	case bsontype.Null:
+		if err := vr.ReadNull(); err != nil {
+			return err
+		}
+	case bsontype.Undefined:
+		if err := vr.ReadUndefined(); err != nil {
+			return err
+		}
+	default:
+		t, err := readTime(vr)
+		if err != nil {
+			return err
+		}
+		n = NullTime{Time: t, Valid: true}
+	}
+	val.Set(reflect.ValueOf(n))
+	return nil
+}
+
+func writeTime(vw bsonrw.ValueWriter, t Time) error {
+	std := time.Time(t)
+	return vw.WriteDateTime(std.Unix()*1e3 + int64(std.Nanosecond()/1e6))
+}
+
+func readTime(vr bsonrw.ValueReader) (Time, error) {
+	switch vr.Type() {
+	case bsontype.DateTime:
+		ms, err := vr.ReadDateTime()
+		if err != nil {
+			return Time{}, err
+		}
+		return Time(time.Unix(ms/1e3, ms%1e3*1e6)), nil
+	case bsontype.String:
+		// 历史数据中以字符串保存的时间
+		s, err := vr.ReadString()
+		if err != nil {
+			return Time{}, err
+		}
+		return ParseTime(s)
+	default:
+		return Time{}, fmt.Errorf("错误的类型[%s],不是Time", vr.Type())
+	}
+}
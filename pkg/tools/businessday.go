@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Calendar 工作日日历，可以接入外部的节假日服务
+type Calendar interface {
+	// IsBusinessDay day为业务时区的某一天，只看日期
+	IsBusinessDay(day Time) bool
+}
+
+// maxNonBusinessDays 连续这么多天都不是工作日时认为日历配置错误，避免AddBusinessDays一直找下去
+const maxNonBusinessDays = 3 * 366
+
+// ErrNoBusinessDay 连续maxNonBusinessDays天都不是工作日
+var ErrNoBusinessDay = errors.New("找不到工作日")
+
+// Weekdays 周一到周五为工作日，没有节假日
+var Weekdays Calendar = weekdays{}
+
+type weekdays struct{}
+
+func (weekdays) IsBusinessDay(day Time) bool {
+	weekday := day.Display().Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday
+}
+
+// HolidayFile 节假日文件的内容，日期为"2006-01-02"，连续的日期可以写成"2021-10-01~2021-10-07"
+type HolidayFile struct {
+	Holidays []string `yaml:"holidays" json:"holidays"` // 放假的日期
+	Workdays []string `yaml:"workdays" json:"workdays"` // 调休上班的周末
+}
+
+// HolidayCalendar 在周一到周五的基础上加入节假日和调休
+type HolidayCalendar struct {
+	holidays map[string]bool
+	workdays map[string]bool
+}
+
+/*LoadHolidayCalendar 从yaml或json文件加载节假日，格式见HolidayFile
+参数:
+*	path	string
+返回值:
+*	*HolidayCalendar	*HolidayCalendar
+*	error           	error
+*/
+func LoadHolidayCalendar(path string) (*HolidayCalendar, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取节假日文件: %w", err)
+	}
+	// json是yaml的子集
+	var file HolidayFile
+	if err = yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析节假日文件[%s]: %w", path, err)
+	}
+	return NewHolidayCalendar(file)
+}
+
+// NewHolidayCalendar .
+func NewHolidayCalendar(file HolidayFile) (*HolidayCalendar, error) {
+	c := &HolidayCalendar{holidays: make(map[string]bool), workdays: make(map[string]bool)}
+	for _, days := range []struct {
+		values []string
+		set    map[string]bool
+	}{{file.Holidays, c.holidays}, {file.Workdays, c.workdays}} {
+		for _, value := range days.values {
+			if err := eachDate(value, func(day string) { days.set[day] = true }); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c, nil
+}
+
+// eachDate 展开"2006-01-02"或"2006-01-02~2006-01-05"
+func eachDate(value string, fn func(day string)) error {
+	from, to := value, value
+	if i := strings.Index(value, "~"); i >= 0 {
+		from, to = strings.TrimSpace(value[:i]), strings.TrimSpace(value[i+1:])
+	}
+	start, err := time.Parse(dayLayout, from)
+	if err != nil {
+		return fmt.Errorf("节假日[%s]: %w", value, err)
+	}
+	end, err := time.Parse(dayLayout, to)
+	if err != nil {
+		return fmt.Errorf("节假日[%s]: %w", value, err)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("节假日[%s]的开始晚于结束", value)
+	}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		fn(day.Format(dayLayout))
+	}
+	return nil
+}
+
+func (c *HolidayCalendar) IsBusinessDay(day Time) bool {
+	key := day.StringDay()
+	switch {
+	case c.holidays[key]:
+		return false
+	case c.workdays[key]:
+		return true
+	default:
+		return Weekdays.IsBusinessDay(day)
+	}
+}
+
+/*EachBusinessDay 按顺序遍历[from, to]之间的工作日，按业务时区的日期计算
+参数:
+*	c   	Calendar	为nil时使用Weekdays
+*	from	Time
+*	to  	Time
+*	fn  	func(day Time) bool	day为当天00:00:00，返回false时停止
+*/
+func EachBusinessDay(c Calendar, from, to Time, fn func(day Time) bool) {
+	if c == nil {
+		c = Weekdays
+	}
+	last := time.Time(to.DayStart())
+	for day := time.Time(from.DayStart()); !day.After(last); day = day.AddDate(0, 0, 1) {
+		if c.IsBusinessDay(Time(day)) && !fn(Time(day)) {
+			return
+		}
+	}
+}
+
+// BusinessDays [from, to]之间的工作日
+func BusinessDays(c Calendar, from, to Time) []Time {
+	var days []Time
+	EachBusinessDay(c, from, to, func(day Time) bool {
+		days = append(days, day)
+		return true
+	})
+	return days
+}
+
+/*AddBusinessDays 加n个工作日，保留时分秒，n为0时返回t
+参数:
+*	c	Calendar	为nil时使用Weekdays
+*	t	Time
+*	n	int	可以为负数
+返回值:
+*	Time 	Time
+*	error	error	连续三年左右都不是工作日时返回ErrNoBusinessDay
+*/
+func AddBusinessDays(c Calendar, t Time, n int) (Time, error) {
+	if c == nil {
+		c = Weekdays
+	}
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	day := t.Display()
+	for skipped := 0; n > 0; {
+		day = day.AddDate(0, 0, step)
+		if c.IsBusinessDay(Time(day)) {
+			n, skipped = n-1, 0
+			continue
+		}
+		if skipped++; skipped >= maxNonBusinessDays {
+			return Time{}, fmt.Errorf("%w, 到%s为止连续%d天不是工作日", ErrNoBusinessDay, Time(day).StringDay(), skipped)
+		}
+	}
+	return Time(day), nil
+}
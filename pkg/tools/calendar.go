@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// isoWeekLayout ISO周的格式，例如2021-W05
+const isoWeekLayout = "%04d-W%02d"
+
+// QuarterStart 本季度第一天00:00:00
+func (z Zone) QuarterStart(t Time) Time {
+	stdTime := z.In(t)
+	month := (stdTime.Month()-1)/3*3 + 1
+	return Time(time.Date(stdTime.Year(), month, 1, 0, 0, 0, 0, z.Location()))
+}
+
+// QuarterEnd 本季度最后一天23:59:59
+func (z Zone) QuarterEnd(t Time) Time {
+	start := time.Time(z.QuarterStart(t))
+	return Time(time.Date(start.Year(), start.Month()+3, 0, 23, 59, 59, 0, z.Location()))
+}
+
+// YearStart 1月1日00:00:00
+func (z Zone) YearStart(t Time) Time {
+	return Time(time.Date(z.In(t).Year(), time.January, 1, 0, 0, 0, 0, z.Location()))
+}
+
+// YearEnd 12月31日23:59:59
+func (z Zone) YearEnd(t Time) Time {
+	return Time(time.Date(z.In(t).Year(), time.December, 31, 23, 59, 59, 0, z.Location()))
+}
+
+/*AddMonths 加n个月，保留时分秒，目标月份没有这一天时取月末，例如1月31日加1个月为2月28日或29日
+参数:
+*	t	Time
+*	n	int	可以为负数
+返回值:
+*	Time	Time
+*/
+func (z Zone) AddMonths(t Time, n int) Time {
+	stdTime := z.In(t)
+	year, month, day := stdTime.Date()
+	// 目标月份的最后一天
+	last := time.Date(year, month+time.Month(n)+1, 0, 0, 0, 0, 0, z.Location()).Day()
+	if day > last {
+		day = last
+	}
+	return Time(time.Date(year, month+time.Month(n), day, stdTime.Hour(), stdTime.Minute(), stdTime.Second(), stdTime.Nanosecond(), z.Location()))
+}
+
+// ISOWeek ISO 8601的年和周，周一为一周的开始，包含1月4日的周为第1周
+func (z Zone) ISOWeek(t Time) (year, week int) {
+	return z.In(t).ISOWeek()
+}
+
+/*ISOWeekStart ISO周的周一00:00:00
+参数:
+*	year	int
+*	week	int	1-53，超出当年的周数时顺延到下一年
+返回值:
+*	Time	Time
+*/
+func (z Zone) ISOWeekStart(year, week int) Time {
+	// 1月4日总是在第1周
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, z.Location())
+	weekday := int(jan4.Weekday()+6) % 7 // 周一为0
+	return Time(time.Date(year, time.January, 4-weekday+(week-1)*7, 0, 0, 0, 0, z.Location()))
+}
+
+// ParseISOWeek 解析"2021-W05"，返回这一周的周一00:00:00
+func (z Zone) ParseISOWeek(data string) (Time, error) {
+	if len(data) != len("2006-W01") || data[4:6] != "-W" {
+		return Time{}, fmt.Errorf("ISO周[%s]的格式应该为2006-W01", data)
+	}
+	year, err := strconv.Atoi(data[:4])
+	if err != nil {
+		return Time{}, fmt.Errorf("ISO周[%s]的年份错误", data)
+	}
+	week, err := strconv.Atoi(data[6:])
+	if err != nil || week < 1 {
+		return Time{}, fmt.Errorf("ISO周[%s]的周数错误", data)
+	}
+	start := z.ISOWeekStart(year, week)
+	if y, w := z.ISOWeek(start); y != year || w != week {
+		return Time{}, fmt.Errorf("%d年没有第%d周", year, week)
+	}
+	return start, nil
+}
+
+// QuarterRange 本季度的开始和结束
+func (t Time) QuarterRange() (Time, Time) {
+	return Zone{}.QuarterStart(t), Zone{}.QuarterEnd(t)
+}
+
+// Quarter 第几季度，1-4
+func (t Time) Quarter() int {
+	return int(t.Display().Month()-1)/3 + 1
+}
+
+// YearRange 本年的开始和结束
+func (t Time) YearRange() (Time, Time) {
+	return Zone{}.YearStart(t), Zone{}.YearEnd(t)
+}
+
+// AddMonths 见Zone.AddMonths
+func (t Time) AddMonths(n int) Time {
+	return Zone{}.AddMonths(t, n)
+}
+
+// ISOWeek 业务时区的ISO年和周
+func (t Time) ISOWeek() (year, week int) {
+	return Zone{}.ISOWeek(t)
+}
+
+// StringISOWeek 格式化为"2021-W05"
+func (t Time) StringISOWeek() string {
+	if t.IsZero() {
+		return ""
+	}
+	year, week := t.ISOWeek()
+	return fmt.Sprintf(isoWeekLayout, year, week)
+}
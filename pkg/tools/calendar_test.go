@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustParse(t *testing.T, s string) Time {
+	v, err := ParseTimeInLength(s)
+	require.NoError(t, err)
+	return v
+}
+
+func TestQuarterYear(t *testing.T) {
+	cases := []struct {
+		t                  string
+		quarter            int
+		qStart, qEnd       string
+		yearStart, yearEnd string
+	}{
+		{"2021-01-01 00:00:00", 1, "2021-01-01 00:00:00", "2021-03-31 23:59:59", "2021-01-01 00:00:00", "2021-12-31 23:59:59"},
+		{"2021-05-20 12:00:00", 2, "2021-04-01 00:00:00", "2021-06-30 23:59:59", "2021-01-01 00:00:00", "2021-12-31 23:59:59"},
+		{"2020-09-30 23:59:59", 3, "2020-07-01 00:00:00", "2020-09-30 23:59:59", "2020-01-01 00:00:00", "2020-12-31 23:59:59"},
+		{"2021-12-31 23:59:59", 4, "2021-10-01 00:00:00", "2021-12-31 23:59:59", "2021-01-01 00:00:00", "2021-12-31 23:59:59"},
+	}
+	for _, c := range cases {
+		tm := mustParse(t, c.t)
+		require.Equal(t, c.quarter, tm.Quarter(), c.t)
+		start, end := tm.QuarterRange()
+		require.Equal(t, c.qStart, start.String(), c.t)
+		require.Equal(t, c.qEnd, end.String(), c.t)
+		start, end = tm.YearRange()
+		require.Equal(t, c.yearStart, start.String(), c.t)
+		require.Equal(t, c.yearEnd, end.String(), c.t)
+	}
+}
+
+func TestAddMonths(t *testing.T) {
+	cases := []struct {
+		t      string
+		n      int
+		expect string
+	}{
+		{"2021-01-31 10:30:00", 1, "2021-02-28 10:30:00"},
+		{"2020-01-31 10:30:00", 1, "2020-02-29 10:30:00"},
+		{"2021-03-31 00:00:00", -1, "2021-02-28 00:00:00"},
+		{"2021-05-31 00:00:00", 1, "2021-06-30 00:00:00"},
+		{"2021-01-15 08:00:00", 13, "2022-02-15 08:00:00"},
+		{"2021-12-31 23:59:59", 2, "2022-02-28 23:59:59"},
+		{"2021-03-31 00:00:00", -12, "2020-03-31 00:00:00"},
+		{"2021-06-30 00:00:00", 0, "2021-06-30 00:00:00"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.expect, mustParse(t, c.t).AddMonths(c.n).String(), "%s %+d", c.t, c.n)
+	}
+}
+
+func TestISOWeek(t *testing.T) {
+	cases := []struct {
+		t    string
+		week string
+		mon  string // 这一周的周一
+	}{
+		{"2021-01-01", "2020-W53", "2020-12-28"},
+		{"2021-01-04", "2021-W01", "2021-01-04"},
+		{"2021-06-16", "2021-W24", "2021-06-14"},
+		{"2019-12-30", "2020-W01", "2019-12-30"},
+		{"2026-12-31", "2026-W53", "2026-12-28"},
+	}
+	z := Zone{}
+	for _, c := range cases {
+		tm := mustParse(t, c.t)
+		require.Equal(t, c.week, tm.StringISOWeek(), c.t)
+		start, err := z.ParseISOWeek(c.week)
+		require.NoError(t, err, c.week)
+		require.Equal(t, c.mon+" 00:00:00", start.String(), c.week)
+		require.Equal(t, tm.WeekStart(), start)
+	}
+	for _, s := range []string{"2021-W54", "2021-W00", "2021W01", "2021-01", "2021-Wxx"} {
+		_, err := z.ParseISOWeek(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestBusinessDays(t *testing.T) {
+	add := func(c Calendar, from string, n int) string {
+		day, err := AddBusinessDays(c, mustParse(t, from), n)
+		require.NoError(t, err)
+		return day.String()
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"holidays.yaml": `
+holidays:
+  - 2021-10-01~2021-10-07
+workdays:
+  - "2021-09-26"
+  - 2021-10-09
+`,
+		"holidays.json": `{"holidays": ["2021-10-01~2021-10-07"], "workdays": ["2021-09-26", "2021-10-09"]}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0o644))
+		c, err := LoadHolidayCalendar(path)
+		require.NoError(t, err, name)
+
+		// 9月26日周日调休上班，国庆放假，10月8日周五正常上班，10月9日周六调休上班
+		var days []string
+		for _, day := range BusinessDays(c, mustParse(t, "2021-09-25 18:00:00"), mustParse(t, "2021-10-11")) {
+			days = append(days, day.String())
+		}
+		require.Equal(t, []string{
+			"2021-09-26 00:00:00", "2021-09-27 00:00:00", "2021-09-28 00:00:00", "2021-09-29 00:00:00", "2021-09-30 00:00:00",
+			"2021-10-08 00:00:00", "2021-10-09 00:00:00", "2021-10-11 00:00:00",
+		}, days, name)
+
+		require.Equal(t, "2021-10-08 15:00:00", add(c, "2021-09-30 15:00:00", 1))
+		require.Equal(t, "2021-09-30 15:00:00", add(c, "2021-10-09 15:00:00", -2))
+	}
+	require.Equal(t, "2021-06-21 09:00:00", add(nil, "2021-06-18 09:00:00", 1))
+	require.Len(t, BusinessDays(nil, mustParse(t, "2021-06-01"), mustParse(t, "2021-06-30")), 22)
+
+	// 每天都放假时不会一直找下去
+	never, err := NewHolidayCalendar(HolidayFile{Holidays: []string{"2021-01-01~2030-12-31"}})
+	require.NoError(t, err)
+	_, err = AddBusinessDays(never, mustParse(t, "2021-06-18"), 1)
+	require.ErrorIs(t, err, ErrNoBusinessDay)
+	_, err = AddBusinessDays(never, mustParse(t, "2029-06-18"), -1)
+	require.ErrorIs(t, err, ErrNoBusinessDay)
+
+	_, err = NewHolidayCalendar(HolidayFile{Holidays: []string{"2021-10-07~2021-10-01"}})
+	require.Error(t, err)
+	_, err = NewHolidayCalendar(HolidayFile{Workdays: []string{"2021/10/09"}})
+	require.Error(t, err)
+}
+
+func TestHumanize(t *testing.T) {
+	cases := []struct {
+		d      time.Duration
+		expect string
+	}{
+		{0, "0毫秒"},
+		{500 * time.Millisecond, "500毫秒"},
+		{90 * time.Second, "1分钟30秒"},
+		{time.Hour, "1小时"},
+		{26*time.Hour + 5*time.Minute, "1天2小时"},
+		{24*time.Hour + 5*time.Minute, "1天"},
+		{-3 * time.Minute, "-3分钟"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.expect, Humanize(c.d))
+	}
+
+	now := mustParse(t, "2021-06-16 10:30:00")
+	relatives := []struct {
+		t      string
+		expect string
+	}{
+		{"2021-06-16 10:29:30", "刚刚"},
+		{"2021-06-16 10:10:00", "20分钟前"},
+		{"2021-06-16 12:45:00", "2小时后"},
+		{"2021-06-15 23:00:00", "昨天 23:00"},
+		{"2021-06-17 08:00:00", "明天 08:00"},
+		{"2021-06-12 10:30:00", "4天前"},
+		{"2021-06-01 10:30:00", "2021-06-01"},
+	}
+	for _, c := range relatives {
+		require.Equal(t, c.expect, mustParse(t, c.t).Relative(now), c.t)
+	}
+}
@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationUnits Humanize使用的单位，从大到小
+var durationUnits = []struct {
+	d    time.Duration
+	name string
+}{
+	{24 * time.Hour, "天"},
+	{time.Hour, "小时"},
+	{time.Minute, "分钟"},
+	{time.Second, "秒"},
+}
+
+/*Humanize 把时长转为最多两个单位的中文，例如"1天2小时"、"3分钟20秒"，舍去更小的单位
+参数:
+*	d	time.Duration	负数时前面加"-"
+返回值:
+*	string	string	不到1秒时为毫秒，例如"500毫秒"
+*/
+func Humanize(d time.Duration) string {
+	var b strings.Builder
+	if d < 0 {
+		b.WriteString("-")
+		d = -d
+	}
+	if d < time.Second {
+		b.WriteString(strconv.FormatInt(int64(d/time.Millisecond), 10))
+		b.WriteString("毫秒")
+		return b.String()
+	}
+	parts := 0
+	for _, unit := range durationUnits {
+		if parts == 2 {
+			break
+		}
+		n := d / unit.d
+		if n == 0 {
+			// 已经输出大单位后中间的单位为0时不再继续，例如"1天5秒"显示为"1天"
+			if parts > 0 {
+				break
+			}
+			continue
+		}
+		b.WriteString(strconv.FormatInt(int64(n), 10))
+		b.WriteString(unit.name)
+		d -= n * unit.d
+		parts++
+	}
+	return b.String()
+}
+
+/*Relative t相对now的描述，例如"刚刚"、"3分钟前"、"2小时后"、"昨天 15:04"，超过7天时为日期
+参数:
+*	t  	Time
+*	now	Time
+返回值:
+*	string	string
+*/
+func (t Time) Relative(now Time) string {
+	d := now.Sub(t)
+	suffix := "前"
+	if d < 0 {
+		d, suffix = -d, "后"
+	}
+	switch {
+	case d < time.Minute:
+		return "刚刚"
+	case d < time.Hour:
+		return strconv.Itoa(int(d/time.Minute)) + "分钟" + suffix
+	case d < 24*time.Hour && t.SameDay(now):
+		return strconv.Itoa(int(d/time.Hour)) + "小时" + suffix
+	}
+	today := time.Time(now.DayStart())
+	day := time.Time(t.DayStart())
+	switch {
+	case day.Equal(today.AddDate(0, 0, -1)):
+		return "昨天 " + t.Format("15:04")
+	case day.Equal(today.AddDate(0, 0, 1)):
+		return "明天 " + t.Format("15:04")
+	}
+	// 按日期相差的天数，夏令时的一天不是24小时
+	if days := int(math.Round(math.Abs(day.Sub(today).Hours()) / 24)); days < 7 {
+		return strconv.Itoa(days) + "天" + suffix
+	}
+	return t.StringDay()
+}
@@ -2,16 +2,71 @@ package tools
 
 import (
 	"flag"
+	"fmt"
+	"os"
+	"sync/atomic"
 	"time"
 	_ "time/tzdata"
 )
 
+const (
+	// DefaultTimezone 没有配置时的业务时区
+	DefaultTimezone = "Asia/Shanghai"
+	// TimezoneEnv 设置业务时区的环境变量，配置文件中没有设置时使用
+	TimezoneEnv = "APP_TIMEZONE"
+)
+
+// location 业务时区，Time的日历计算、格式化和解析都使用它
+var location atomic.Value
+
 func init() {
-	var err error
-	if location, err = time.LoadLocation("Asia/Shanghai"); err != nil {
-		panic(err.Error())
+	loc, err := loadTimezone("")
+	if err != nil {
+		// 环境变量错误时先使用默认时区，启动时由SetTimezone返回错误
+		loc, _ = time.LoadLocation(DefaultTimezone)
+	}
+	location.Store(loc)
+}
+
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		name = os.Getenv(TimezoneEnv)
+	}
+	if name == "" {
+		name = DefaultTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("加载时区[%s]: %w", name, err)
 	}
+	return loc, nil
 }
+
+/*SetTimezone 设置业务时区，启动时根据配置调用
+参数:
+*	name	string	IANA时区名，例如Asia/Shanghai，为空时使用环境变量APP_TIMEZONE，都没有时为Asia/Shanghai
+返回值:
+*	error	error	时区不存在时返回错误，业务时区不变
+*/
+func SetTimezone(name string) error {
+	loc, err := loadTimezone(name)
+	if err != nil {
+		return err
+	}
+	SetLocation(loc)
+	return nil
+}
+
+// SetLocation 设置业务时区，应该在启动时设置，运行中修改会让已经计算的日期范围不一致
+func SetLocation(loc *time.Location) {
+	location.Store(loc)
+}
+
+// Location 业务使用的时区，默认Asia/Shanghai
+func Location() *time.Location {
+	return location.Load().(*time.Location)
+}
+
 func IsTest() bool {
 	return flag.Lookup("test.v") != nil
 }
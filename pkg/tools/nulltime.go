@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// NullTime 可以为空的Time，无效时JSON为null、BSON为null、SQL为NULL、文本和CSV为空，不会输出0001-01-01
+// Valid为true但Time为零值时和无效相同，各种编码都按无效处理
+type NullTime struct {
+	Time  Time
+	Valid bool // Time不为NULL时为true
+}
+
+// valid Valid并且Time不是零值
+func (n NullTime) valid() bool {
+	return n.Valid && !n.Time.IsZero()
+}
+
+// NullTimeOf 零值为无效
+func NullTimeOf(t Time) NullTime {
+	return NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// Ptr 无效时为nil
+func (n NullTime) Ptr() *Time {
+	if !n.valid() {
+		return nil
+	}
+	t := n.Time
+	return &t
+}
+
+// IsZero bson的omitempty使用它，无效时省略字段
+func (n NullTime) IsZero() bool {
+	return !n.valid()
+}
+
+func (n NullTime) String() string {
+	if !n.valid() {
+		return ""
+	}
+	return n.Time.String()
+}
+
+func (n *NullTime) Scan(value interface{}) error {
+	nullTime := &sql.NullTime{}
+	if err := nullTime.Scan(value); err != nil {
+		return err
+	}
+	*n = NullTime{Time: Time(nullTime.Time), Valid: nullTime.Valid}
+	return nil
+}
+
+// Value 和Time.Value相同，无效时为NULL
+func (n NullTime) Value() (driver.Value, error) {
+	if !n.valid() {
+		return nil, nil
+	}
+	return n.Time.Value()
+}
+
+// GormDataType gorm common data type
+func (n NullTime) GormDataType() string {
+	return n.Time.GormDataType()
+}
+
+func (n NullTime) MarshalJSON() ([]byte, error) {
+	if !n.valid() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Time.String())
+}
+
+func (n *NullTime) UnmarshalJSON(data []byte) error {
+	return n.unmarshal(bytes.Trim(data, `""`))
+}
+
+func (n NullTime) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+func (n *NullTime) UnmarshalText(data []byte) error {
+	return n.unmarshal(bytes.Trim(data, `""`))
+}
+
+func (n NullTime) MarshalCSV() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+func (n *NullTime) UnmarshalCSV(data []byte) error {
+	return n.unmarshal(bytes.TrimPrefix(bytes.Trim(data, `""`), []byte("`")))
+}
+
+// unmarshal 空值为无效，其它格式见ParseTime
+func (n *NullTime) unmarshal(data []byte) error {
+	s := string(data)
+	if isEmptyTime(s) {
+		*n = NullTime{}
+		return nil
+	}
+	t, err := ParseTime(s)
+	if err != nil {
+		return err
+	}
+	*n = NullTime{Time: t, Valid: true}
+	return nil
+}
+
+func (n NullTime) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if !n.valid() {
+		return bsontype.Null, nil, nil
+	}
+	return n.Time.MarshalBSONValue()
+}
+
+func (n *NullTime) UnmarshalBSONValue(bt bsontype.Type, v []byte) error {
+	if bt == bsontype.Null || bt == bsontype.Undefined {
+		*n = NullTime{}
+		return nil
+	}
+	if err := n.Time.UnmarshalBSONValue(bt, v); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// GobEncode 无效时为空
+func (n NullTime) GobEncode() ([]byte, error) {
+	if !n.valid() {
+		return nil, nil
+	}
+	return n.Time.GobEncode()
+}
+
+func (n *NullTime) GobDecode(b []byte) error {
+	if len(b) == 0 {
+		*n = NullTime{}
+		return nil
+	}
+	if err := n.Time.GobDecode(b); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos-layout/pkg/mongocodec"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestParseTime(t *testing.T) {
+	useTimezone(t, "Asia/Shanghai")
+	expect := time.Date(2021, 6, 16, 2, 30, 0, 0, time.UTC)
+	for _, input := range []string{
+		"2021-06-16 10:30:00",
+		"2021-06-16T10:30:00+08:00",
+		"2021-06-16T02:30:00Z",
+		"2021-06-16T02:30:00.000Z",
+		"2021-06-16T10:30:00",
+		"1623810600",
+		"1623810600000",
+	} {
+		for name, unmarshal := range map[string]func(*Time, []byte) error{
+			"json": func(v *Time, b []byte) error { return json.Unmarshal(b, v) },
+			"text": (*Time).UnmarshalText,
+			"csv":  (*Time).UnmarshalCSV,
+		} {
+			data := input
+			if name == "json" {
+				data = `"` + input + `"`
+			}
+			var v Time
+			require.NoError(t, unmarshal(&v, []byte(data)), "%s %s", name, input)
+			require.True(t, expect.Equal(time.Time(v)), "%s %s: %s", name, input, v)
+		}
+	}
+
+	// json的数字
+	var v Time
+	require.NoError(t, json.Unmarshal([]byte("1623810600000"), &v))
+	require.True(t, expect.Equal(time.Time(v)))
+
+	// 前缀按日期解析，不是时间戳
+	v, err := ParseTime("2021")
+	require.NoError(t, err)
+	require.Equal(t, "2021-01-01 00:00:00", v.String())
+
+	for _, input := range []string{"2021-06-16T10:30", "162381060", "2021/06/16"} {
+		_, err = ParseTime(input)
+		require.Error(t, err, input)
+	}
+
+	// null保持零值
+	var doc struct{ T Time }
+	require.NoError(t, json.Unmarshal([]byte(`{"T":null}`), &doc))
+	require.True(t, doc.T.IsZero())
+}
+
+func TestTimestamp(t *testing.T) {
+	require.Nil(t, Time{}.Timestamp())
+	require.True(t, FromTimestamp(nil).IsZero())
+
+	tm := Time(time.Date(2021, 6, 16, 2, 30, 0, 123456789, time.UTC))
+	ts := tm.Timestamp()
+	require.Equal(t, int64(1623810600), ts.Seconds)
+	require.Equal(t, int32(123456789), ts.Nanos)
+	back := FromTimestamp(ts)
+	require.True(t, time.Time(tm).Equal(time.Time(back)))
+	require.Equal(t, Location(), time.Time(back).Location())
+
+	require.Nil(t, NullTime{}.Timestamp())
+	require.False(t, NullTimeFromTimestamp(nil).Valid)
+	n := NullTimeFromTimestamp(timestamppb.New(time.Unix(0, 0)))
+	require.True(t, n.Valid)
+	require.Equal(t, int64(0), n.Timestamp().Seconds)
+}
+
+func TestNullTime(t *testing.T) {
+	type doc struct {
+		T NullTime `json:"t" bson:"t"`
+	}
+	tm, err := ParseTimeInLength("2021-07-01 08:30:15")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name string
+		v    NullTime
+		json string
+		text string
+	}{
+		{"有效", NullTimeOf(tm), `{"t":"2021-07-01 08:30:15"}`, "2021-07-01 08:30:15"},
+		{"无效", NullTimeOf(Time{}), `{"t":null}`, ""},
+		// Valid但Time为零值时按无效编码
+		{"零值", NullTime{Valid: true}, `{"t":null}`, ""},
+	}
+	registry := mongocodec.Build()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			valid := c.v.Valid && !c.v.Time.IsZero()
+			same := func(v NullTime) {
+				require.Equal(t, valid, v.Valid)
+				require.True(t, time.Time(c.v.Time).Equal(time.Time(v.Time)))
+			}
+
+			b, err := json.Marshal(doc{T: c.v})
+			require.NoError(t, err)
+			require.JSONEq(t, c.json, string(b))
+			fromJSON := doc{T: NullTimeOf(Now())}
+			require.NoError(t, json.Unmarshal(b, &fromJSON))
+			same(fromJSON.T)
+
+			text, err := c.v.MarshalText()
+			require.NoError(t, err)
+			require.Equal(t, c.text, string(text))
+			var fromText NullTime
+			require.NoError(t, fromText.UnmarshalText(text))
+			same(fromText)
+
+			csv, err := c.v.MarshalCSV()
+			require.NoError(t, err)
+			var fromCSV NullTime
+			require.NoError(t, fromCSV.UnmarshalCSV(csv))
+			same(fromCSV)
+
+			b, err = bson.Marshal(doc{T: c.v})
+			require.NoError(t, err)
+			raw := bson.Raw(b).Lookup("t")
+			require.Equal(t, !valid, raw.Type == bson.TypeNull)
+			b, err = bson.MarshalWithRegistry(registry, doc{T: c.v})
+			require.NoError(t, err)
+			raw = bson.Raw(b).Lookup("t")
+			require.Equal(t, !valid, raw.Type == bson.TypeNull)
+			fromBSON := doc{T: NullTimeOf(Now())}
+			require.NoError(t, bson.Unmarshal(b, &fromBSON))
+			same(fromBSON.T)
+
+			var buf bytes.Buffer
+			require.NoError(t, gob.NewEncoder(&buf).Encode(doc{T: c.v}))
+			var fromGob doc
+			require.NoError(t, gob.NewDecoder(&buf).Decode(&fromGob))
+			same(fromGob.T)
+
+			ts := c.v.Timestamp()
+			require.Equal(t, !valid, ts == nil)
+			same(NullTimeFromTimestamp(ts))
+
+			value, err := c.v.Value()
+			require.NoError(t, err)
+			var fromSQL NullTime
+			require.NoError(t, fromSQL.Scan(value))
+			require.Equal(t, valid, fromSQL.Valid)
+			if valid {
+				require.True(t, fromSQL.Time.SameDay(tm))
+			} else {
+				require.Nil(t, value)
+			}
+		})
+	}
+
+	// omitempty省略无效的时间
+	b, err := bson.Marshal(struct {
+		T NullTime `bson:"t,omitempty"`
+	}{})
+	require.NoError(t, err)
+	_, err = bson.Raw(b).LookupErr("t")
+	require.Error(t, err)
+	require.Nil(t, NullTime{}.Ptr())
+	require.Nil(t, NullTime{Valid: true}.Ptr())
+	require.True(t, NullTime{Valid: true}.IsZero())
+	require.Equal(t, tm, *NullTimeOf(tm).Ptr())
+}
@@ -7,16 +7,13 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 )
 
-var (
-	location *time.Location
-)
-
 const (
 	defaultLayout = "2006-01-02 15:04:05"
 )
@@ -64,12 +61,13 @@ func (t *Time) Scan(value interface{}) (err error) {
 	return
 }
 
+// Value 列类型为date，保存业务时区的日期
 func (t Time) Value() (driver.Value, error) {
-	y, m, d := time.Time(t).Date()
 	if t.IsZero() {
+		y, m, d := time.Time(t).Date()
 		return time.Date(y, m, d, 0, 0, 0, 0, time.Time(t).Location()), nil
 	}
-	return time.Date(y, m, d, 0, 0, 0, 0, location), nil
+	return time.Time(t.DayStart()), nil
 }
 
 // GormDataType gorm common data type
@@ -101,16 +99,7 @@ func Now() Time {
 *	Time	Time
 */
 func (t Time) WeekStart() Time {
-	stdTime := time.Time(t)
-	weekDay := stdTime.Weekday()
-	day := stdTime.Day()
-	switch weekDay {
-	case time.Sunday: // Sunday 值为0,
-		day -= 6
-	default:
-		day -= int(weekDay) - 1
-	}
-	return Time(time.Date(stdTime.Year(), stdTime.Month(), day, 0, 0, 0, 0, location))
+	return Zone{}.WeekStart(t)
 }
 
 /*WeekEnd 返回本周结束时间，即周日23:59:59
@@ -120,16 +109,7 @@ func (t Time) WeekStart() Time {
 *	Time	Time
 */
 func (t Time) WeekEnd() Time {
-	stdTime := time.Time(t)
-	weekDay := stdTime.Weekday()
-	day := stdTime.Day()
-	switch weekDay {
-	case time.Sunday: // 周日不用处理
-
-	default: // 其天时间，比如周1 需要加 7-1==6 天¬
-		day += 7 - int(weekDay)
-	}
-	return Time(time.Date(stdTime.Year(), stdTime.Month(), day, 23, 59, 59, 0, location))
+	return Zone{}.WeekEnd(t)
 }
 
 /*WeekRange 返回当前所在的周一00:00和周日23:59:59
@@ -149,9 +129,7 @@ func (t Time) WeekRange() (start, end Time) {
 *	Time	Time
 */
 func (t Time) MonthStart() Time {
-	stdTime := time.Time(t)
-
-	return Time(time.Date(stdTime.Year(), stdTime.Month(), 1, 0, 0, 0, 0, location))
+	return Zone{}.MonthStart(t)
 }
 
 /*MonthEnd 返回本月结束时间，即最后一天23:59:59
@@ -160,21 +138,7 @@ func (t Time) MonthStart() Time {
 *	Time	Time
 */
 func (t Time) MonthEnd() Time {
-	stdTime := time.Time(t)
-	month := stdTime.Month()
-	day := 31
-	switch month {
-	case time.February: // 2 月
-		if year := stdTime.Year(); year%400 == 0 || (year%100 != 0 && year%4 == 0) { // 闰年29天
-			day = 29
-		} else { // 非闰年28天
-			day = 28
-		}
-
-	case 4, 6, 9, 11: // 小月 30天
-		day = 30
-	}
-	return Time(time.Date(stdTime.Year(), stdTime.Month(), day, 23, 59, 59, 0, location))
+	return Zone{}.MonthEnd(t)
 }
 
 /*MonthRange 返回本月开始结束
@@ -188,38 +152,47 @@ func (t Time) MonthRange() (Time, Time) {
 }
 
 func (t Time) DayStart() Time {
-	stdTime := time.Time(t)
-	return Time(time.Date(stdTime.Year(), stdTime.Month(), stdTime.Day(), 0, 0, 0, 0, location))
+	return Zone{}.DayStart(t)
 }
 
 func (t Time) DayEnd() Time {
-	stdTime := time.Time(t)
-	return Time(time.Date(stdTime.Year(), stdTime.Month(), stdTime.Day(), 23, 59, 59, 0, location))
+	return Zone{}.DayEnd(t)
 }
 
 func (t Time) DayRange() (Time, Time) {
 	return t.DayStart(), t.DayEnd()
 }
 
+// EachDay from到to经过的每一天，第一个为from，最后一个为to，中间为业务时区当天的开始，可以跨年
 func EachDay(from, to Time) []Time {
-	if to.After(from) {
-		year := time.Time(from).Year()
-		fromDay := time.Time(from).YearDay()
-		endDay := time.Time(to).YearDay()
-		result := make([]Time, 0, endDay-fromDay+1)
-		result = append(result, from)
-		if endDay != fromDay {
-			for i := fromDay + 1; i < endDay; i++ {
-				result = append(result, Time(time.Date(year, 1, i, 0, 0, 0, 0, location)))
-			}
-			result = append(result, to)
-		}
-		return result
+	if !to.After(from) {
+		return nil
 	}
-	return nil
+	result := []Time{from}
+	end := time.Time(to.DayStart())
+	for day := time.Time(from.DayStart()).AddDate(0, 0, 1); day.Before(end); day = day.AddDate(0, 0, 1) {
+		result = append(result, Time(day))
+	}
+	if !from.SameDay(to) {
+		result = append(result, to)
+	}
+	return result
 }
+// SameDay 在业务时区是否为同一天
 func (t Time) SameDay(another Time) bool {
-	return time.Time(t).Year() == time.Time(another).Year() && time.Time(t).YearDay() == time.Time(another).YearDay()
+	y1, m1, d1 := t.Display().Date()
+	y2, m2, d2 := another.Display().Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
+
+// UTC 转为UTC时间，用于保存和比较，表示的时刻不变
+func (t Time) UTC() Time {
+	return Time(time.Time(t).UTC())
+}
+
+// Display 业务时区的time.Time，用于展示和读取年月日
+func (t Time) Display() time.Time {
+	return time.Time(t).In(Location())
 }
 
 // DeepCopyInto creates a deep-copy of the Time value.  The underlying time.Time
@@ -239,46 +212,31 @@ func (t *Time) DeepCopy() *Time {
 	return out
 }
 
+// MarshalCSV 和MarshalText相同，按业务时区格式化，零值为空
 func (t Time) MarshalCSV() ([]byte, error) {
-	return []byte(time.Time(t).Format(defaultLayout)), nil
+	return []byte(t.String()), nil
 }
 
 func (t *Time) UnmarshalCSV(data []byte) error {
-	data = bytes.Trim(data, `""`)
-	data = bytes.TrimPrefix(data, []byte("`"))
-	s := string(data)
-	if s == "--" || s == "" {
-		return nil
-	}
-	if len(data) == 10 {
-		parseInt, err := strconv.ParseInt(s, 10, 64)
-		if err == nil {
-			*t = Time(time.Unix(parseInt, 0))
-			return nil
-		}
-	}
-	parsedTime, err := ParseTimeInLength(s)
-	if err != nil {
-		return err
-	}
-	*t = parsedTime
-	return nil
+	data = bytes.TrimPrefix(bytes.Trim(data, `""`), []byte("`"))
+	return t.unmarshal(data)
 }
 
 func (t *Time) UnmarshalJSON(data []byte) error {
-	data = bytes.Trim(data, `""`)
+	return t.unmarshal(bytes.Trim(data, `""`))
+}
+
+func (t *Time) UnmarshalText(data []byte) error {
+	return t.unmarshal(bytes.Trim(data, `""`))
+}
+
+// unmarshal 空值不修改t，其它格式见ParseTime
+func (t *Time) unmarshal(data []byte) error {
 	s := string(data)
-	if s == "--" || s == "" {
+	if isEmptyTime(s) {
 		return nil
 	}
-	if len(data) == 10 {
-		parseInt, err := strconv.ParseInt(s, 10, 64)
-		if err == nil {
-			*t = Time(time.Unix(parseInt, 0))
-			return nil
-		}
-	}
-	parsedTime, err := ParseTimeInLength(s)
+	parsedTime, err := ParseTime(s)
 	if err != nil {
 		return err
 	}
@@ -286,22 +244,58 @@ func (t *Time) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func (t *Time) UnmarshalText(data []byte) error {
-	data = bytes.Trim(data, `""`)
-	if string(data) == "" {
-		return nil
-	} else {
-		parsedTime, err := ParseTimeInLength(string(data))
+// isEmptyTime 表示没有时间的输入
+func isEmptyTime(s string) bool {
+	return s == "" || s == "--" || s == "null"
+}
+
+/*ParseTime 解析外部输入的时间，JSON、文本和CSV都使用它
+参数:
+*	data	string	支持的格式:
+*			"2006-01-02 15:04:05"或者它的前缀，按业务时区
+*			RFC3339，例如"2021-06-16T10:30:00+08:00"，没有时区时按业务时区
+*			10位的unix秒和13位的unix毫秒
+返回值:
+*	Time 	Time
+*	error	error
+*/
+func ParseTime(data string) (Time, error) {
+	if n, ok := parseUnix(data); ok {
+		return Time(n), nil
+	}
+	if strings.Contains(data, "T") {
+		if value, err := time.Parse(time.RFC3339Nano, data); err == nil {
+			return Time(value), nil
+		}
+		value, err := time.ParseInLocation(localRFC3339, data, Location())
 		if err != nil {
-			return err
-		} else {
-			*t = Time(parsedTime)
-			return nil
+			return Time{}, fmt.Errorf("错误的时间[%s]: %w", data, err)
 		}
+		return Time(value), nil
+	}
+	return ParseTimeInLength(data)
+}
+
+// localRFC3339 没有时区的RFC3339
+const localRFC3339 = "2006-01-02T15:04:05.999999999"
+
+// parseUnix 10位为秒，13位为毫秒，其它长度的数字不是时间戳，例如"2021"是年份
+func parseUnix(data string) (time.Time, bool) {
+	if len(data) != 10 && len(data) != 13 {
+		return time.Time{}, false
+	}
+	n, err := strconv.ParseInt(data, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if len(data) == 13 {
+		return time.Unix(0, n*int64(time.Millisecond)), true
 	}
+	return time.Unix(n, 0), true
 }
+
 func (t Time) IsZero() bool {
-	return time.Time(t).In(location).IsZero()
+	return time.Time(t).IsZero()
 }
 func (t Time) MarshalText() ([]byte, error) {
 	if t.IsZero() {
@@ -314,11 +308,9 @@ func (t Time) String() string {
 	return t.Format(defaultLayout)
 }
 
+// Format 按业务时区格式化，零值返回空字符串
 func (t Time) Format(layout string) string {
-	if t.IsZero() {
-		return ""
-	}
-	return time.Time(t).In(location).Format(layout)
+	return Zone{}.Format(t, layout)
 }
 func (t Time) StringDay() string {
 	return t.Format(dayLayout)
@@ -351,15 +343,12 @@ func (t Time) MarshalBSONValue() (bsontype.Type, []byte, error) {
 	return bsontype.DateTime, bsoncore.AppendTime(nil, time.Time(t)), nil
 }
 
+// ParseTimeInLength 按业务时区解析"2006-01-02 15:04:05"或者它的前缀，指定时区时使用Zone.Parse
 func ParseTimeInLength(data string) (Time, error) {
-	if value, err := time.ParseInLocation(defaultLayout[:len(data)], data, location); err != nil {
-		return Time{}, err
-	} else {
-		return Time(value), nil
-	}
+	return Zone{}.Parse(data)
 }
 func NewTimeFromCBCDeal(date string) (Time, error) {
-	if value, err := time.ParseInLocation("20060102150405", date, location); err != nil {
+	if value, err := time.ParseInLocation("20060102150405", date, Location()); err != nil {
 		return Time{}, err
 	} else {
 		return Time(value), nil
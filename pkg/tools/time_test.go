@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// useTimezone 测试期间修改业务时区
+func useTimezone(t *testing.T, name string) *time.Location {
+	old := Location()
+	require.NoError(t, SetTimezone(name))
+	t.Cleanup(func() { SetLocation(old) })
+	return Location()
+}
+
+func TestSetTimezone(t *testing.T) {
+	old := Location()
+	defer SetLocation(old)
+	// 运行测试的环境可能设置了APP_TIMEZONE
+	t.Setenv(TimezoneEnv, "")
+	require.NoError(t, SetTimezone(""))
+	require.Equal(t, DefaultTimezone, Location().String())
+
+	require.Error(t, SetTimezone("Mars/Olympus"))
+	require.Equal(t, DefaultTimezone, Location().String())
+
+	t.Setenv(TimezoneEnv, "Europe/London")
+	require.NoError(t, SetTimezone(""))
+	require.Equal(t, "Europe/London", Location().String())
+	// 配置优先于环境变量
+	require.NoError(t, SetTimezone("UTC"))
+	require.Equal(t, "UTC", Location().String())
+}
+
+func TestEachDay(t *testing.T) {
+	useTimezone(t, "Asia/Shanghai")
+	from, err := ParseTimeInLength("2021-12-30 10:00:00")
+	require.NoError(t, err)
+	to, err := ParseTimeInLength("2022-01-02 08:00:00")
+	require.NoError(t, err)
+	var days []string
+	for _, day := range EachDay(from, to) {
+		days = append(days, day.String())
+	}
+	require.Equal(t, []string{"2021-12-30 10:00:00", "2021-12-31 00:00:00", "2022-01-01 00:00:00", "2022-01-02 08:00:00"}, days)
+
+	require.Equal(t, []Time{from}, EachDay(from, Time(time.Time(from).Add(time.Hour))))
+	require.Nil(t, EachDay(to, from))
+}
+
+func TestCalendarDST(t *testing.T) {
+	cases := []struct {
+		name     string
+		zone     string
+		instant  time.Time // 按UTC给出
+		day      string    // 业务时区的日期
+		dayStart string    // RFC3339
+		dayEnd   string
+		week     string // 周一
+		monthEnd string
+	}{
+		{
+			name:     "纽约夏令时开始当天只有23小时",
+			zone:     "America/New_York",
+			instant:  time.Date(2021, 3, 14, 16, 0, 0, 0, time.UTC),
+			day:      "2021-03-14",
+			dayStart: "2021-03-14T00:00:00-05:00",
+			dayEnd:   "2021-03-14T23:59:59-04:00",
+			week:     "2021-03-08T00:00:00-05:00",
+			monthEnd: "2021-03-31T23:59:59-04:00",
+		},
+		{
+			name:     "UTC已经是第二天，纽约还是前一天",
+			zone:     "America/New_York",
+			instant:  time.Date(2021, 3, 14, 3, 0, 0, 0, time.UTC),
+			day:      "2021-03-13",
+			dayStart: "2021-03-13T00:00:00-05:00",
+			dayEnd:   "2021-03-13T23:59:59-05:00",
+			week:     "2021-03-08T00:00:00-05:00",
+			monthEnd: "2021-03-31T23:59:59-04:00",
+		},
+		{
+			name:     "伦敦夏令时结束当天有25小时",
+			zone:     "Europe/London",
+			instant:  time.Date(2021, 10, 31, 12, 0, 0, 0, time.UTC),
+			day:      "2021-10-31",
+			dayStart: "2021-10-31T00:00:00+01:00",
+			dayEnd:   "2021-10-31T23:59:59Z",
+			week:     "2021-10-25T00:00:00+01:00",
+			monthEnd: "2021-10-31T23:59:59Z",
+		},
+		{
+			name:     "上海没有夏令时",
+			zone:     "Asia/Shanghai",
+			instant:  time.Date(2021, 2, 28, 16, 0, 0, 0, time.UTC),
+			day:      "2021-03-01",
+			dayStart: "2021-03-01T00:00:00+08:00",
+			dayEnd:   "2021-03-01T23:59:59+08:00",
+			week:     "2021-03-01T00:00:00+08:00",
+			monthEnd: "2021-03-31T23:59:59+08:00",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			loc := useTimezone(t, c.zone)
+			tm := Time(c.instant)
+			rfc := func(v Time) string { return time.Time(v).In(loc).Format(time.RFC3339) }
+			require.Equal(t, c.day, tm.StringDay())
+			require.Equal(t, c.dayStart, rfc(tm.DayStart()))
+			require.Equal(t, c.dayEnd, rfc(tm.DayEnd()))
+			require.Equal(t, c.week, rfc(tm.WeekStart()))
+			require.Equal(t, c.monthEnd, rfc(tm.MonthEnd()))
+			require.True(t, tm.SameDay(tm.DayStart()))
+			require.True(t, tm.SameDay(tm.DayEnd()))
+
+			// 按其他时区计算时结果不受业务时区影响
+			z, err := LoadZone("Asia/Tokyo")
+			require.NoError(t, err)
+			require.Equal(t, c.instant.In(z.Location()).Format(dayLayout), z.Format(z.DayStart(tm), dayLayout))
+			require.Equal(t, "00:00:00", z.Format(z.DayStart(tm), "15:04:05"))
+		})
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	type doc struct {
+		T Time `json:"t" bson:"t"`
+	}
+	for _, zone := range []string{"Asia/Shanghai", "America/New_York", "Europe/London", "UTC"} {
+		t.Run(zone, func(t *testing.T) {
+			useTimezone(t, zone)
+			// 夏令时期间的时间
+			tm, err := ParseTimeInLength("2021-07-01 08:30:15")
+			require.NoError(t, err)
+			require.Equal(t, "2021-07-01 08:30:15", tm.String())
+
+			b, err := json.Marshal(doc{T: tm})
+			require.NoError(t, err)
+			require.JSONEq(t, `{"t":"2021-07-01 08:30:15"}`, string(b))
+			var fromJSON doc
+			require.NoError(t, json.Unmarshal(b, &fromJSON))
+			require.True(t, time.Time(tm).Equal(time.Time(fromJSON.T)))
+
+			b, err = bson.Marshal(doc{T: tm})
+			require.NoError(t, err)
+			var fromBSON doc
+			require.NoError(t, bson.Unmarshal(b, &fromBSON))
+			require.True(t, time.Time(tm).Equal(time.Time(fromBSON.T)))
+			require.Equal(t, tm.String(), fromBSON.T.String())
+
+			csv, err := tm.MarshalCSV()
+			require.NoError(t, err)
+			require.Equal(t, "2021-07-01 08:30:15", string(csv))
+			var fromCSV Time
+			require.NoError(t, fromCSV.UnmarshalCSV(csv))
+			require.True(t, time.Time(tm).Equal(time.Time(fromCSV)))
+
+			var buf bytes.Buffer
+			require.NoError(t, gob.NewEncoder(&buf).Encode(tm))
+			var fromGob Time
+			require.NoError(t, gob.NewDecoder(&buf).Decode(&fromGob))
+			require.True(t, time.Time(tm).Equal(time.Time(fromGob)))
+			require.Equal(t, tm.String(), fromGob.String())
+
+			// sql保存业务时区的日期
+			value, err := tm.Value()
+			require.NoError(t, err)
+			require.Equal(t, "2021-07-01 00:00:00", Time(value.(time.Time)).String())
+			var fromSQL Time
+			require.NoError(t, fromSQL.Scan(value))
+			require.True(t, fromSQL.SameDay(tm))
+
+			// UTC保存，展示时转回业务时区
+			require.Equal(t, time.UTC, time.Time(tm.UTC()).Location())
+			require.Equal(t, tm.String(), tm.UTC().String())
+			require.Equal(t, Location(), tm.Display().Location())
+		})
+	}
+
+	var zero Time
+	csv, err := zero.MarshalCSV()
+	require.NoError(t, err)
+	require.Empty(t, csv)
+	require.NoError(t, zero.UnmarshalCSV(csv))
+	require.True(t, zero.IsZero())
+	_, err = ParseTimeInLength("2021-07-01 08:30:15.000")
+	require.Error(t, err)
+}
@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Timestamp 转为protobuf的时间，零值为nil，返回给接口时不会出现0001-01-01
+func (t Time) Timestamp() *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(time.Time(t))
+}
+
+// FromTimestamp protobuf的时间转为Time，nil为零值
+func FromTimestamp(ts *timestamppb.Timestamp) Time {
+	if ts == nil {
+		return Time{}
+	}
+	return Time(ts.AsTime().In(Location()))
+}
+
+// Timestamp 无效或者Time为零值时为nil
+func (n NullTime) Timestamp() *timestamppb.Timestamp {
+	if !n.valid() {
+		return nil
+	}
+	return timestamppb.New(time.Time(n.Time))
+}
+
+// NullTimeFromTimestamp nil为无效，其它值都有效
+func NullTimeFromTimestamp(ts *timestamppb.Timestamp) NullTime {
+	if ts == nil {
+		return NullTime{}
+	}
+	return NullTime{Time: FromTimestamp(ts), Valid: true}
+}
@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	"time"
+)
+
+// Zone 按指定时区做日历计算、格式化和解析，零值使用业务时区Location()
+// Time的同名方法使用业务时区，需要按用户的时区计算时使用Zone，例如LoadZone("America/New_York")
+type Zone struct {
+	loc *time.Location
+}
+
+// ZoneOf loc为nil时使用业务时区
+func ZoneOf(loc *time.Location) Zone {
+	return Zone{loc: loc}
+}
+
+// LoadZone 按IANA时区名创建Zone
+func LoadZone(name string) (Zone, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return Zone{}, err
+	}
+	return Zone{loc: loc}, nil
+}
+
+// Location Zone使用的时区
+func (z Zone) Location() *time.Location {
+	if z.loc == nil {
+		return Location()
+	}
+	return z.loc
+}
+
+// In t在这个时区的时间，用于展示和读取年月日
+func (z Zone) In(t Time) time.Time {
+	return time.Time(t).In(z.Location())
+}
+
+// Now 当前时间
+func (z Zone) Now() Time {
+	return Time(time.Now().In(z.Location()))
+}
+
+// Parse 按这个时区解析"2006-01-02 15:04:05"或者它的前缀
+func (z Zone) Parse(data string) (Time, error) {
+	if len(data) > len(defaultLayout) {
+		return Time{}, &time.ParseError{Layout: defaultLayout, Value: data, Message: ": extra text"}
+	}
+	value, err := time.ParseInLocation(defaultLayout[:len(data)], data, z.Location())
+	if err != nil {
+		return Time{}, err
+	}
+	return Time(value), nil
+}
+
+// Format 按这个时区格式化，零值返回空字符串
+func (z Zone) Format(t Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return z.In(t).Format(layout)
+}
+
+// DayStart 当天00:00:00
+func (z Zone) DayStart(t Time) Time {
+	stdTime := z.In(t)
+	return Time(time.Date(stdTime.Year(), stdTime.Month(), stdTime.Day(), 0, 0, 0, 0, z.Location()))
+}
+
+// DayEnd 当天23:59:59
+func (z Zone) DayEnd(t Time) Time {
+	stdTime := z.In(t)
+	return Time(time.Date(stdTime.Year(), stdTime.Month(), stdTime.Day(), 23, 59, 59, 0, z.Location()))
+}
+
+// WeekStart 本周一00:00:00
+func (z Zone) WeekStart(t Time) Time {
+	stdTime := z.In(t)
+	weekDay := stdTime.Weekday()
+	day := stdTime.Day()
+	switch weekDay {
+	case time.Sunday: // Sunday 值为0,
+		day -= 6
+	default:
+		day -= int(weekDay) - 1
+	}
+	return Time(time.Date(stdTime.Year(), stdTime.Month(), day, 0, 0, 0, 0, z.Location()))
+}
+
+// WeekEnd 本周日23:59:59
+func (z Zone) WeekEnd(t Time) Time {
+	stdTime := z.In(t)
+	weekDay := stdTime.Weekday()
+	day := stdTime.Day()
+	switch weekDay {
+	case time.Sunday: // 周日不用处理
+
+	default: // 其天时间，比如周1 需要加 7-1==6 天
+		day += 7 - int(weekDay)
+	}
+	return Time(time.Date(stdTime.Year(), stdTime.Month(), day, 23, 59, 59, 0, z.Location()))
+}
+
+// MonthStart 本月1号00:00:00
+func (z Zone) MonthStart(t Time) Time {
+	stdTime := z.In(t)
+	return Time(time.Date(stdTime.Year(), stdTime.Month(), 1, 0, 0, 0, 0, z.Location()))
+}
+
+// MonthEnd 本月最后一天23:59:59
+func (z Zone) MonthEnd(t Time) Time {
+	stdTime := z.In(t)
+	// 下个月的第0天即本月最后一天
+	return Time(time.Date(stdTime.Year(), stdTime.Month()+1, 0, 23, 59, 59, 0, z.Location()))
+}
+
+type zoneKey struct{}
+
+// WithZone 在ctx中保存请求的时区，例如根据请求头设置
+func WithZone(ctx context.Context, z Zone) context.Context {
+	return context.WithValue(ctx, zoneKey{}, z)
+}
+
+// ZoneFrom ctx中的时区，没有时为业务时区
+func ZoneFrom(ctx context.Context) Zone {
+	z, _ := ctx.Value(zoneKey{}).(Zone)
+	return z
+}